@@ -0,0 +1,210 @@
+// Package auth autentica clientes do servidor por API key e controla as cotas
+// diárias de requisições e tokens de cada chave.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// KeyPrefix identifica as chaves geradas pelo code-explainer
+const KeyPrefix = "ce_"
+
+// ErrQuotaExceeded indica que a chave atingiu uma das cotas do dia
+var ErrQuotaExceeded = errors.New("cota diária excedida")
+
+// Key descreve uma API key. Apenas o hash SHA-256 da chave é guardado.
+type Key struct {
+	Name          string    `json:"name"`
+	Hash          string    `json:"hash"`                     // SHA-256 da chave, em hexadecimal
+	Admin         bool      `json:"admin,omitempty"`          // Permite consultar o uso de todas as chaves
+	DailyRequests int       `json:"daily_requests,omitempty"` // 0 = sem limite
+	DailyTokens   int       `json:"daily_tokens,omitempty"`   // 0 = sem limite
+	CreatedAt     time.Time `json:"created_at,omitempty"`
+}
+
+// QuotaError informa qual cota foi excedida e quando ela é renovada
+type QuotaError struct {
+	Quota   string // "requests" ou "tokens"
+	Limit   int
+	ResetAt time.Time
+}
+
+func (e *QuotaError) Error() string {
+	unit := "requisições"
+	if e.Quota == "tokens" {
+		unit = "tokens"
+	}
+	return fmt.Sprintf("%v: limite de %d %s por dia (renova em %s)", ErrQuotaExceeded, e.Limit, unit, e.ResetAt.Format(time.RFC3339))
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// Usage é o consumo de uma chave no dia corrente (UTC)
+type Usage struct {
+	Name          string `json:"name"`
+	Admin         bool   `json:"admin,omitempty"`
+	Day           string `json:"day"`
+	Requests      int    `json:"requests"`
+	Tokens        int    `json:"tokens"`
+	DailyRequests int    `json:"daily_requests,omitempty"`
+	DailyTokens   int    `json:"daily_tokens,omitempty"`
+}
+
+// HashKey retorna o hash SHA-256 em hexadecimal de uma chave
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateKey gera uma nova chave aleatória com o prefixo KeyPrefix
+func GenerateKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro ao gerar chave: %w", err)
+	}
+	return KeyPrefix + hex.EncodeToString(b), nil
+}
+
+// Authenticator valida as chaves e contabiliza o uso diário de cada uma.
+// Os contadores ficam em memória e são zerados à meia-noite (UTC).
+type Authenticator struct {
+	byHash map[string]*Key
+
+	mu    sync.Mutex
+	day   string
+	usage map[string]*Usage // Por nome da chave
+	now   func() time.Time
+}
+
+// NewAuthenticator cria um Authenticator com as chaves informadas. Nomes e
+// hashes precisam ser únicos.
+func NewAuthenticator(keys []Key) (*Authenticator, error) {
+	a := &Authenticator{byHash: make(map[string]*Key), usage: make(map[string]*Usage), now: time.Now}
+	names := make(map[string]bool)
+	for i := range keys {
+		k := keys[i]
+		switch {
+		case k.Name == "":
+			return nil, fmt.Errorf("chave %d sem nome", i+1)
+		case len(k.Hash) != sha256.Size*2:
+			return nil, fmt.Errorf("chave %s: hash SHA-256 inválido", k.Name)
+		case names[k.Name]:
+			return nil, fmt.Errorf("chave %s definida mais de uma vez", k.Name)
+		case a.byHash[k.Hash] != nil:
+			return nil, fmt.Errorf("chave %s repete o valor da chave %s", k.Name, a.byHash[k.Hash].Name)
+		}
+		names[k.Name] = true
+		a.byHash[k.Hash] = &k
+	}
+	return a, nil
+}
+
+// Len retorna o número de chaves cadastradas
+func (a *Authenticator) Len() int {
+	return len(a.byHash)
+}
+
+// Authenticate retorna a chave correspondente ao token, ou nil se ele for inválido
+func (a *Authenticator) Authenticate(token string) *Key {
+	if token == "" {
+		return nil
+	}
+	return a.byHash[HashKey(token)]
+}
+
+// Check retorna *QuotaError se a cota de requisições ou de tokens do dia da
+// chave já tiver sido atingida, sem registrar uma requisição
+func (a *Authenticator) Check(k *Key) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.check(k, a.usageFor(k))
+}
+
+// Begin registra uma requisição da chave, recusando-a com *QuotaError se a
+// cota de requisições ou de tokens do dia já tiver sido atingida
+func (a *Authenticator) Begin(k *Key) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	u := a.usageFor(k)
+	if err := a.check(k, u); err != nil {
+		return err
+	}
+	u.Requests++
+	return nil
+}
+
+// check compara o uso u com as cotas de k. Deve ser chamada com a.mu travado.
+func (a *Authenticator) check(k *Key, u *Usage) error {
+	if k.DailyRequests > 0 && u.Requests >= k.DailyRequests {
+		return &QuotaError{Quota: "requests", Limit: k.DailyRequests, ResetAt: a.resetAt()}
+	}
+	if k.DailyTokens > 0 && u.Tokens >= k.DailyTokens {
+		return &QuotaError{Quota: "tokens", Limit: k.DailyTokens, ResetAt: a.resetAt()}
+	}
+	return nil
+}
+
+// AddTokens soma os tokens consumidos por uma explicação à cota da chave
+func (a *Authenticator) AddTokens(k *Key, tokens int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.usageFor(k).Tokens += tokens
+}
+
+// Usage retorna o consumo do dia de todas as chaves, ordenado pelo nome
+func (a *Authenticator) Usage() []Usage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := make([]Usage, 0, len(a.byHash))
+	for _, k := range a.byHash {
+		result = append(result, *a.usageFor(k))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// usageFor retorna o contador do dia da chave, zerando todos na virada do dia.
+// Deve ser chamada com a.mu travado.
+func (a *Authenticator) usageFor(k *Key) *Usage {
+	if today := a.now().UTC().Format(time.DateOnly); today != a.day {
+		a.day = today
+		a.usage = make(map[string]*Usage)
+	}
+	u := a.usage[k.Name]
+	if u == nil {
+		u = &Usage{Name: k.Name, Admin: k.Admin, Day: a.day, DailyRequests: k.DailyRequests, DailyTokens: k.DailyTokens}
+		a.usage[k.Name] = u
+	}
+	return u
+}
+
+// resetAt retorna a próxima meia-noite (UTC), quando as cotas são renovadas
+func (a *Authenticator) resetAt() time.Time {
+	now := a.now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+type contextKey struct{}
+
+// WithKey retorna uma cópia de ctx com a chave autenticada
+func WithKey(ctx context.Context, k *Key) context.Context {
+	return context.WithValue(ctx, contextKey{}, k)
+}
+
+// KeyFrom retorna a chave autenticada em ctx, ou nil
+func KeyFrom(ctx context.Context) *Key {
+	k, _ := ctx.Value(contextKey{}).(*Key)
+	return k
+}
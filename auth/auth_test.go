@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAuthenticator(t *testing.T) {
+	hash := HashKey("segredo")
+	tests := []struct {
+		name    string
+		keys    []Key
+		wantErr string
+	}{
+		{name: "Válidas", keys: []Key{{Name: "ana", Hash: hash}, {Name: "bia", Hash: HashKey("outro")}}},
+		{name: "Sem nome", keys: []Key{{Hash: hash}}, wantErr: "sem nome"},
+		{name: "Hash inválido", keys: []Key{{Name: "ana", Hash: "abc"}}, wantErr: "hash SHA-256 inválido"},
+		{name: "Nome repetido", keys: []Key{{Name: "ana", Hash: hash}, {Name: "ana", Hash: HashKey("outro")}}, wantErr: "mais de uma vez"},
+		{name: "Chave repetida", keys: []Key{{Name: "ana", Hash: hash}, {Name: "bia", Hash: hash}}, wantErr: "repete o valor da chave ana"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAuthenticator(tt.keys)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("NewAuthenticator() erro = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a, err := NewAuthenticator([]Key{{Name: "ana", Hash: HashKey("ce_ana")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if k := a.Authenticate("ce_ana"); k == nil || k.Name != "ana" {
+		t.Errorf("Authenticate(ce_ana) = %v, want ana", k)
+	}
+	for _, token := range []string{"", "ce_bia", HashKey("ce_ana")} {
+		if k := a.Authenticate(token); k != nil {
+			t.Errorf("Authenticate(%q) = %v, want nil", token, k)
+		}
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	now := time.Date(2024, 5, 10, 23, 0, 0, 0, time.UTC)
+	a, _ := NewAuthenticator([]Key{
+		{Name: "requisicoes", Hash: HashKey("a"), DailyRequests: 2},
+		{Name: "tokens", Hash: HashKey("b"), DailyTokens: 100},
+	})
+	a.now = func() time.Time { return now }
+	reqKey, tokKey := a.Authenticate("a"), a.Authenticate("b")
+
+	// Check não conta a requisição
+	for i := 0; i < 3; i++ {
+		if err := a.Check(reqKey); err != nil {
+			t.Fatalf("Check() %d: %v", i+1, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := a.Begin(reqKey); err != nil {
+			t.Fatalf("requisição %d: %v", i+1, err)
+		}
+	}
+	err := a.Begin(reqKey)
+	var quotaErr *QuotaError
+	if !errors.As(err, &quotaErr) || !errors.Is(err, ErrQuotaExceeded) || quotaErr.Quota != "requests" {
+		t.Fatalf("terceira requisição: erro = %v, want cota de requisições", err)
+	}
+	if want := time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC); !quotaErr.ResetAt.Equal(want) {
+		t.Errorf("ResetAt = %v, want %v", quotaErr.ResetAt, want)
+	}
+	if err := a.Check(reqKey); !errors.As(err, &quotaErr) || quotaErr.Quota != "requests" {
+		t.Errorf("Check() com a cota esgotada: erro = %v, want cota de requisições", err)
+	}
+
+	if err := a.Begin(tokKey); err != nil {
+		t.Fatal(err)
+	}
+	a.AddTokens(tokKey, 150)
+	if err := a.Begin(tokKey); !errors.As(err, &quotaErr) || quotaErr.Quota != "tokens" {
+		t.Errorf("após 150 tokens: erro = %v, want cota de tokens", err)
+	}
+
+	usage := a.Usage()
+	if len(usage) != 2 || usage[0].Name != "requisicoes" || usage[0].Requests != 2 || usage[1].Tokens != 150 || usage[1].Day != "2024-05-10" {
+		t.Errorf("Usage() = %+v", usage)
+	}
+
+	// Na virada do dia (UTC) as cotas são renovadas
+	now = now.Add(2 * time.Hour)
+	if err := a.Begin(reqKey); err != nil {
+		t.Errorf("no dia seguinte: erro = %v", err)
+	}
+	if err := a.Begin(tokKey); err != nil {
+		t.Errorf("no dia seguinte: erro = %v", err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	s := &Store{Path: filepath.Join(t.TempDir(), "state", "keys.json")}
+
+	if keys, err := s.Load(); err != nil || len(keys) != 0 {
+		t.Fatalf("Load() sem arquivo = %v, %v", keys, err)
+	}
+
+	token, err := s.Add(Key{Name: "ana", DailyRequests: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, KeyPrefix) {
+		t.Errorf("chave gerada = %q, want prefixo %s", token, KeyPrefix)
+	}
+	if _, err := s.Add(Key{Name: "ana"}); err == nil {
+		t.Error("Add() com nome repetido deveria falhar")
+	}
+
+	data, _ := os.ReadFile(s.Path)
+	if strings.Contains(string(data), token) {
+		t.Error("o arquivo não deve conter a chave em texto puro")
+	}
+	if info, _ := os.Stat(s.Path); info.Mode().Perm() != 0o600 {
+		t.Errorf("permissões = %v, want 0600", info.Mode().Perm())
+	}
+
+	keys, _ := s.Load()
+	a, err := NewAuthenticator(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := a.Authenticate(token); k == nil || k.Name != "ana" || k.DailyRequests != 10 || k.CreatedAt.IsZero() {
+		t.Errorf("Authenticate() = %+v", k)
+	}
+
+	if err := s.Remove("ana"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove("ana"); err == nil {
+		t.Error("Remove() de chave inexistente deveria falhar")
+	}
+	if keys, _ := s.Load(); len(keys) != 0 {
+		t.Errorf("Load() após Remove = %v", keys)
+	}
+}
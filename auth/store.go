@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Store guarda as chaves em um arquivo JSON local; apenas os hashes são gravados
+type Store struct {
+	Path string
+}
+
+// storeFile é o formato do arquivo do Store
+type storeFile struct {
+	Keys []Key `json:"keys"`
+}
+
+// DefaultStorePath retorna $XDG_STATE_HOME/code-explainer/keys.json (ou ~/.local/state)
+func DefaultStorePath() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("não foi possível determinar o diretório de estado: %w", err)
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "code-explainer", "keys.json"), nil
+}
+
+// Load lê as chaves do arquivo; se ele não existir, retorna uma lista vazia
+func (s *Store) Load() ([]Key, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", s.Path, err)
+	}
+
+	var f storeFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: arquivo de chaves inválido: %w", s.Path, err)
+	}
+	return f.Keys, nil
+}
+
+// Save grava as chaves, acessíveis apenas pelo dono do arquivo
+func (s *Store) Save(keys []Key) error {
+	data, err := json.MarshalIndent(storeFile{Keys: keys}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return err
+	}
+
+	// Grava em um arquivo temporário e renomeia, para não corromper o original
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// Add gera uma nova chave com os dados de k e grava seu hash. A chave em texto puro é
+// retornada apenas aqui e não pode ser recuperada depois.
+func (s *Store) Add(k Key) (string, error) {
+	keys, err := s.Load()
+	if err != nil {
+		return "", err
+	}
+	for _, existing := range keys {
+		if existing.Name == k.Name {
+			return "", fmt.Errorf("já existe uma chave chamada %s", k.Name)
+		}
+	}
+
+	token, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	k.Hash = HashKey(token)
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	}
+
+	if err := s.Save(append(keys, k)); err != nil {
+		return "", fmt.Errorf("erro ao gravar %s: %w", s.Path, err)
+	}
+	return token, nil
+}
+
+// Remove apaga a chave chamada name
+func (s *Store) Remove(name string) error {
+	keys, err := s.Load()
+	if err != nil {
+		return err
+	}
+	for i, k := range keys {
+		if k.Name == name {
+			if err := s.Save(append(keys[:i], keys[i+1:]...)); err != nil {
+				return fmt.Errorf("erro ao gravar %s: %w", s.Path, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("chave %s não encontrada", name)
+}
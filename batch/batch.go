@@ -0,0 +1,259 @@
+// Package batch lê arquivos JSONL de requisições de explicação e grava os
+// resultados correspondentes, um por linha, permitindo retomar execuções.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/policy"
+)
+
+// maxLineSize limita o tamanho de cada linha JSONL (código embutido pode ser grande)
+const maxLineSize = 10 * 1024 * 1024
+
+// Tipos de erro registrados nos resultados
+const (
+	ErrorInvalidRequest = "invalid_request"
+	ErrorInput          = "input_error"
+	ErrorTimeout        = "timeout"
+	ErrorModelNotFound  = "model_not_found"
+	ErrorContextTooLong = "context_too_long"
+	ErrorUnavailable    = "backend_unavailable"
+	ErrorCanceled       = "canceled"
+	ErrorPolicy         = "policy_blocked"
+	ErrorAPI            = "api_error"
+	ErrorExplain        = "explain_error"
+)
+
+// Request representa uma linha do arquivo de entrada
+type Request struct {
+	ID       string `json:"id,omitempty"`
+	Code     string `json:"code,omitempty"`
+	File     string `json:"file,omitempty"`
+	Language string `json:"language,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Level    string `json:"level,omitempty"`
+}
+
+// Entry é uma requisição lida do arquivo junto com o número da sua linha (a partir de 1)
+type Entry struct {
+	Line    int
+	Request Request
+	Err     error // Erro de parsing ou validação da linha
+}
+
+// Result representa uma linha do arquivo de saída
+type Result struct {
+	Line        int    `json:"line"`
+	ID          string `json:"id,omitempty"`
+	File        string `json:"file,omitempty"`
+	Language    string `json:"language,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Level       string `json:"level,omitempty"`
+	Explanation string `json:"explanation,omitempty"`
+	Error       *Error `json:"error,omitempty"`
+	DurationMs  int64  `json:"duration_ms"`
+
+	Usage *openai.Usage `json:"usage,omitempty"` // Ausente em respostas do cache
+}
+
+// Error é o erro estruturado registrado quando uma requisição falha
+type Error struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// ReadRequests lê todas as requisições do arquivo JSONL. Linhas em branco são
+// ignoradas; linhas inválidas geram uma Entry com Err preenchido em vez de
+// interromper a leitura.
+func ReadRequests(r io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	var entries []Entry
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		entry := Entry{Line: line}
+		if err := json.Unmarshal([]byte(text), &entry.Request); err != nil {
+			entry.Err = fmt.Errorf("JSON inválido: %w", err)
+		} else {
+			entry.Err = Validate(entry.Request)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("erro ao ler linha %d: %w", line+1, err)
+	}
+
+	return entries, nil
+}
+
+// Validate verifica se a requisição tem exatamente uma fonte de código e um nível válido
+func Validate(req Request) error {
+	if req.Code == "" && req.File == "" {
+		return fmt.Errorf("informe \"code\" ou \"file\"")
+	}
+	if req.Code != "" && req.File != "" {
+		return fmt.Errorf("\"code\" e \"file\" são mutuamente exclusivos")
+	}
+	return openai.ValidateLevel(req.Level)
+}
+
+// retryable são os tipos de erro transitórios, que --resume tenta novamente
+var retryable = map[string]bool{
+	ErrorTimeout:     true,
+	ErrorUnavailable: true,
+	ErrorCanceled:    true,
+}
+
+// CompletedLines lê um arquivo de resultados existente e retorna as linhas já
+// processadas. Linhas corrompidas (ex.: escrita interrompida) e falhas
+// transitórias (timeout, servidor indisponível, cancelamento) são ignoradas,
+// para que sejam reprocessadas.
+func CompletedLines(r io.Reader) (map[int]bool, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	completed := make(map[int]bool)
+	for scanner.Scan() {
+		if line, ok := completedLine(scanner.Bytes()); ok {
+			completed[line] = true
+		}
+	}
+
+	return completed, scanner.Err()
+}
+
+// Compact reescreve o arquivo de resultados em path apenas com o primeiro resultado
+// concluído de cada linha, descartando linhas corrompidas e falhas transitórias, que
+// serão refeitas, e retorna as linhas concluídas. Assim os novos resultados podem ser
+// acrescentados sem que uma linha de entrada fique com dois resultados. Se o arquivo
+// não existir, não há linhas concluídas.
+func Compact(path string) (map[int]bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[int]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	completed := make(map[int]bool)
+	var kept bytes.Buffer
+	for _, record := range bytes.Split(data, []byte("\n")) {
+		line, ok := completedLine(record)
+		if !ok || completed[line] {
+			continue
+		}
+		completed[line] = true
+		kept.Write(record)
+		kept.WriteByte('\n')
+	}
+	if bytes.Equal(kept.Bytes(), data) {
+		return completed, nil
+	}
+
+	return completed, rewrite(path, kept.Bytes())
+}
+
+// completedLine retorna o número da linha de entrada de um resultado, se ele estiver
+// íntegro e não for uma falha transitória
+func completedLine(record []byte) (int, bool) {
+	var result Result
+	if err := json.Unmarshal(record, &result); err != nil || result.Line <= 0 {
+		return 0, false
+	}
+	if result.Error != nil && retryable[result.Error.Type] {
+		return 0, false
+	}
+	return result.Line, true
+}
+
+// rewrite substitui o conteúdo de path por data através de um arquivo temporário,
+// para que uma interrupção não deixe o arquivo pela metade
+func rewrite(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".results-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// NewError converte um erro em um Error estruturado, classificando seu tipo
+func NewError(errType string, err error) *Error {
+	if errType == "" {
+		var apiErr *openai.APIError
+		switch {
+		case errors.Is(err, policy.ErrBlocked):
+			errType = ErrorPolicy
+		case errors.Is(err, context.Canceled):
+			errType = ErrorCanceled
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, openai.ErrTimeout):
+			errType = ErrorTimeout
+		case errors.Is(err, openai.ErrModelNotFound):
+			errType = ErrorModelNotFound
+		case errors.Is(err, openai.ErrContextTooLong):
+			errType = ErrorContextTooLong
+		case errors.Is(err, openai.ErrServerUnavailable):
+			errType = ErrorUnavailable
+		case errors.As(err, &apiErr):
+			errType = ErrorAPI
+		default:
+			errType = ErrorExplain
+		}
+	}
+
+	return &Error{Type: errType, Message: err.Error()}
+}
+
+// Writer grava resultados no formato JSONL
+type Writer struct {
+	enc *json.Encoder
+}
+
+// NewWriter cria um Writer que grava em w
+func NewWriter(w io.Writer) *Writer {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &Writer{enc: enc}
+}
+
+// Write grava um resultado como uma linha JSON
+func (w *Writer) Write(result Result) error {
+	return w.enc.Encode(result)
+}
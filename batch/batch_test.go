@@ -0,0 +1,200 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/policy"
+)
+
+func TestReadRequests(t *testing.T) {
+	input := `{"id": "a", "code": "print('oi')", "level": "basico"}
+
+{"file": "main.go", "model": "llama2"}
+not json
+{"id": "vazio"}
+{"code": "x", "file": "y"}
+{"code": "x", "level": "expert"}
+`
+
+	entries, err := ReadRequests(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadRequests() error = %v", err)
+	}
+
+	tests := []struct {
+		line    int
+		wantErr bool
+	}{
+		{line: 1, wantErr: false},
+		{line: 3, wantErr: false},
+		{line: 4, wantErr: true},
+		{line: 5, wantErr: true},
+		{line: 6, wantErr: true},
+		{line: 7, wantErr: true},
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("Expected %d entries, got %d", len(tests), len(entries))
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("linha %d", tt.line), func(t *testing.T) {
+			entry := entries[i]
+			if entry.Line != tt.line {
+				t.Errorf("Expected line %d, got %d", tt.line, entry.Line)
+			}
+			if (entry.Err != nil) != tt.wantErr {
+				t.Errorf("Expected error = %v, got %v", tt.wantErr, entry.Err)
+			}
+		})
+	}
+
+	if entries[0].Request.Level != "basico" || entries[1].Request.Model != "llama2" {
+		t.Errorf("Request fields were not decoded: %+v %+v", entries[0].Request, entries[1].Request)
+	}
+}
+
+func TestCompletedLines(t *testing.T) {
+	output := `{"line": 1, "explanation": "ok", "duration_ms": 10}
+{"line": 2, "error": {"type": "model_not_found", "message": "modelo"}, "duration_ms": 30}
+{"line": 3, "error": {"type": "timeout", "message": "deadline"}, "duration_ms": 30000}
+{"line": 5, "error": {"type": "backend_unavailable", "message": "conexão recusada"}, "duration_ms": 5}
+{"line": 6, "error": {"type": "canceled", "message": "context canceled"}, "duration_ms": 5}
+{"line": 4, "explanation": "parcial...`
+
+	completed, err := CompletedLines(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("CompletedLines() error = %v", err)
+	}
+
+	if !completed[1] || !completed[2] {
+		t.Errorf("Expected lines 1 and 2 to be completed, got %v", completed)
+	}
+	for _, line := range []int{3, 5, 6} {
+		if completed[line] {
+			t.Errorf("Transient failure on line %d should be retried", line)
+		}
+	}
+	if completed[4] {
+		t.Errorf("Truncated line 4 should not be considered completed")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		expected  string
+		completed []int
+	}{
+		{name: "Arquivo vazio", content: "", expected: ""},
+		{name: "Linhas completas", content: "{\"line\":1}\n{\"line\":2}\n", expected: "{\"line\":1}\n{\"line\":2}\n", completed: []int{1, 2}},
+		{name: "Última linha incompleta", content: "{\"line\":1}\n{\"line\":2,\"expl", expected: "{\"line\":1}\n", completed: []int{1}},
+		{name: "Apenas linha incompleta", content: "{\"line\":1", expected: ""},
+		{name: "Linha incompleta longa", content: "{\"line\":1}\n" + strings.Repeat("x", 200*1024), expected: "{\"line\":1}\n", completed: []int{1}},
+		{
+			name:      "Falha transitória substituída",
+			content:   "{\"line\":1,\"error\":{\"type\":\"timeout\",\"message\":\"x\"}}\n{\"line\":2}\n",
+			expected:  "{\"line\":2}\n",
+			completed: []int{2},
+		},
+		{
+			name:      "Resultado repetido de uma execução anterior",
+			content:   "{\"line\":1,\"error\":{\"type\":\"timeout\",\"message\":\"x\"}}\n{\"line\":1,\"explanation\":\"a\"}\n{\"line\":1,\"explanation\":\"b\"}\n",
+			expected:  "{\"line\":1,\"explanation\":\"a\"}\n",
+			completed: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "results.jsonl")
+			os.WriteFile(path, []byte(tt.content), 0o644)
+
+			completed, err := Compact(path)
+			if err != nil {
+				t.Fatalf("Compact() error = %v", err)
+			}
+			if len(completed) != len(tt.completed) {
+				t.Errorf("completed = %v, want %v", completed, tt.completed)
+			}
+			for _, line := range tt.completed {
+				if !completed[line] {
+					t.Errorf("line %d should be completed, got %v", line, completed)
+				}
+			}
+
+			data, _ := os.ReadFile(path)
+			if string(data) != tt.expected {
+				t.Errorf("content = %q, want %q", data, tt.expected)
+			}
+		})
+	}
+
+	if completed, err := Compact(filepath.Join(t.TempDir(), "nao-existe.jsonl")); err != nil || len(completed) != 0 {
+		t.Errorf("Compact() of missing file = %v, %v", completed, err)
+	}
+}
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		name     string
+		errType  string
+		err      error
+		expected string
+	}{
+		{name: "Tipo explícito", errType: ErrorInput, err: errors.New("arquivo"), expected: ErrorInput},
+		{name: "Timeout", err: fmt.Errorf("falha: %w", context.DeadlineExceeded), expected: ErrorTimeout},
+		{name: "Modelo inexistente", err: &openai.APIError{StatusCode: 404, Kind: openai.ErrModelNotFound}, expected: ErrorModelNotFound},
+		{name: "Cancelado", err: fmt.Errorf("falha: %w", context.Canceled), expected: ErrorCanceled},
+		{name: "Servidor indisponível", err: fmt.Errorf("conexão: %w", openai.ErrServerUnavailable), expected: ErrorUnavailable},
+		{name: "Destino bloqueado", err: &policy.BlockedError{Host: "api.example.com"}, expected: ErrorPolicy},
+		{name: "Erro da API", err: &openai.APIError{StatusCode: 500}, expected: ErrorAPI},
+		{name: "Erro genérico", err: errors.New("falha"), expected: ErrorExplain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewError(tt.errType, tt.err)
+			if result.Type != tt.expected {
+				t.Errorf("NewError() type = %v, want %v", result.Type, tt.expected)
+			}
+			if result.Message != tt.err.Error() {
+				t.Errorf("NewError() message = %v, want %v", result.Message, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+
+	writer.Write(Result{Line: 1, Explanation: "usa <html> & mais"})
+	writer.Write(Result{Line: 2, Error: &Error{Type: ErrorAPI, Message: "falhou"}})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "<html> & mais") {
+		t.Errorf("Expected HTML characters to be written unescaped, got %s", lines[0])
+	}
+
+	var result Result
+	if err := json.Unmarshal([]byte(lines[1]), &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result.Error == nil || result.Error.Type != ErrorAPI {
+		t.Errorf("Expected structured error, got %+v", result.Error)
+	}
+}
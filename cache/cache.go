@@ -0,0 +1,308 @@
+// Package cache implementa um cache em disco, endereçado por conteúdo, para as
+// explicações geradas pelo modelo.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// entryExt é a extensão dos arquivos de entrada do cache
+const entryExt = ".json"
+
+// Cache armazena valores em arquivos dentro de Dir, um por chave
+type Cache struct {
+	Dir      string
+	TTL      time.Duration // Entradas mais antigas são consideradas expiradas (0 = sem expiração)
+	MaxBytes int64         // Tamanho máximo do cache em bytes (0 = sem limite)
+}
+
+// entry é o conteúdo gravado em disco para cada chave
+type entry struct {
+	CreatedAt time.Time `json:"created_at"`
+	Value     string    `json:"value"`
+}
+
+// Stats resume o estado do cache
+type Stats struct {
+	Dir     string
+	Entries int
+	Expired int
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// fileInfo descreve um arquivo de entrada durante varreduras
+type fileInfo struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// DefaultDir retorna o diretório padrão do cache: $XDG_CACHE_HOME/code-explainer,
+// ou o diretório de cache do usuário no sistema operacional
+func DefaultDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		var err error
+		base, err = os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("não foi possível determinar o diretório de cache: %w", err)
+		}
+	}
+	return filepath.Join(base, "code-explainer"), nil
+}
+
+// New cria um cache no diretório informado
+func New(dir string, ttl time.Duration, maxBytes int64) *Cache {
+	return &Cache{Dir: dir, TTL: ttl, MaxBytes: maxBytes}
+}
+
+// Key calcula a chave de cache a partir das partes informadas. Cada parte é
+// prefixada pelo seu tamanho para que ("ab", "c") e ("a", "bc") gerem chaves diferentes.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:%s;", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path retorna o caminho do arquivo da chave, distribuído em subdiretórios pelo prefixo
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key+entryExt)
+}
+
+// Get retorna o valor associado à chave. Entradas expiradas são removidas e tratadas como ausentes.
+func (c *Cache) Get(key string) (string, bool, error) {
+	path := c.path(key)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		// Entrada corrompida: descarta
+		os.Remove(path)
+		return "", false, nil
+	}
+
+	if c.expired(e.CreatedAt) {
+		os.Remove(path)
+		return "", false, nil
+	}
+
+	// Atualiza o horário de modificação para que a remoção por tamanho siga a ordem de uso
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return e.Value, true, nil
+}
+
+// Put grava o valor associado à chave e, se necessário, remove as entradas menos
+// usadas recentemente para respeitar MaxBytes
+func (c *Cache) Put(key, value string) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de cache: %w", err)
+	}
+
+	data, err := json.Marshal(entry{CreatedAt: time.Now(), Value: value})
+	if err != nil {
+		return err
+	}
+
+	// Grava em arquivo temporário e renomeia para evitar entradas parciais
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-*")
+	if err != nil {
+		return fmt.Errorf("erro ao gravar entrada de cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("erro ao gravar entrada de cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("erro ao gravar entrada de cache: %w", err)
+	}
+
+	if c.MaxBytes > 0 {
+		if _, _, err := c.evict(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stats percorre o cache e retorna suas estatísticas
+func (c *Cache) Stats() (Stats, error) {
+	stats := Stats{Dir: c.Dir}
+
+	files, err := c.files()
+	if err != nil {
+		return stats, err
+	}
+
+	for _, f := range files {
+		stats.Entries++
+		stats.Bytes += f.size
+
+		createdAt, err := readCreatedAt(f.path)
+		if err != nil {
+			continue
+		}
+		if c.expired(createdAt) {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || createdAt.Before(stats.Oldest) {
+			stats.Oldest = createdAt
+		}
+		if createdAt.After(stats.Newest) {
+			stats.Newest = createdAt
+		}
+	}
+
+	return stats, nil
+}
+
+// Clear remove todas as entradas do cache e retorna quantas foram removidas
+func (c *Cache) Clear() (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, f := range files {
+		if err := os.Remove(f.path); err == nil {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// Prune remove as entradas expiradas ou corrompidas e, em seguida, as menos usadas
+// recentemente até respeitar MaxBytes. Retorna a quantidade de entradas e bytes removidos.
+func (c *Cache) Prune() (int, int64, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	removed := 0
+	var freed int64
+	for _, f := range files {
+		createdAt, err := readCreatedAt(f.path)
+		if err != nil || c.expired(createdAt) {
+			if os.Remove(f.path) == nil {
+				removed++
+				freed += f.size
+			}
+		}
+	}
+
+	n, bytes, err := c.evict()
+	return removed + n, freed + bytes, err
+}
+
+// evict remove as entradas menos usadas recentemente até o cache caber em MaxBytes
+func (c *Cache) evict() (int, int64, error) {
+	if c.MaxBytes <= 0 {
+		return 0, 0, nil
+	}
+
+	files, err := c.files()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	removed := 0
+	var freed int64
+	for _, f := range files {
+		if total <= c.MaxBytes {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+			freed += f.size
+			removed++
+		}
+	}
+
+	return removed, freed, nil
+}
+
+// files lista os arquivos de entrada do cache. Um diretório inexistente equivale a um cache vazio.
+func (c *Cache) files() ([]fileInfo, error) {
+	var files []fileInfo
+
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), entryExt) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, fileInfo{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+
+	return files, err
+}
+
+// expired informa se uma entrada criada no instante informado já expirou
+func (c *Cache) expired(createdAt time.Time) bool {
+	return c.TTL > 0 && time.Since(createdAt) > c.TTL
+}
+
+// readCreatedAt lê apenas o horário de criação de uma entrada
+func readCreatedAt(path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return time.Time{}, err
+	}
+	return e.CreatedAt, nil
+}
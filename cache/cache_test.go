@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     []string
+		b     []string
+		equal bool
+	}{
+		{name: "Mesmas partes", a: []string{"code", "Go"}, b: []string{"code", "Go"}, equal: true},
+		{name: "Modelo diferente", a: []string{"code", "codellama"}, b: []string{"code", "llama2"}, equal: false},
+		{name: "Fronteira entre partes", a: []string{"ab", "c"}, b: []string{"a", "bc"}, equal: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (Key(tt.a...) == Key(tt.b...)) != tt.equal {
+				t.Errorf("Key(%v) == Key(%v) should be %v", tt.a, tt.b, tt.equal)
+			}
+		})
+	}
+}
+
+func TestCachePutGet(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, 0)
+	key := Key("func main() {}", "Go", "codellama")
+
+	if _, ok, err := c.Get(key); ok || err != nil {
+		t.Fatalf("Expected miss on empty cache, got ok=%v err=%v", ok, err)
+	}
+
+	if err := c.Put(key, "explicação"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	value, ok, err := c.Get(key)
+	if err != nil || !ok {
+		t.Fatalf("Expected hit, got ok=%v err=%v", ok, err)
+	}
+	if value != "explicação" {
+		t.Errorf("Expected cached value, got %q", value)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := New(t.TempDir(), time.Millisecond, 0)
+	key := Key("code")
+
+	c.Put(key, "valor")
+	time.Sleep(5 * time.Millisecond)
+
+	stats, _ := c.Stats()
+	if stats.Expired != 1 {
+		t.Errorf("Expected 1 expired entry, got %d", stats.Expired)
+	}
+
+	if _, ok, _ := c.Get(key); ok {
+		t.Errorf("Expected expired entry to be a miss")
+	}
+	if stats, _ := c.Stats(); stats.Entries != 0 {
+		t.Errorf("Expected expired entry to be removed, got %d entries", stats.Entries)
+	}
+}
+
+func TestCacheSizeEviction(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, 0, 0)
+
+	value := strings.Repeat("x", 1000)
+	keys := []string{Key("a"), Key("b"), Key("c")}
+	for i, key := range keys {
+		c.Put(key, value)
+		// Garante horários de uso distintos
+		past := time.Now().Add(time.Duration(i-10) * time.Minute)
+		os.Chtimes(c.path(key), past, past)
+	}
+
+	// Acessar "a" o torna o mais recente
+	c.Get(keys[0])
+
+	stats, _ := c.Stats()
+	c.MaxBytes = stats.Bytes - 1
+	removed, freed, err := c.Prune()
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if removed != 1 || freed == 0 {
+		t.Errorf("Expected 1 entry evicted, got %d (%d bytes)", removed, freed)
+	}
+
+	if _, ok, _ := c.Get(keys[1]); ok {
+		t.Errorf("Expected least recently used entry to be evicted")
+	}
+	if _, ok, _ := c.Get(keys[0]); !ok {
+		t.Errorf("Expected recently used entry to be kept")
+	}
+}
+
+func TestCacheClearAndCorruptEntries(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, time.Hour, 0)
+
+	c.Put(Key("a"), "1")
+	c.Put(Key("b"), "2")
+
+	corrupt := filepath.Join(dir, "zz", "corrompido.json")
+	os.MkdirAll(filepath.Dir(corrupt), 0o755)
+	os.WriteFile(corrupt, []byte("{"), 0o644)
+
+	removed, _, err := c.Prune()
+	if err != nil || removed != 1 {
+		t.Errorf("Expected Prune() to remove the corrupt entry, got %d (%v)", removed, err)
+	}
+
+	removed, err = c.Clear()
+	if err != nil || removed != 2 {
+		t.Errorf("Expected Clear() to remove 2 entries, got %d (%v)", removed, err)
+	}
+}
+
+func TestCacheMissingDir(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "inexistente"), 0, 0)
+
+	stats, err := c.Stats()
+	if err != nil || stats.Entries != 0 {
+		t.Errorf("Expected empty stats for missing dir, got %+v (%v)", stats, err)
+	}
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg-cache")
+
+	dir, err := DefaultDir()
+	if err != nil {
+		t.Fatalf("DefaultDir() error = %v", err)
+	}
+	if dir != filepath.Join("/tmp/xdg-cache", "code-explainer") {
+		t.Errorf("Expected dir under XDG_CACHE_HOME, got %s", dir)
+	}
+}
@@ -0,0 +1,143 @@
+// Package chat mantém o histórico de uma conversa sobre um trecho de código,
+// permitindo perguntas de acompanhamento ao modelo.
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// SendFunc envia as mensagens ao modelo; por padrão openai.Chat
+type SendFunc func(ctx context.Context, messages []openai.Message, config *openai.Config) (string, error)
+
+// Session é uma conversa sobre um trecho de código
+type Session struct {
+	Config   openai.Config
+	Code     string
+	Language string
+	Messages []openai.Message // Histórico, começando pela mensagem de sistema
+	Send     SendFunc
+}
+
+// NewSession cria uma sessão para o código. A linguagem é detectada se config.Language estiver vazia.
+func NewSession(code string, config *openai.Config) *Session {
+	s := &Session{Config: *config, Code: code, Send: openai.Chat}
+	s.Language = config.Language
+	if s.Language == "" {
+		s.Language = openai.DetectLanguage(code)
+	}
+	s.Reset()
+	return s
+}
+
+// Reset descarta as perguntas e respostas, mantendo o código em discussão
+func (s *Session) Reset() {
+	s.Messages = []openai.Message{
+		{Role: openai.RoleSystem, Content: s.systemPrompt()},
+	}
+}
+
+// SetLevel altera o nível de detalhamento das próximas respostas
+func (s *Session) SetLevel(level string) error {
+	if err := openai.ValidateLevel(level); err != nil {
+		return err
+	}
+	s.Config.Level = level
+	s.Messages[0].Content = s.systemPrompt()
+	return nil
+}
+
+// Explain pede a explicação inicial do código
+func (s *Session) Explain(ctx context.Context) (string, error) {
+	return s.ask(ctx, openai.BuildPrompt(s.Code, s.Language, ""))
+}
+
+// Ask faz uma pergunta de acompanhamento sobre o código
+func (s *Session) Ask(ctx context.Context, question string) (string, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return "", fmt.Errorf("pergunta vazia")
+	}
+	return s.ask(ctx, question)
+}
+
+// Turns retorna o número de perguntas já respondidas
+func (s *Session) Turns() int {
+	turns := 0
+	for _, m := range s.Messages {
+		if m.Role == openai.RoleAssistant {
+			turns++
+		}
+	}
+	return turns
+}
+
+// ask adiciona a mensagem ao histórico apenas se o modelo responder
+func (s *Session) ask(ctx context.Context, content string) (string, error) {
+	messages := append(append([]openai.Message(nil), s.Messages...), openai.Message{Role: openai.RoleUser, Content: content})
+
+	answer, err := s.Send(ctx, messages, &s.Config)
+	if err != nil {
+		return "", err
+	}
+
+	s.Messages = append(messages, openai.Message{Role: openai.RoleAssistant, Content: answer})
+	return answer, nil
+}
+
+// systemPrompt descreve o papel do modelo e o nível de detalhamento
+func (s *Session) systemPrompt() string {
+	prompt := fmt.Sprintf("Você é um assistente que explica código em %s. Responda em português às perguntas do usuário sobre o código enviado.", s.Language)
+	if instructions := openai.LevelInstructions(s.Config.Level); instructions != "" {
+		prompt += " " + instructions
+	}
+	return prompt
+}
+
+// Transcript formata a conversa em Markdown, sem a mensagem de sistema
+func (s *Session) Transcript() string {
+	var b strings.Builder
+
+	b.WriteString("# Conversa sobre código\n\n")
+	fmt.Fprintf(&b, "- **Data:** %s\n", time.Now().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "- **Modelo:** %s\n", s.Config.Model)
+	fmt.Fprintf(&b, "- **Linguagem:** %s\n\n", s.Language)
+	b.WriteString("```\n" + s.Code + "\n```\n")
+
+	for i, m := range s.Messages {
+		switch m.Role {
+		case openai.RoleUser:
+			// A primeira mensagem é o pedido de explicação, que já contém o código
+			if i == 1 && strings.Contains(m.Content, s.Code) {
+				b.WriteString("\n## Explicação\n\n")
+				continue
+			}
+			b.WriteString("\n## " + m.Content + "\n\n")
+		case openai.RoleAssistant:
+			b.WriteString(m.Content + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// Command é um comando de barra digitado na conversa (/model, /level, /save...)
+type Command struct {
+	Name string
+	Arg  string
+}
+
+// ParseCommand interpreta a linha como comando de barra. ok é falso para perguntas comuns.
+func ParseCommand(line string) (cmd Command, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "/") || strings.HasPrefix(line, "//") {
+		return Command{}, false
+	}
+
+	name, arg, _ := strings.Cut(line[1:], " ")
+	return Command{Name: strings.ToLower(name), Arg: strings.TrimSpace(arg)}, true
+}
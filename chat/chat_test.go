@@ -0,0 +1,115 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+func newTestSession(t *testing.T) (*Session, *[][]openai.Message) {
+	t.Helper()
+	config := &openai.Config{APIURL: "http://localhost", Model: "codellama", Timeout: time.Second, Language: "Go"}
+	s := NewSession("defer f.Close()", config)
+
+	var calls [][]openai.Message
+	s.Send = func(ctx context.Context, messages []openai.Message, config *openai.Config) (string, error) {
+		calls = append(calls, messages)
+		if strings.Contains(messages[len(messages)-1].Content, "falhe") {
+			return "", errors.New("falha simulada")
+		}
+		return "resposta " + config.Model, nil
+	}
+	return s, &calls
+}
+
+func TestSessionHistory(t *testing.T) {
+	s, calls := newTestSession(t)
+	ctx := context.Background()
+
+	if _, err := s.Explain(ctx); err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if _, err := s.Ask(ctx, "Por que o defer?"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+
+	if len(*calls) != 2 || len((*calls)[1]) != 4 {
+		t.Fatalf("Expected second call to carry system + 3 messages, got %d calls", len(*calls))
+	}
+	if s.Turns() != 2 || len(s.Messages) != 5 {
+		t.Errorf("Expected 2 turns and 5 messages, got %d and %d", s.Turns(), len(s.Messages))
+	}
+
+	// Uma falha não deve deixar a pergunta sem resposta no histórico
+	if _, err := s.Ask(ctx, "falhe agora"); err == nil {
+		t.Errorf("Expected error from Send")
+	}
+	if len(s.Messages) != 5 {
+		t.Errorf("Failed question should not be kept in history, got %d messages", len(s.Messages))
+	}
+
+	if _, err := s.Ask(ctx, "   "); err == nil {
+		t.Errorf("Expected error for empty question")
+	}
+
+	s.Reset()
+	if len(s.Messages) != 1 || s.Messages[0].Role != openai.RoleSystem {
+		t.Errorf("Reset() should keep only the system message, got %+v", s.Messages)
+	}
+}
+
+func TestSessionSetLevel(t *testing.T) {
+	s, _ := newTestSession(t)
+
+	if err := s.SetLevel(openai.LevelAdvanced); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	if !strings.Contains(s.Messages[0].Content, openai.LevelInstructions(openai.LevelAdvanced)) {
+		t.Errorf("Expected system prompt to include level instructions, got %q", s.Messages[0].Content)
+	}
+	if err := s.SetLevel("expert"); err == nil {
+		t.Errorf("Expected error for invalid level")
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	s, _ := newTestSession(t)
+	ctx := context.Background()
+	s.Explain(ctx)
+	s.Config.Model = "llama3"
+	s.Ask(ctx, "Qual a complexidade?")
+
+	transcript := s.Transcript()
+	for _, expected := range []string{"defer f.Close()", "## Explicação", "resposta codellama", "## Qual a complexidade?", "resposta llama3"} {
+		if !strings.Contains(transcript, expected) {
+			t.Errorf("Expected transcript to contain %q, got:\n%s", expected, transcript)
+		}
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Command
+		ok       bool
+	}{
+		{line: "/model llama3", expected: Command{Name: "model", Arg: "llama3"}, ok: true},
+		{line: "  /SAVE  conversa.md ", expected: Command{Name: "save", Arg: "conversa.md"}, ok: true},
+		{line: "/reset", expected: Command{Name: "reset"}, ok: true},
+		{line: "por que o defer?", ok: false},
+		{line: "// comentário colado", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			cmd, ok := ParseCommand(tt.line)
+			if ok != tt.ok || cmd != tt.expected {
+				t.Errorf("ParseCommand() = %+v, %v, want %+v, %v", cmd, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
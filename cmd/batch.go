@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/batch"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchInput  string
+	batchResume bool
+)
+
+// batchCmd representa o comando batch
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Explica vários trechos de código a partir de um arquivo JSONL",
+	Long: `Processa um arquivo JSONL em que cada linha descreve um trecho de código
+a ser explicado, gravando um resultado JSON por linha.
+
+Campos de cada linha de entrada:
+  id        identificador livre (opcional)
+  code      código a ser explicado (ou use "file")
+  file      caminho do arquivo com o código (ou use "code")
+  language  linguagem forçada (opcional)
+  model     modelo a ser usado (opcional, padrão: --model; MODEL_NAME, se
+            definida, tem precedência e é o modelo registrado no resultado)
+  level     nível do prompt: basico, intermediario, avancado (opcional)
+
+Cada linha de saída contém o número da linha de entrada e a explicação ou um
+erro estruturado ({"type", "message"}). Com --resume, as linhas já presentes
+no arquivo de saída são ignoradas e os novos resultados são acrescentados;
+falhas transitórias (timeout, backend_unavailable, canceled) são refeitas e seus
+registros antigos removidos, para que cada linha tenha um único resultado.
+
+Exemplos:
+  code-explainer batch --input requests.jsonl --output results.jsonl
+  code-explainer batch --input requests.jsonl --output results.jsonl --concurrency 4
+  code-explainer batch --input requests.jsonl --output results.jsonl --resume`,
+	RunE: runBatch,
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	batchCmd.Flags().StringVar(&batchInput, "input", "", "Arquivo JSONL com as requisições")
+	batchCmd.Flags().BoolVar(&batchResume, "resume", false, "Retoma a partir das linhas já concluídas no arquivo de saída")
+	batchCmd.MarkFlagRequired("input")
+}
+
+// batchItem associa uma entrada do arquivo ao código e à configuração que serão usados
+type batchItem struct {
+	entry  batch.Entry
+	code   string
+	config *openai.Config
+	err    *batch.Error
+	usage  *openai.Usage // Preenchido pelo OnUsage da configuração do item
+}
+
+func runBatch(cmd *cobra.Command, args []string) error {
+	if batchResume && output == "" {
+		return usageError(fmt.Errorf("--resume requer --output"))
+	}
+
+	in, err := os.Open(batchInput)
+	if err != nil {
+		return inputError(fmt.Errorf("erro ao abrir arquivo de entrada %s: %w", batchInput, err))
+	}
+	entries, err := batch.ReadRequests(in)
+	in.Close()
+	if err != nil {
+		return inputError(err)
+	}
+
+	completed := map[int]bool{}
+	if batchResume {
+		completed, err = batch.Compact(output)
+		if err != nil {
+			return fmt.Errorf("erro ao ler resultados anteriores: %w", err)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if output != "" {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if batchResume {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		file, err := os.OpenFile(output, flags, 0644)
+		if err != nil {
+			return fmt.Errorf("erro ao abrir arquivo de saída: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	items := prepareBatchItems(entries, completed)
+	skipped := len(entries) - len(items)
+
+	var jobs []openai.Job
+	var jobItems []int // índice em items de cada job
+	for i, item := range items {
+		if item.err == nil {
+			item.config.OnUsage = openai.CaptureUsage(item.config.OnUsage, &items[i].usage)
+			jobs = append(jobs, openai.Job{ID: fmt.Sprint(item.entry.Line), Code: item.code, Config: item.config})
+			jobItems = append(jobItems, i)
+		}
+	}
+
+	slog.Debug("lote carregado",
+		"input", batchInput,
+		"requests", len(entries),
+		"skipped", skipped,
+		"concurrency", concurrency,
+	)
+
+	writer := batch.NewWriter(out)
+	failures := 0
+	var writeErr error
+	written := 0
+
+	// write grava o item informado, mantendo a ordem das linhas de entrada
+	write := func(item batchItem, explanation string, duration time.Duration) {
+		result := batch.Result{
+			Line:        item.entry.Line,
+			ID:          item.entry.Request.ID,
+			File:        item.entry.Request.File,
+			Explanation: explanation,
+			Error:       item.err,
+			DurationMs:  duration.Milliseconds(),
+			Usage:       item.usage,
+		}
+		if item.config != nil {
+			result.Language = item.config.Language
+			result.Model = item.config.Model
+			result.Level = item.config.Level
+		}
+		if result.Error != nil {
+			failures++
+		}
+		if err := writer.Write(result); err != nil && writeErr == nil {
+			writeErr = err
+		}
+	}
+
+	// flushUntil grava os itens inválidos que antecedem o índice informado
+	flushUntil := func(limit int) {
+		for ; written < limit; written++ {
+			write(items[written], "", 0)
+		}
+	}
+
+	openai.ExplainEach(cmd.Context(), jobs, concurrency, explainCode, func(r openai.Result) {
+		index := jobItems[r.Index]
+		flushUntil(index)
+
+		item := items[index]
+		if r.Err != nil {
+			item.err = batch.NewError("", r.Err)
+		}
+		write(item, r.Explanation, r.Duration)
+		written = index + 1
+
+		slog.Debug("linha processada", "line", item.entry.Line, "duration_ms", r.Duration.Milliseconds(), "error", r.Err)
+	})
+	flushUntil(len(items))
+
+	if writeErr != nil {
+		return fmt.Errorf("erro ao escrever resultados: %w", writeErr)
+	}
+
+	fmt.Fprintf(os.Stderr, "📊 Lote concluído: %d processada(s), %d com erro, %d ignorada(s)\n", len(items), failures, skipped)
+	if failures > 0 {
+		return fmt.Errorf("%d de %d requisição(ões) falharam", failures, len(items))
+	}
+
+	return nil
+}
+
+// prepareBatchItems resolve código e configuração de cada entrada ainda não concluída
+func prepareBatchItems(entries []batch.Entry, completed map[int]bool) []batchItem {
+	var items []batchItem
+
+	for _, entry := range entries {
+		if completed[entry.Line] {
+			continue
+		}
+
+		item := batchItem{entry: entry}
+		if entry.Err != nil {
+			item.err = batch.NewError(batch.ErrorInvalidRequest, entry.Err)
+			items = append(items, item)
+			continue
+		}
+
+		req := entry.Request
+		item.code = req.Code
+		if req.File != "" {
+			code, err := readFile(req.File)
+			if err != nil {
+				item.err = batch.NewError(batch.ErrorInput, fmt.Errorf("erro ao ler arquivo %s: %w", req.File, err))
+				items = append(items, item)
+				continue
+			}
+			item.code = code
+		}
+
+		config := newConfig()
+		if req.Model != "" {
+			config.Model = req.Model
+		}
+		// O resultado registra o modelo que de fato responde; MODEL_NAME tem precedência
+		if model := openai.EffectiveModel(config); model != config.Model {
+			if req.Model != "" {
+				slog.Warn("MODEL_NAME substitui o modelo pedido na linha", "line", entry.Line, "requested", req.Model, "model", model)
+			}
+			config.Model = model
+		}
+		if req.Level != "" {
+			config.Level = req.Level
+		}
+		if req.Language != "" {
+			config.Language = req.Language
+		}
+		if config.Language == "" {
+			config.Language = openai.DetectLanguage(item.code)
+		}
+		item.config = config
+
+		items = append(items, item)
+	}
+
+	return items
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mvcbotelho/code-explainer/batch"
+)
+
+func TestRunBatchResume(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"response": "explicação", "done": true})
+	}))
+	defer ollama.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "requests.jsonl")
+	os.WriteFile(input, []byte(`{"id": "a", "code": "print(1)"}
+{"id": "b", "code": "print(2)"}
+{"id": "c", "code": "print(3)"}
+`), 0o644)
+	results := filepath.Join(dir, "results.jsonl")
+	os.WriteFile(results, []byte(`{"line":1,"id":"a","explanation":"antiga","duration_ms":1}
+{"line":2,"id":"b","error":{"type":"timeout","message":"deadline"},"duration_ms":1}
+{"line":3,"id":"c","explan`), 0o644)
+
+	restore := saveGlobals()
+	defer restore()
+	oldInput, oldResume, oldOutput, oldCache, oldHistory := batchInput, batchResume, output, noCache, noHistory
+	defer func() {
+		batchInput, batchResume, output, noCache, noHistory = oldInput, oldResume, oldOutput, oldCache, oldHistory
+	}()
+	t.Setenv("MODEL_NAME", "")
+
+	apiURL, modelName, language = ollama.URL+"/api/generate", "codellama", ""
+	batchInput, batchResume, output, noCache, noHistory = input, true, results, true, true
+	batchCmd.SetContext(context.Background())
+
+	if err := runBatch(batchCmd, nil); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	data, err := os.ReadFile(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[int]batch.Result{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var result batch.Result
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatalf("invalid result %q: %v", line, err)
+		}
+		if _, dup := seen[result.Line]; dup {
+			t.Errorf("line %d has more than one result:\n%s", result.Line, data)
+		}
+		seen[result.Line] = result
+	}
+
+	if len(seen) != 3 {
+		t.Fatalf("Expected results for 3 lines, got %d:\n%s", len(seen), data)
+	}
+	if seen[1].Explanation != "antiga" {
+		t.Errorf("Completed line 1 should be kept, got %+v", seen[1])
+	}
+	for _, line := range []int{2, 3} {
+		if seen[line].Error != nil || seen[line].Explanation != "explicação" {
+			t.Errorf("Line %d should be redone, got %+v", line, seen[line])
+		}
+	}
+}
+
+func TestPrepareBatchItemsModel(t *testing.T) {
+	restore := saveGlobals()
+	defer restore()
+	modelName = "codellama"
+
+	entries := []batch.Entry{
+		{Line: 1, Request: batch.Request{Code: "x"}},
+		{Line: 2, Request: batch.Request{Code: "x", Model: "mistral"}},
+	}
+
+	tests := []struct {
+		name     string
+		env      string
+		expected []string
+	}{
+		{name: "Sem MODEL_NAME", expected: []string{"codellama", "mistral"}},
+		{name: "MODEL_NAME tem precedência", env: "llama3", expected: []string{"llama3", "llama3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MODEL_NAME", tt.env)
+			items := prepareBatchItems(entries, map[int]bool{})
+			for i, item := range items {
+				if item.config.Model != tt.expected[i] {
+					t.Errorf("line %d: model = %q, want %q", item.entry.Line, item.config.Model, tt.expected[i])
+				}
+			}
+		})
+	}
+}
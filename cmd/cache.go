@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	noCache      bool
+	cacheTTL     time.Duration
+	cacheMaxSize int64
+)
+
+// cacheCmd representa o comando cache
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Gerencia o cache de explicações",
+	Long: `Gerencia o cache em disco das explicações geradas.
+
+As explicações são armazenadas em $XDG_CACHE_HOME/code-explainer, indexadas
+por um hash do código, linguagem, modelo, endpoint, nível e prompt. Executar
+explain novamente sobre um código inalterado reutiliza a explicação salva.
+
+Subcomandos:
+  stats  - Mostra estatísticas do cache
+  clear  - Remove todas as entradas
+  prune  - Remove entradas expiradas e excedentes ao tamanho máximo
+
+Exemplos:
+  code-explainer cache stats
+  code-explainer cache prune --cache-ttl 24h
+  code-explainer explain --file main.go --no-cache`,
+}
+
+// cacheStatsCmd mostra as estatísticas do cache
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Mostra estatísticas do cache",
+	RunE:  runCacheStats,
+}
+
+// cacheClearCmd remove todas as entradas do cache
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove todas as entradas do cache",
+	RunE:  runCacheClear,
+}
+
+// cachePruneCmd remove entradas expiradas e aplica o limite de tamanho
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove entradas expiradas e excedentes ao tamanho máximo",
+	RunE:  runCachePrune,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+
+	// Flags globais de cache, usadas por explain, batch e pelos subcomandos acima
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Não usar o cache de explicações")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", getEnvDurationOrDefault("CACHE_TTL", 7*24*time.Hour), "Tempo de vida das entradas do cache (0 = sem expiração)")
+	rootCmd.PersistentFlags().Int64Var(&cacheMaxSize, "cache-max-size", int64(getEnvIntOrDefault("CACHE_MAX_SIZE_MB", 100)), "Tamanho máximo do cache em MB (0 = sem limite)")
+}
+
+// openCache abre o cache configurado pelas flags, ou retorna nil se estiver desativado
+func openCache() (*cache.Cache, error) {
+	if noCache {
+		return nil, nil
+	}
+	return newCache()
+}
+
+// newCache cria o cache no diretório padrão com os limites definidos pelas flags
+func newCache() (*cache.Cache, error) {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(dir, cacheTTL, cacheMaxSize*1024*1024), nil
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	c, err := newCache()
+	if err != nil {
+		return err
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		return fmt.Errorf("erro ao ler cache: %w", err)
+	}
+
+	fmt.Println("🗄️  Cache de Explicações")
+	fmt.Println(strings.Repeat("=", 30))
+	fmt.Println()
+	fmt.Printf("📁 **Diretório:** %s\n", stats.Dir)
+	fmt.Printf("📦 **Entradas:** %d (%d expiradas)\n", stats.Entries, stats.Expired)
+	fmt.Printf("💾 **Tamanho:** %s de %s\n", formatBytes(stats.Bytes), formatLimit(c.MaxBytes))
+	fmt.Printf("⏳ **TTL:** %s\n", formatTTL(c.TTL))
+	if stats.Entries > 0 {
+		fmt.Printf("🕰️  **Mais antiga:** %s\n", stats.Oldest.Format(time.DateTime))
+		fmt.Printf("🆕 **Mais recente:** %s\n", stats.Newest.Format(time.DateTime))
+	}
+
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	c, err := newCache()
+	if err != nil {
+		return err
+	}
+
+	removed, err := c.Clear()
+	if err != nil {
+		return fmt.Errorf("erro ao limpar cache: %w", err)
+	}
+
+	fmt.Printf("🧹 %d entrada(s) removida(s) de %s\n", removed, c.Dir)
+	return nil
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	c, err := newCache()
+	if err != nil {
+		return err
+	}
+
+	removed, freed, err := c.Prune()
+	if err != nil {
+		return fmt.Errorf("erro ao podar cache: %w", err)
+	}
+
+	fmt.Printf("✂️  %d entrada(s) removida(s), %s liberados\n", removed, formatBytes(freed))
+	return nil
+}
+
+// formatBytes formata um tamanho em bytes de forma legível
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatLimit formata o tamanho máximo do cache
+func formatLimit(n int64) string {
+	if n <= 0 {
+		return "sem limite"
+	}
+	return formatBytes(n)
+}
+
+// formatTTL formata o tempo de vida das entradas
+func formatTTL(ttl time.Duration) string {
+	if ttl <= 0 {
+		return "sem expiração"
+	}
+	return ttl.String()
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/chat"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chatFile string
+	chatCode string
+)
+
+// chatCmd representa o comando chat
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Conversa sobre um trecho de código com perguntas de acompanhamento",
+	Long: `Inicia uma conversa interativa sobre um trecho de código.
+
+O código é enviado uma vez e explicado; em seguida você pode fazer perguntas
+de acompanhamento ("por que o defer?", "qual a complexidade?"). O histórico da
+conversa é mantido e enviado ao modelo via /api/chat do Ollama. O código e
+cada pergunta passam pela ocultação de dados sensíveis antes do envio.
+
+Ao colar o código, termine com uma linha contendo apenas "." (ponto).
+
+Comandos disponíveis durante a conversa:
+  /model [nome]     Mostra ou altera o modelo (MODEL_NAME tem precedência)
+  /level [nível]    Mostra ou altera o nível de detalhamento
+  /save [arquivo]   Salva a conversa em Markdown
+  /reset            Descarta as perguntas e mantém o código
+  /help             Mostra esta ajuda
+  /sair             Encerra a conversa (também /exit, /quit ou Ctrl+D)
+
+Exemplos:
+  code-explainer chat
+  code-explainer chat --file main.go
+  code-explainer chat --code "defer f.Close()" --level basico`,
+	RunE: runChat,
+}
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+
+	chatCmd.Flags().StringVarP(&chatFile, "file", "f", "", "Arquivo com o código a ser discutido")
+	chatCmd.Flags().StringVarP(&chatCode, "code", "c", "", "Código a ser discutido")
+	chatCmd.MarkFlagsMutuallyExclusive("file", "code")
+}
+
+func runChat(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	code := chatCode
+	switch {
+	case chatFile != "":
+		content, err := readFile(chatFile)
+		if err != nil {
+			return fmt.Errorf("erro ao ler arquivo %s: %w", chatFile, err)
+		}
+		code = content
+	case code == "":
+		fmt.Println("Cole o trecho de código abaixo e termine com uma linha contendo apenas \".\":")
+		content, err := readUntilDot(scanner)
+		if err != nil {
+			return err
+		}
+		code = content
+	}
+
+	if strings.TrimSpace(code) == "" {
+		return inputError(fmt.Errorf("nenhum código fornecido para análise"))
+	}
+
+	redacted := redactCode(cmd.Context(), code)
+	session := chat.NewSession(redacted.Text, newConfig())
+
+	// Cada resposta é registrada no histórico: a explicação com o código e as demais com a pergunta
+	var usage *openai.Usage
+	session.Config.OnUsage = openai.CaptureUsage(session.Config.OnUsage, &usage)
+	record := func(text, answer string, duration time.Duration) {
+		recordHistory(cmd.Context(), "chat", text, session.Language, openai.EffectiveModel(&session.Config), session.Config.Level, answer, duration, false, usage)
+		usage = nil
+	}
+
+	slog.Debug("iniciando conversa", "language", session.Language, "model", session.Config.Model)
+
+	fmt.Println("🔄 Enviando para análise...")
+	start := time.Now()
+	explanation, err := session.Explain(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("erro ao explicar código: %w", err)
+	}
+	record(redacted.Text, explanation, time.Since(start))
+	explanation = restoreOutput(redacted, explanation)
+	fmt.Printf("\n📘 %s\n\n", explanation)
+	fmt.Println("💬 Faça perguntas sobre o código (/help para ver os comandos).")
+
+	for {
+		fmt.Print("❓ ")
+		if !scanner.Scan() {
+			fmt.Println()
+			break
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if command, ok := chat.ParseCommand(line); ok {
+			if done := runChatCommand(session, command); done {
+				break
+			}
+			continue
+		}
+
+		// Cada pergunta também passa pelo redator; o resultado acumula os placeholders da conversa
+		redacted = redactTurn(cmd.Context(), redacted, line)
+		start := time.Now()
+		answer, err := session.Ask(cmd.Context(), redacted.Text)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Erro: %v\n", err)
+			continue
+		}
+		record(redacted.Text, answer, time.Since(start))
+		answer = restoreOutput(redacted, answer)
+		fmt.Printf("\n💡 %s\n\n", answer)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	fmt.Println("👋 Até mais!")
+	return nil
+}
+
+// runChatCommand executa um comando de barra; retorna true para encerrar a conversa
+func runChatCommand(session *chat.Session, command chat.Command) bool {
+	switch command.Name {
+	case "sair", "exit", "quit":
+		return true
+
+	case "help", "ajuda":
+		fmt.Println("📋 Comandos: /model [nome], /level [nível], /save [arquivo], /reset, /help, /sair")
+
+	case "model":
+		if command.Arg == "" {
+			fmt.Printf("🤖 Modelo atual: %s\n", openai.EffectiveModel(&session.Config))
+			break
+		}
+		session.Config.Model = command.Arg
+		fmt.Printf("🤖 Modelo alterado para: %s\n", command.Arg)
+		if env := openai.ModelOverride(); env != "" && env != command.Arg {
+			fmt.Fprintf(os.Stderr, "⚠️  A variável MODEL_NAME=%s tem precedência e continuará sendo usada; remova-a para trocar de modelo na conversa.\n", env)
+		}
+
+	case "level":
+		if command.Arg == "" {
+			current := session.Config.Level
+			if current == "" {
+				current = openai.LevelIntermediate
+			}
+			fmt.Printf("📊 Nível atual: %s (disponíveis: %s)\n", current, strings.Join(openai.GetPromptLevels(), ", "))
+			break
+		}
+		if err := session.SetLevel(command.Arg); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Erro: %v\n", err)
+			break
+		}
+		fmt.Printf("📊 Nível alterado para: %s\n", command.Arg)
+
+	case "save":
+		path := command.Arg
+		if path == "" {
+			path = fmt.Sprintf("conversa-%s.md", time.Now().Format("20060102-150405"))
+		}
+		if err := writeToFile(path, session.Transcript()); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Erro ao salvar conversa: %v\n", err)
+			break
+		}
+		fmt.Printf("💾 Conversa salva em: %s\n", path)
+
+	case "reset":
+		session.Reset()
+		fmt.Println("🧹 Histórico descartado; o código continua em discussão.")
+
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Comando desconhecido: /%s (use /help)\n", command.Name)
+	}
+
+	return false
+}
+
+// readUntilDot lê linhas até encontrar uma linha contendo apenas "." ou o fim da entrada
+func readUntilDot(scanner *bufio.Scanner) (string, error) {
+	var lines []string
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "." {
+			break
+		}
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return "", inputError(err)
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"strings"
 
 	"github.com/mvcbotelho/code-explainer/openai"
@@ -55,34 +56,28 @@ func runDetect(cmd *cobra.Command, args []string) error {
 	switch {
 	case detectCodeInput != "":
 		code = detectCodeInput
-		if verbose {
-			fmt.Printf("📝 Usando código fornecido via flag\n")
-		}
+		slog.Debug("usando código fornecido via flag")
 
 	case detectFilePath != "":
 		code, err = readFile(detectFilePath)
 		if err != nil {
 			return fmt.Errorf("erro ao ler arquivo %s: %w", detectFilePath, err)
 		}
-		if verbose {
-			fmt.Printf("📁 Lendo código do arquivo: %s\n", detectFilePath)
-		}
+		slog.Debug("lendo código do arquivo", "file", detectFilePath)
 
 	case detectInteractive || (detectCodeInput == "" && detectFilePath == ""):
 		code, err = readInteractive()
 		if err != nil {
 			return fmt.Errorf("erro ao ler entrada interativa: %w", err)
 		}
-		if verbose {
-			fmt.Printf("⌨️  Usando entrada interativa\n")
-		}
+		slog.Debug("usando entrada interativa")
 
 	default:
-		return fmt.Errorf("forneça o código via --code, --file ou use modo interativo")
+		return inputError(fmt.Errorf("forneça o código via --code, --file ou use modo interativo"))
 	}
 
 	if code == "" {
-		return fmt.Errorf("código vazio fornecido")
+		return inputError(fmt.Errorf("código vazio fornecido"))
 	}
 
 	// Detectar linguagem
@@ -97,13 +92,14 @@ func runDetect(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("erro ao escrever arquivo de saída: %w", err)
 		}
-		if verbose {
-			fmt.Printf("💾 Resultado salvo em: %s\n", output)
-		}
+		slog.Debug("resultado salvo", "output", output)
 	} else {
 		fmt.Println(outputText)
 	}
 
+	if detectedLang == openai.UnknownLanguage {
+		return &exitError{code: exitDetection, err: fmt.Errorf("não foi possível detectar a linguagem do código")}
+	}
 	return nil
 }
 
@@ -120,7 +116,7 @@ func formatDetectOutput(code, language string) string {
 	output.WriteString("\n```\n\n")
 
 	output.WriteString("🎯 **Linguagem detectada:** ")
-	if language == "linguagem desconhecida" {
+	if language == openai.UnknownLanguage {
 		output.WriteString("❓ " + language)
 	} else {
 		output.WriteString("✅ " + language)
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mvcbotelho/code-explainer/gitdiff"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffPatchPath string
+	diffStaged    bool
+)
+
+// explainDiffCmd explica um diff
+var explainDiffCmd = &cobra.Command{
+	Use:   "diff [argumentos do git diff...]",
+	Short: "Explica as alterações de um diff",
+	Long: `Explica as alterações de um diff unificado, agrupadas por arquivo.
+
+O diff pode vir de três fontes:
+1. git diff no diretório atual (padrão); argumentos extras são repassados ao git
+2. Arquivo de patch: --patch alteracoes.patch
+3. Entrada padrão: --patch -
+
+A linguagem de cada trecho é detectada pelo nome do arquivo. Cada arquivo é
+enviado em uma requisição separada (use --concurrency para paralelizar).
+
+Exemplos:
+  code-explainer explain diff
+  code-explainer explain diff --staged
+  code-explainer explain diff main...feature
+  code-explainer explain diff --patch fix.patch
+  git diff HEAD~3 | code-explainer explain diff --patch -`,
+	RunE: runExplainDiff,
+}
+
+// explainCommitCmd explica um commit
+var explainCommitCmd = &cobra.Command{
+	Use:   "commit <rev>",
+	Short: "Explica as alterações de um commit",
+	Long: `Explica as alterações de um commit do repositório git atual, agrupadas
+por arquivo, usando a mensagem do commit como contexto.
+
+Exemplos:
+  code-explainer explain commit HEAD
+  code-explainer explain commit a1b2c3d --level avancado`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplainCommit,
+}
+
+func init() {
+	explainCmd.AddCommand(explainDiffCmd)
+	explainCmd.AddCommand(explainCommitCmd)
+
+	explainDiffCmd.Flags().StringVarP(&diffPatchPath, "patch", "p", "", "Arquivo de patch a ser explicado (\"-\" para entrada padrão)")
+	explainDiffCmd.Flags().BoolVar(&diffStaged, "staged", false, "Explica as alterações preparadas para commit (git diff --staged)")
+	explainDiffCmd.MarkFlagsMutuallyExclusive("patch", "staged")
+}
+
+func runExplainDiff(cmd *cobra.Command, args []string) error {
+	var files []gitdiff.File
+	var err error
+
+	switch {
+	case diffPatchPath == "-":
+		files, err = gitdiff.Parse(os.Stdin)
+		slog.Debug("lendo diff da entrada padrão")
+
+	case diffPatchPath != "":
+		var file *os.File
+		file, err = os.Open(diffPatchPath)
+		if err != nil {
+			return inputError(fmt.Errorf("erro ao ler patch %s: %w", diffPatchPath, err))
+		}
+		defer file.Close()
+		files, err = gitdiff.Parse(file)
+		slog.Debug("lendo patch do arquivo", "file", diffPatchPath)
+
+	default:
+		if diffStaged {
+			args = append([]string{"--staged"}, args...)
+		}
+		files, err = gitdiff.Diff(cmd.Context(), "", args...)
+		slog.Debug("executando git diff", "args", strings.Join(args, " "))
+	}
+
+	if err != nil {
+		return fmt.Errorf("erro ao interpretar diff: %w", err)
+	}
+
+	return explainFiles(cmd, "diff", files, "", "")
+}
+
+func runExplainCommit(cmd *cobra.Command, args []string) error {
+	commit, err := gitdiff.Show(cmd.Context(), "", args[0])
+	if err != nil {
+		return fmt.Errorf("erro ao ler commit %s: %w", args[0], err)
+	}
+
+	var header strings.Builder
+	header.WriteString(fmt.Sprintf("🔖 **Commit:** %s\n", commit.Hash))
+	header.WriteString(fmt.Sprintf("👤 **Autor:** %s\n", commit.Author))
+	header.WriteString(fmt.Sprintf("📝 **Mensagem:** %s\n\n", commit.Subject))
+
+	return explainFiles(cmd, "commit", commit.Files, commit.Message(), header.String())
+}
+
+// explainFiles pede ao modelo uma explicação por arquivo alterado e escreve o resultado.
+// command identifica as respostas no histórico (diff ou commit).
+func explainFiles(cmd *cobra.Command, command string, files []gitdiff.File, message, header string) error {
+	// Arquivos binários ou sem trechos não têm o que explicar
+	var changed []gitdiff.File
+	for _, f := range files {
+		if !f.Binary && len(f.Hunks) > 0 {
+			changed = append(changed, f)
+		}
+	}
+	if len(changed) == 0 {
+		return fmt.Errorf("nenhuma alteração de texto encontrada no diff")
+	}
+
+	config := newConfig()
+	jobs := make([]openai.Job, len(changed))
+	sources := make(map[string]promptSource, len(changed))
+	for i, f := range changed {
+		prompt := gitdiff.BuildPrompt(f, message, config.Level)
+		jobs[i] = openai.Job{ID: f.Path(), Code: prompt, Config: config}
+		sources[prompt] = promptSource{command: command, code: f.Text(), language: f.Language()}
+	}
+
+	slog.Debug("enviando alterações para análise", "files", len(changed), "model", config.Model)
+
+	results := openai.ExplainAll(cmd.Context(), jobs, concurrency, promptFunc(command, sources))
+
+	var out strings.Builder
+	out.WriteString("📘 Explicação das alterações:\n")
+	out.WriteString(strings.Repeat("=", 50) + "\n\n")
+	out.WriteString(header)
+
+	for i, r := range results {
+		f := changed[i]
+		added, removed := f.Stats()
+		out.WriteString(fmt.Sprintf("📄 **Arquivo:** %s (%s) +%d -%d\n\n", f.Path(), f.Language(), added, removed))
+		if r.Err != nil {
+			out.WriteString(fmt.Sprintf("❌ Erro ao explicar alterações: %v\n\n", r.Err))
+			continue
+		}
+		out.WriteString(r.Explanation)
+		out.WriteString("\n\n")
+	}
+
+	if err := writeOutput(out.String()); err != nil {
+		return err
+	}
+
+	if failures := openai.CountFailures(results); failures > 0 {
+		return fmt.Errorf("%d de %d arquivo(s) não puderam ser explicados", failures, len(results))
+	}
+
+	return nil
+}
+
+// writeOutput escreve o texto no arquivo de --output ou na saída padrão
+func writeOutput(text string) error {
+	if output == "" {
+		_, err := io.WriteString(os.Stdout, text)
+		return err
+	}
+
+	if err := writeToFile(output, text); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo de saída: %w", err)
+	}
+	slog.Debug("explicação salva", "output", output)
+	return nil
+}
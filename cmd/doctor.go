@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mvcbotelho/code-explainer/cache"
+	"github.com/mvcbotelho/code-explainer/doctor"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorSkipGenerate bool
+	// doctorSetup guarda os erros de configuração, reportados como verificações
+	doctorSetup setupErrors
+)
+
+// doctorCmd representa o comando doctor
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnostica o ambiente (API, modelo, configuração e permissões)",
+	Long: `Executa verificações do ambiente e informa, para cada uma, se passou (✅),
+gerou um aviso (⚠️) ou falhou (❌), com uma dica de como corrigir.
+
+Verificações:
+• Arquivo de configuração válido
+• Variáveis de ambiente que se sobrepõem às flags (ex.: MODEL_NAME e --model)
+• Conexão com a URL da API
+• Tipo de endpoint (generate, chat ou compatível com OpenAI)
+• Modelo configurado instalado no servidor
+• Latência de ida e volta com um prompt mínimo
+• Permissões dos diretórios de cache e histórico
+
+O comando termina com código 1 se alguma verificação falhar.
+
+Exemplos:
+  code-explainer doctor
+  code-explainer doctor --model codellama:13b
+  code-explainer doctor --skip-generate`,
+	Args: cobra.NoArgs,
+	// O arquivo de configuração inválido é reportado como verificação, em vez de abortar
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupCommand(cmd, &doctorSetup); err != nil {
+			return err
+		}
+		commandStarted = true
+		return nil
+	},
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorSkipGenerate, "skip-generate", false, "Não envia o prompt de teste ao modelo")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	ctx := cmd.Context()
+	config := newConfig()
+	model := openai.EffectiveModel(config)
+
+	fmt.Println("🩺 Diagnóstico do Code Explainer")
+	fmt.Printf("   API: %s · Modelo: %s\n\n", config.APIURL, model)
+
+	var results []doctor.Result
+	report := func(r doctor.Result) {
+		results = append(results, r)
+		printCheck(r)
+	}
+
+	configFile := ""
+	if loadedConfig != nil {
+		configFile = loadedConfig.Path
+	}
+	report(doctor.CheckConfigFile(configFile, doctorSetup.config))
+	if doctorSetup.config == nil && doctorSetup.level != nil {
+		report(doctor.Result{Name: "Nível de detalhamento", Status: doctor.Fail, Message: doctorSetup.level.Error(), Hint: "Use --level basico, intermediario ou avancado"})
+	}
+
+	report(doctor.CheckEnv([]doctor.EnvConflict{
+		{Env: "MODEL_NAME", Flag: "model", Value: modelName},
+	}, os.Getenv))
+
+	// Com o destino bloqueado, ou a política inválida, nenhuma requisição é enviada à API
+	var allowed doctor.Result
+	if doctorSetup.policy != nil {
+		allowed = doctor.Result{
+			Name:    "Política de destinos",
+			Status:  doctor.Fail,
+			Message: doctorSetup.policy.Error(),
+			Hint:    "Corrija o arquivo de configuração do repositório (.code-explainer.yaml)",
+		}
+	} else {
+		allowed = doctor.CheckPolicy(currentPolicy(), config.APIURL)
+	}
+	report(allowed)
+
+	reachable := doctor.Result{Name: "Conexão com a API", Status: doctor.Skip, Message: "destino bloqueado pela política"}
+	if doctorSetup.policy != nil {
+		reachable.Message = "política inválida"
+	}
+	if allowed.Status != doctor.Fail {
+		reachable = doctor.CheckReachable(ctx, config)
+	}
+	report(reachable)
+	report(doctor.CheckEndpoint(config.APIURL))
+
+	if reachable.Status != doctor.Pass {
+		reason := "API inacessível"
+		if reachable.Status == doctor.Skip {
+			reason = reachable.Message
+		}
+		report(doctor.Result{Name: "Modelo", Status: doctor.Skip, Message: reason})
+		report(doctor.Result{Name: "Latência", Status: doctor.Skip, Message: reason})
+	} else {
+		modelCheck := doctor.CheckModel(ctx, config, model)
+		report(modelCheck)
+
+		switch {
+		case doctorSkipGenerate:
+			report(doctor.Result{Name: "Latência", Status: doctor.Skip, Message: "--skip-generate"})
+		case modelCheck.Status == doctor.Fail:
+			report(doctor.Result{Name: "Latência", Status: doctor.Skip, Message: "modelo não instalado"})
+		default:
+			report(doctor.CheckLatency(ctx, config))
+		}
+	}
+
+	if noCache {
+		report(doctor.Result{Name: "Diretório de cache", Status: doctor.Skip, Message: "--no-cache"})
+	} else if dir, err := cache.DefaultDir(); err != nil {
+		report(doctor.Result{Name: "Diretório de cache", Status: doctor.Fail, Message: err.Error(), Hint: "Defina XDG_CACHE_HOME ou use --no-cache"})
+	} else {
+		report(doctor.CheckDir("Diretório de cache", dir))
+	}
+
+	if noHistory {
+		report(doctor.Result{Name: "Diretório do histórico", Status: doctor.Skip, Message: "--no-history"})
+	} else if store, err := newHistory(); err != nil {
+		report(doctor.Result{Name: "Diretório do histórico", Status: doctor.Fail, Message: err.Error(), Hint: "Defina HISTORY_FILE ou use --no-history"})
+	} else {
+		report(doctor.CheckDir("Diretório do histórico", filepath.Dir(store.Path)))
+	}
+
+	counts := map[doctor.Status]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	fmt.Printf("\n📊 %d ok, %d avisos, %d falhas\n", counts[doctor.Pass], counts[doctor.Warn], counts[doctor.Fail])
+
+	if counts[doctor.Fail] > 0 {
+		return &exitError{code: 1, err: fmt.Errorf("%d verificações falharam", counts[doctor.Fail])}
+	}
+	return nil
+}
+
+// printCheck mostra o resultado de uma verificação com a dica de correção, se houver
+func printCheck(r doctor.Result) {
+	icons := map[doctor.Status]string{
+		doctor.Pass: "✅",
+		doctor.Warn: "⚠️ ",
+		doctor.Fail: "❌",
+		doctor.Skip: "⏭️ ",
+	}
+
+	fmt.Printf("%s %s: %s\n", icons[r.Status], r.Name, r.Message)
+	if r.Hint != "" && r.Status != doctor.Pass {
+		fmt.Printf("   💡 %s\n", r.Hint)
+	}
+}
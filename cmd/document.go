@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mvcbotelho/code-explainer/docgen"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/spf13/cobra"
+)
+
+var (
+	documentFile   string
+	documentDryRun bool
+	documentWrite  bool
+)
+
+// documentCmd representa o comando document
+var documentCmd = &cobra.Command{
+	Use:   "document",
+	Short: "Gera comentários de documentação para identificadores públicos",
+	Long: `Gera comentários de documentação para identificadores públicos que ainda
+não têm documentação, usando IA local.
+
+Para Go, os alvos são encontrados com go/ast (funções, métodos, tipos,
+constantes e variáveis exportadas); o arquivo só é reformatado com go/printer
+se já seguia o gofmt, para que o diff se limite aos comentários inseridos.
+Para as demais linguagens, os comentários são inseridos acima das definições
+de funções e classes detectadas, no estilo de cada linguagem.
+
+Por padrão (--dry-run) apenas exibe o diff unificado das alterações.
+Use --write para aplicar as alterações no próprio arquivo.
+
+Exemplos:
+  code-explainer document --file main.go
+  code-explainer document --file utils.py --write
+  code-explainer document --file lib.rs --dry-run --output docs.patch`,
+	RunE: runDocument,
+}
+
+func init() {
+	rootCmd.AddCommand(documentCmd)
+
+	documentCmd.Flags().StringVarP(&documentFile, "file", "f", "", "Arquivo a ser documentado")
+	documentCmd.Flags().BoolVar(&documentDryRun, "dry-run", false, "Exibe o diff sem alterar o arquivo (padrão)")
+	documentCmd.Flags().BoolVar(&documentWrite, "write", false, "Aplica as alterações no arquivo")
+
+	documentCmd.MarkFlagRequired("file")
+	documentCmd.MarkFlagsMutuallyExclusive("dry-run", "write")
+}
+
+func runDocument(cmd *cobra.Command, args []string) error {
+	src, err := readFile(documentFile)
+	if err != nil {
+		return fmt.Errorf("erro ao ler arquivo %s: %w", documentFile, err)
+	}
+
+	lang := language
+	if lang == "" {
+		lang = openai.LanguageFromFilename(documentFile)
+	}
+	if lang == "" {
+		lang = openai.DetectLanguage(src)
+	}
+
+	targets, err := docgen.FindTargets(src, documentFile, lang)
+	if err != nil {
+		return fmt.Errorf("erro ao analisar %s: %w", documentFile, err)
+	}
+	if len(targets) == 0 {
+		fmt.Println("✅ Nenhum identificador público sem documentação encontrado.")
+		return nil
+	}
+
+	config := newConfig()
+	jobs := make([]openai.Job, len(targets))
+	sources := make(map[string]promptSource, len(targets))
+	for i, t := range targets {
+		prompt := docgen.BuildPrompt(t, lang)
+		jobs[i] = openai.Job{ID: t.Name, Code: prompt, Config: config}
+		sources[prompt] = promptSource{command: "document", code: t.Source, language: lang}
+	}
+
+	slog.Debug("gerando documentação",
+		"language", lang,
+		"targets", targetNames(targets),
+		"model", config.Model,
+	)
+
+	results := openai.ExplainAll(cmd.Context(), jobs, concurrency, promptFunc("document", sources))
+
+	var comments []docgen.Comment
+	for i, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %s: %v\n", r.ID, r.Err)
+			continue
+		}
+		text := docgen.CleanComment(r.Explanation, targets[i], lang)
+		if text == "" {
+			fmt.Fprintf(os.Stderr, "⚠️  %s: o modelo retornou um comentário vazio\n", r.ID)
+			continue
+		}
+		comments = append(comments, docgen.Comment{Target: targets[i], Text: text})
+	}
+	if len(comments) == 0 {
+		return fmt.Errorf("nenhum comentário pôde ser gerado")
+	}
+
+	updated, err := docgen.Apply(src, lang, comments)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir comentários: %w", err)
+	}
+
+	// --dry-run é o padrão; só --write altera o arquivo
+	dryRun := documentDryRun || !documentWrite
+	if dryRun {
+		name := strings.TrimPrefix(filepath.ToSlash(documentFile), "/")
+		diff := docgen.UnifiedDiff("a/"+name, "b/"+name, src, updated)
+		if err := writeOutput(diff); err != nil {
+			return err
+		}
+	} else {
+		info, err := os.Stat(documentFile)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(documentFile, []byte(updated), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("erro ao escrever %s: %w", documentFile, err)
+		}
+		fmt.Printf("💾 %d comentário(s) inserido(s) em %s\n", len(comments), documentFile)
+	}
+
+	if failed := len(targets) - len(comments); failed > 0 {
+		return fmt.Errorf("%d de %d comentário(s) não puderam ser gerados", failed, len(targets))
+	}
+
+	return nil
+}
+
+// targetNames lista os nomes dos alvos para o modo verboso
+func targetNames(targets []docgen.Target) string {
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = t.Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/policy"
+)
+
+// Códigos de saída documentados. O review usa 20-24 para os achados.
+const (
+	exitFailure        = 1  // Erro genérico
+	exitUsage          = 2  // Flags, argumentos ou configuração inválidos
+	exitInput          = 3  // Entrada ilegível ou vazia
+	exitDetection      = 4  // Linguagem não detectada
+	exitUnavailable    = 5  // Servidor inacessível
+	exitTimeout        = 6  // Tempo limite excedido
+	exitModelNotFound  = 7  // Modelo não instalado
+	exitContextTooLong = 8  // Código maior que o contexto do modelo
+	exitUnauthorized   = 9  // Credenciais rejeitadas
+	exitAPI            = 10 // Outros erros retornados pela API
+	exitInternal       = 11 // Erro interno (panic)
+	exitPolicy         = 12 // Destino recusado pela política
+)
+
+// errorTypes nomeia cada código na saída JSON; os nomes seguem os da API REST e do modo lote
+var errorTypes = map[int]string{
+	exitFailure:        "error",
+	exitUsage:          "usage_error",
+	exitInput:          "input_error",
+	exitDetection:      "detection_failed",
+	exitUnavailable:    "backend_unavailable",
+	exitTimeout:        "timeout",
+	exitModelNotFound:  "model_not_found",
+	exitContextTooLong: "context_too_long",
+	exitUnauthorized:   "unauthorized",
+	exitAPI:            "api_error",
+	exitInternal:       "internal_error",
+	exitPolicy:         "policy_blocked",
+}
+
+// usageError marca um erro de uso (flags, argumentos ou configuração)
+func usageError(err error) error {
+	return &exitError{code: exitUsage, err: err}
+}
+
+// inputError marca um erro de leitura ou validação da entrada
+func inputError(err error) error {
+	return &exitError{code: exitInput, err: err}
+}
+
+// apiErrorInfo associa um erro da API ao código de saída e a uma dica de correção.
+// Retorna ok = false para erros que não vieram da API.
+func apiErrorInfo(err error) (code int, hint string, ok bool) {
+	var apiErr *openai.APIError
+	switch {
+	case errors.Is(err, openai.ErrServerUnavailable):
+		return exitUnavailable, fmt.Sprintf("Verifique se o Ollama está rodando ('ollama serve') em %s, ou ajuste --api-url", openai.BaseURL(apiURL)), true
+	case errors.Is(err, openai.ErrTimeout):
+		return exitTimeout, "Aumente --timeout ou pré-carregue o modelo com 'code-explainer models warm'", true
+	case errors.Is(err, openai.ErrModelNotFound):
+		return exitModelNotFound, fmt.Sprintf("Baixe o modelo com 'code-explainer models pull %s' ou veja os instalados com 'code-explainer list models'", modelName), true
+	case errors.Is(err, openai.ErrContextTooLong):
+		return exitContextTooLong, "Explique um trecho menor (ex.: uma função por vez) ou use um modelo com contexto maior", true
+	case errors.Is(err, openai.ErrUnauthorized):
+		return exitUnauthorized, "Confira as credenciais exigidas pelo servidor configurado em --api-url", true
+	case errors.As(err, &apiErr):
+		return exitAPI, "Rode 'code-explainer doctor' para diagnosticar o ambiente", true
+	}
+	return 0, "", false
+}
+
+// exitCode retorna o código de saída e a dica de correção para o erro.
+// started indica se o comando chegou a ser executado; antes disso, o erro é de uso.
+func exitCode(err error, started bool) (int, string) {
+	if errors.Is(err, policy.ErrBlocked) {
+		return exitPolicy, "Use um servidor local (--api-url http://localhost:11434/api/generate) ou peça a inclusão do host em policy.allowed_hosts"
+	}
+	if code, hint, ok := apiErrorInfo(err); ok {
+		return code, hint
+	}
+
+	var exitErr *exitError
+	switch {
+	case errors.As(err, &exitErr):
+		if exitErr.code == exitUsage {
+			return exitUsage, "Use --help para ver as flags e exemplos"
+		}
+		return exitErr.code, ""
+	case !started:
+		return exitUsage, "Use --help para ver as flags e exemplos"
+	}
+	return exitFailure, ""
+}
+
+// errorOutput é o formato de erro emitido em stderr com --format json
+type errorOutput struct {
+	Error struct {
+		Type     string `json:"type"`
+		Message  string `json:"message"`
+		Hint     string `json:"hint,omitempty"`
+		ExitCode int    `json:"exit_code"`
+	} `json:"error"`
+}
+
+// printError mostra o erro em w (stderr), como texto com dica de correção ou como JSON
+func printError(w io.Writer, err error, code int, hint string, asJSON bool) {
+	if asJSON {
+		var out errorOutput
+		out.Error.Type = errorTypes[code]
+		if out.Error.Type == "" {
+			out.Error.Type = "error"
+		}
+		out.Error.Message = err.Error()
+		out.Error.Hint = hint
+		out.Error.ExitCode = code
+
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.Encode(out)
+		return
+	}
+
+	fmt.Fprintf(w, "❌ Erro: %v\n", err)
+	if hint != "" {
+		fmt.Fprintf(w, "💡 %s\n", hint)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/policy"
+)
+
+func TestExitCode(t *testing.T) {
+	apiErr := &openai.APIError{StatusCode: 500, Message: "500 Internal Server Error"}
+	modelErr := &openai.APIError{StatusCode: 404, Message: "404 Not Found", Kind: openai.ErrModelNotFound}
+
+	tests := []struct {
+		name     string
+		err      error
+		started  bool
+		expected int
+		hint     bool
+	}{
+		{name: "Erro genérico", err: errors.New("falhou"), started: true, expected: exitFailure},
+		{name: "Erro de uso", err: usageError(errors.New("flag inválida")), started: true, expected: exitUsage, hint: true},
+		{name: "Erro de entrada", err: inputError(errors.New("vazio")), started: true, expected: exitInput},
+		{name: "Linguagem não detectada", err: &exitError{code: exitDetection, err: errors.New("?")}, started: true, expected: exitDetection},
+		{name: "Servidor inacessível", err: fmt.Errorf("x: %w", openai.ErrServerUnavailable), started: true, expected: exitUnavailable, hint: true},
+		{name: "Timeout", err: fmt.Errorf("x: %w", openai.ErrTimeout), started: true, expected: exitTimeout, hint: true},
+		{name: "Modelo não encontrado", err: fmt.Errorf("x: %w", modelErr), started: true, expected: exitModelNotFound, hint: true},
+		{name: "Contexto excedido", err: openai.ErrContextTooLong, started: true, expected: exitContextTooLong, hint: true},
+		{name: "Não autorizado", err: openai.ErrUnauthorized, started: true, expected: exitUnauthorized, hint: true},
+		{name: "Outro erro da API", err: fmt.Errorf("x: %w", apiErr), started: true, expected: exitAPI, hint: true},
+		{name: "Política", err: fmt.Errorf("x: %w", policy.ErrBlocked), started: true, expected: exitPolicy, hint: true},
+		{name: "Achados do review", err: &exitError{code: 22, err: errors.New("achados")}, started: true, expected: 22},
+
+		// Antes de o comando começar, erros sem tipo são de uso; erros tipados mantêm o código
+		{name: "Flag do cobra antes do início", err: errors.New("unknown flag: --x"), expected: exitUsage, hint: true},
+		{name: "Erro de entrada antes do início", err: inputError(errors.New("vazio")), expected: exitInput},
+		{name: "Erro de uso antes do início", err: usageError(errors.New("config")), expected: exitUsage, hint: true},
+		{name: "Erro da API antes do início", err: openai.ErrTimeout, expected: exitTimeout, hint: true},
+		{name: "Política antes do início", err: policy.ErrBlocked, expected: exitPolicy, hint: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, hint := exitCode(tt.err, tt.started)
+			if code != tt.expected {
+				t.Errorf("exitCode() = %d, want %d", code, tt.expected)
+			}
+			if (hint != "") != tt.hint {
+				t.Errorf("exitCode() hint = %q, want hint %v", hint, tt.hint)
+			}
+			if errorTypes[code] == "" && code < 20 {
+				t.Errorf("Code %d has no error type", code)
+			}
+		})
+	}
+}
+
+func TestPrintErrorJSON(t *testing.T) {
+	oldModel, oldURL := modelName, apiURL
+	modelName, apiURL = "codellama", "http://localhost:11434/api/generate"
+	defer func() { modelName, apiURL = oldModel, oldURL }()
+
+	tests := []struct {
+		name    string
+		err     error
+		started bool
+		golden  string
+	}{
+		{name: "Modelo não encontrado", err: fmt.Errorf("erro ao explicar código: %w", openai.ErrModelNotFound), started: true, golden: "error_model_not_found.json"},
+		{name: "Erro de uso", err: errors.New(`unknown flag: --modelo`), golden: "error_usage.json"},
+		{name: "Erro genérico", err: errors.New("2 de 3 arquivo(s) não puderam ser explicados <main.go>"), started: true, golden: "error_generic.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			code, hint := exitCode(tt.err, tt.started)
+			printError(&out, tt.err, code, hint, true)
+
+			expected, err := os.ReadFile(filepath.Join("testdata", tt.golden))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != string(expected) {
+				t.Errorf("printError() =\n%s\nwant\n%s", out.String(), expected)
+			}
+		})
+	}
+}
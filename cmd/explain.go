@@ -2,19 +2,24 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/tracing"
 	"github.com/spf13/cobra"
 )
 
 var (
 	codeInput   string
 	filePath    string
+	dirPath     string
 	interactive bool
 )
 
@@ -24,15 +29,19 @@ var explainCmd = &cobra.Command{
 	Short: "Explica um trecho de código usando IA",
 	Long: `Explica um trecho de código usando IA local (Ollama).
 
-Você pode fornecer o código de três formas:
+Você pode fornecer o código de quatro formas:
 1. Via flag --code: code-explainer explain --code "func main() {}"
 2. Via arquivo: code-explainer explain --file main.go
-3. Interativo: code-explainer explain (digite o código e pressione Ctrl+D)
+3. Via diretório: code-explainer explain --dir ./src (explica cada arquivo suportado)
+4. Interativo: code-explainer explain (digite o código e pressione Ctrl+D)
+
+No modo diretório, use --concurrency para enviar várias requisições em paralelo.
 
 Exemplos:
   code-explainer explain --code "print('Hello World')"
   code-explainer explain --file main.go
   code-explainer explain --file main.go --output explanation.md
+  code-explainer explain --dir ./src --concurrency 4
   code-explainer explain --model gpt-3.5-turbo --code "console.log('Hello')"`,
 	RunE: runExplain,
 }
@@ -43,82 +52,77 @@ func init() {
 	// Flags específicas do comando explain
 	explainCmd.Flags().StringVarP(&codeInput, "code", "c", "", "Código a ser explicado")
 	explainCmd.Flags().StringVarP(&filePath, "file", "f", "", "Arquivo contendo o código")
+	explainCmd.Flags().StringVarP(&dirPath, "dir", "d", "", "Diretório cujos arquivos serão explicados")
 	explainCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Modo interativo (padrão se nenhuma entrada for fornecida)")
 
 	// Marcar flags como mutuamente exclusivas
-	explainCmd.MarkFlagsMutuallyExclusive("code", "file", "interactive")
+	explainCmd.MarkFlagsMutuallyExclusive("code", "file", "dir", "interactive")
 }
 
 func runExplain(cmd *cobra.Command, args []string) error {
 	var code string
 	var err error
 
+	if dirPath != "" {
+		return runExplainDir(cmd.Context(), dirPath)
+	}
+
 	// Determinar a fonte do código
 	switch {
 	case codeInput != "":
 		code = codeInput
-		if verbose {
-			fmt.Printf("📝 Usando código fornecido via flag\n")
-		}
+		slog.Debug("usando código fornecido via flag")
 
 	case filePath != "":
 		code, err = readFile(filePath)
 		if err != nil {
 			return fmt.Errorf("erro ao ler arquivo %s: %w", filePath, err)
 		}
-		if verbose {
-			fmt.Printf("📁 Lendo código do arquivo: %s\n", filePath)
-		}
+		slog.Debug("lendo código do arquivo", "file", filePath)
 
 	case interactive || (codeInput == "" && filePath == ""):
 		code, err = readInteractive()
 		if err != nil {
 			return fmt.Errorf("erro ao ler entrada interativa: %w", err)
 		}
-		if verbose {
-			fmt.Printf("⌨️  Usando entrada interativa\n")
-		}
+		slog.Debug("usando entrada interativa")
 
 	default:
-		return fmt.Errorf("forneça o código via --code, --file ou use modo interativo")
+		return inputError(fmt.Errorf("forneça o código via --code, --file ou use modo interativo"))
 	}
 
 	if code == "" {
-		return fmt.Errorf("código vazio fornecido")
+		return inputError(fmt.Errorf("código vazio fornecido"))
 	}
 
 	// Detectar linguagem se não for forçada
 	detectedLang := language
 	if detectedLang == "" {
-		detectedLang = openai.DetectLanguage(code)
-		if verbose {
-			fmt.Printf("🔍 Linguagem detectada: %s\n", detectedLang)
-		}
+		detectedLang = openai.DetectLanguageContext(cmd.Context(), code)
+		slog.Debug("linguagem detectada", "language", detectedLang)
 	}
 
 	// Configurar cliente
-	config := &openai.Config{
-		APIURL:  apiURL,
-		Model:   modelName,
-		Timeout: time.Duration(timeout) * time.Second,
-	}
+	config := newConfig()
+	config.Language = detectedLang
 
-	if verbose {
-		fmt.Printf("🤖 Usando modelo: %s\n", config.Model)
-		fmt.Printf("🌐 API URL: %s\n", config.APIURL)
-		fmt.Printf("⏱️  Timeout: %ds\n", timeout)
-		fmt.Printf("📊 Tamanho do código: %d caracteres\n", len(code))
-		fmt.Println("🔄 Enviando para análise...")
-	}
+	slog.Debug("enviando para análise",
+		"model", config.Model,
+		"api_url", config.APIURL,
+		"timeout_s", timeout,
+		"code_chars", len(code),
+	)
 
 	// Explicar código
-	explanation, err := openai.ExplainCode(code, config)
+	explanation, err := explainCode(cmd.Context(), code, config)
 	if err != nil {
 		return fmt.Errorf("erro ao explicar código: %w", err)
 	}
 
 	// Formatar saída
+	_, span := tracing.Start(cmd.Context(), "format_output")
 	outputText := formatOutput(code, detectedLang, explanation)
+	span.End()
 
 	// Escrever saída
 	if output != "" {
@@ -126,9 +130,7 @@ func runExplain(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("erro ao escrever arquivo de saída: %w", err)
 		}
-		if verbose {
-			fmt.Printf("💾 Explicação salva em: %s\n", output)
-		}
+		slog.Debug("explicação salva", "output", output)
 	} else {
 		fmt.Println(outputText)
 	}
@@ -136,17 +138,113 @@ func runExplain(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runExplainDir explica todos os arquivos suportados de um diretório usando o pool de workers
+func runExplainDir(ctx context.Context, dir string) error {
+	files, err := collectSourceFiles(dir)
+	if err != nil {
+		return fmt.Errorf("erro ao percorrer diretório %s: %w", dir, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("nenhum arquivo de linguagem suportada encontrado em %s", dir)
+	}
+
+	config := newConfig()
+	jobs := make([]openai.Job, 0, len(files))
+	codes := make(map[string]string, len(files))
+
+	for _, file := range files {
+		code, err := readFile(file)
+		if err != nil {
+			return fmt.Errorf("erro ao ler arquivo %s: %w", file, err)
+		}
+		codes[file] = code
+		jobs = append(jobs, openai.Job{ID: file, Code: code, Config: config})
+	}
+
+	slog.Debug("enviando diretório para análise",
+		"dir", dir,
+		"files", len(files),
+		"model", config.Model,
+		"concurrency", concurrency,
+	)
+
+	results := openai.ExplainAll(ctx, jobs, concurrency, explainCode)
+
+	var out strings.Builder
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		code := codes[r.ID]
+		lang := language
+		if lang == "" {
+			lang = openai.DetectLanguage(code)
+		}
+		out.WriteString(fmt.Sprintf("📄 **Arquivo:** %s\n\n", r.ID))
+		out.WriteString(formatOutput(code, lang, r.Explanation))
+		out.WriteString("\n")
+		slog.Debug("arquivo explicado", "file", r.ID, "duration_ms", r.Duration.Milliseconds())
+	}
+
+	if output != "" {
+		if err := writeToFile(output, out.String()); err != nil {
+			return fmt.Errorf("erro ao escrever arquivo de saída: %w", err)
+		}
+		slog.Debug("explicações salvas", "output", output)
+	} else {
+		fmt.Print(out.String())
+	}
+
+	failures := openai.CountFailures(results)
+	if failures > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  %d de %d arquivo(s) falharam:\n", failures, len(results))
+		for _, r := range results {
+			if r.Err != nil {
+				fmt.Fprintf(os.Stderr, "   ❌ %s: %v\n", r.ID, r.Err)
+			}
+		}
+		return fmt.Errorf("%d de %d arquivo(s) não puderam ser explicados", failures, len(results))
+	}
+
+	return nil
+}
+
+// collectSourceFiles lista, em ordem lexical, os arquivos de linguagens suportadas
+// dentro de dir, ignorando diretórios ocultos e de dependências
+func collectSourceFiles(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if openai.LanguageFromFilename(path) != "" {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	return files, err
+}
+
 // readFile lê o conteúdo de um arquivo
 func readFile(path string) (string, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return "", err
+		return "", inputError(err)
 	}
 	defer file.Close()
 
 	content, err := io.ReadAll(file)
 	if err != nil {
-		return "", err
+		return "", inputError(err)
 	}
 
 	return string(content), nil
@@ -164,7 +262,7 @@ func readInteractive() (string, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return "", err
+		return "", inputError(err)
 	}
 
 	return strings.Join(lines, "\n"), nil
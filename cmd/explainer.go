@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/cache"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// explainCode explica o código consultando antes o cache em disco e registra o resultado no histórico.
+// Tem a assinatura de openai.ExplainFunc para ser usada pelo pool de workers.
+func explainCode(ctx context.Context, code string, config *openai.Config) (explanation string, err error) {
+	ctx, span := tracing.Start(ctx, "explain_code")
+	defer func() { tracing.End(span, err) }()
+
+	cfg := *config
+	if cfg.Language == "" {
+		cfg.Language = openai.DetectLanguageContext(ctx, code)
+	}
+	span.SetAttributes(attribute.String("code.language", cfg.Language), attribute.String("gen_ai.request.model", openai.EffectiveModel(&cfg)))
+	redacted := redactCode(ctx, code)
+
+	key := cache.Key(
+		cfg.APIURL,
+		openai.EffectiveModel(&cfg),
+		cfg.Language,
+		cfg.Level,
+		openai.BuildPrompt(redacted.Text, cfg.Language, cfg.Level),
+	)
+
+	var usage *openai.Usage
+	cfg.OnUsage = openai.CaptureUsage(cfg.OnUsage, &usage)
+
+	// Se fn não for chamada, a explicação veio do cache
+	cached := true
+	start := time.Now()
+	explanation, err = withCache(ctx, key, func() (string, error) {
+		cached = false
+		return openai.ExplainCodeContext(ctx, redacted.Text, &cfg)
+	})
+	if err != nil {
+		return "", err
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", cached))
+
+	recordHistory(ctx, "explain", redacted.Text, cfg.Language, openai.EffectiveModel(&cfg), cfg.Level, explanation, time.Since(start), cached, usage)
+	return restoreOutput(redacted, explanation), nil
+}
+
+// streamCode explica o código em streaming, sem cache, e registra o resultado no histórico.
+// Tem a assinatura de openai.StreamFunc para ser usada pelo servidor.
+func streamCode(ctx context.Context, code string, config *openai.Config, onToken openai.TokenFunc) (explanation string, err error) {
+	ctx, span := tracing.Start(ctx, "explain_code", attribute.Bool("stream", true))
+	defer func() { tracing.End(span, err) }()
+
+	cfg := *config
+	if cfg.Language == "" {
+		cfg.Language = openai.DetectLanguageContext(ctx, code)
+	}
+	span.SetAttributes(attribute.String("code.language", cfg.Language), attribute.String("gen_ai.request.model", openai.EffectiveModel(&cfg)))
+
+	// Os trechos enviados a onToken mantêm os placeholders; apenas o texto final é restaurado
+	redacted := redactCode(ctx, code)
+	var usage *openai.Usage
+	cfg.OnUsage = openai.CaptureUsage(cfg.OnUsage, &usage)
+
+	start := time.Now()
+	explanation, err = openai.ExplainCodeStream(ctx, redacted.Text, &cfg, onToken)
+	if err != nil {
+		return "", err
+	}
+
+	recordHistory(ctx, "explain", redacted.Text, cfg.Language, openai.EffectiveModel(&cfg), cfg.Level, explanation, time.Since(start), false, usage)
+	return restoreOutput(redacted, explanation), nil
+}
+
+// promptSource é o que fica registrado no histórico para um prompt: o comando que o
+// gerou e o código de origem (o diff, o arquivo revisado), em vez do prompt completo
+type promptSource struct {
+	command  string
+	code     string
+	language string
+}
+
+// generatePrompt envia um prompt já montado ao modelo, consultando antes o cache,
+// e registra a resposta no histórico como uma entrada de source.
+func generatePrompt(ctx context.Context, prompt string, config *openai.Config, source promptSource) (string, error) {
+	redacted := redactCode(ctx, prompt)
+	key := cache.Key(config.APIURL, openai.EffectiveModel(config), "prompt", redacted.Text)
+
+	cfg := *config
+	var usage *openai.Usage
+	cfg.OnUsage = openai.CaptureUsage(cfg.OnUsage, &usage)
+
+	cached := true
+	start := time.Now()
+	answer, err := withCache(ctx, key, func() (string, error) {
+		cached = false
+		return openai.Generate(ctx, redacted.Text, &cfg)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	code := redacted.Text
+	if source.code != "" {
+		code = redactCode(ctx, source.code).Text
+	}
+	recordHistory(ctx, source.command, code, source.language, openai.EffectiveModel(&cfg), cfg.Level, answer, time.Since(start), cached, usage)
+	return restoreOutput(redacted, answer), nil
+}
+
+// promptFunc adapta generatePrompt à assinatura de openai.ExplainFunc, que recebe o
+// prompt no lugar do código. sources associa cada prompt à sua origem.
+func promptFunc(command string, sources map[string]promptSource) openai.ExplainFunc {
+	return func(ctx context.Context, prompt string, config *openai.Config) (string, error) {
+		source, ok := sources[prompt]
+		if !ok {
+			source = promptSource{command: command}
+		}
+		return generatePrompt(ctx, prompt, config, source)
+	}
+}
+
+// withCache retorna o valor em cache para a chave ou executa fn e grava o resultado.
+// Falhas do cache nunca impedem a chamada ao modelo.
+func withCache(ctx context.Context, key string, fn func() (string, error)) (string, error) {
+	c, err := openCache()
+	if err != nil {
+		slog.WarnContext(ctx, "cache indisponível", "error", err)
+	}
+	if c == nil {
+		return fn()
+	}
+
+	_, span := tracing.Start(ctx, "cache_lookup")
+	value, ok, err := c.Get(key)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil && ok))
+	tracing.End(span, err)
+
+	if err == nil && ok {
+		slog.DebugContext(ctx, "explicação obtida do cache", "key", key[:12])
+		observeCache(true)
+		return value, nil
+	}
+	observeCache(false)
+
+	value, err = fn()
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.Put(key, value); err != nil {
+		slog.WarnContext(ctx, "não foi possível gravar no cache", "error", err)
+	}
+
+	return value, nil
+}
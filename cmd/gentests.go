@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/mvcbotelho/code-explainer/testgen"
+	"github.com/spf13/cobra"
+)
+
+var (
+	genTestsFile     string
+	genTestsFunc     string
+	genTestsForce    bool
+	genTestsSkeleton bool
+)
+
+// genTestsCmd representa o comando gen-tests
+var genTestsCmd = &cobra.Command{
+	Use:   "gen-tests",
+	Short: "Gera testes table-driven para uma função Go",
+	Long: `Gera um arquivo _test.go com um teste table-driven para uma função Go.
+
+O esqueleto do teste (struct de casos, laço com t.Run e verificações) é gerado
+a partir da assinatura da função. O modelo preenche apenas a tabela de casos,
+e o resultado é validado com go/parser antes de ser escrito.
+
+O arquivo de destino é <arquivo>_test.go (ou o caminho de --output). Se ele já
+existir, o comando se recusa a alterá-lo, a menos que --force seja usado: nesse
+caso o teste é acrescentado ao arquivo, preservando os testes existentes, e o
+comando falha se uma função com o mesmo nome já existir.
+
+Métodos são indicados como Tipo.Metodo.
+
+Exemplos:
+  code-explainer gen-tests --file openai/language.go --func DetectLanguage
+  code-explainer gen-tests --file cache/cache.go --func Cache.Get --force
+  code-explainer gen-tests --file util.go --func Parse --skeleton`,
+	RunE: runGenTests,
+}
+
+func init() {
+	rootCmd.AddCommand(genTestsCmd)
+
+	genTestsCmd.Flags().StringVarP(&genTestsFile, "file", "f", "", "Arquivo Go com a função")
+	genTestsCmd.Flags().StringVar(&genTestsFunc, "func", "", "Função a ser testada (Funcao ou Tipo.Metodo)")
+	genTestsCmd.Flags().BoolVar(&genTestsForce, "force", false, "Acrescenta o teste ao arquivo de teste se ele já existir")
+	genTestsCmd.Flags().BoolVar(&genTestsSkeleton, "skeleton", false, "Gera apenas o esqueleto, sem consultar o modelo")
+
+	genTestsCmd.MarkFlagRequired("file")
+	genTestsCmd.MarkFlagRequired("func")
+}
+
+func runGenTests(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	src, err := readFile(genTestsFile)
+	if err != nil {
+		return fmt.Errorf("erro ao ler arquivo %s: %w", genTestsFile, err)
+	}
+
+	fn, err := testgen.FindFunction(src, genTestsFile, genTestsFunc)
+	if err != nil {
+		return inputError(err)
+	}
+
+	dest := output
+	if dest == "" {
+		dest = testgen.TestPath(genTestsFile)
+	}
+	existing, err := os.ReadFile(dest)
+	exists := err == nil
+	if exists && !genTestsForce {
+		return usageError(fmt.Errorf("o arquivo %s já existe; use --force para acrescentar o teste a ele", dest))
+	}
+
+	skeleton, err := testgen.Skeleton(fn)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar esqueleto: %w", err)
+	}
+
+	result := skeleton
+	if !genTestsSkeleton {
+		config := newConfig()
+		slog.Debug("gerando casos de teste", "test", fn.TestName(), "dest", dest, "model", config.Model)
+
+		source := promptSource{command: "gen-tests", code: fn.Source, language: "Go"}
+		answer, err := generatePrompt(cmd.Context(), testgen.BuildPrompt(fn, skeleton), config, source)
+		if err != nil {
+			return fmt.Errorf("erro ao gerar casos de teste: %w", err)
+		}
+
+		result, err = testgen.Complete(skeleton, answer)
+		if err != nil {
+			return fmt.Errorf("%w (use --skeleton para gerar apenas o esqueleto)", err)
+		}
+	}
+
+	if exists {
+		result, err = testgen.Merge(string(existing), result)
+		if err != nil {
+			return inputError(fmt.Errorf("não foi possível acrescentar o teste a %s: %w", dest, err))
+		}
+	}
+
+	if err := writeToFile(dest, result); err != nil {
+		return fmt.Errorf("erro ao escrever %s: %w", dest, err)
+	}
+	fmt.Printf("💾 Teste %s salvo em: %s\n", fn.TestName(), dest)
+
+	return nil
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/history"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/spf13/cobra"
+)
+
+var (
+	noHistory     bool
+	historyLimit  int
+	historySince  time.Duration
+	historyFormat string
+	historyAll    bool
+
+	historyOnce  sync.Once
+	historyStore *history.Store
+	historyErr   error
+)
+
+// historyCmd representa o comando history
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Consulta o histórico de explicações",
+	Long: `Consulta o histórico local das explicações geradas.
+
+Cada resposta do modelo (comando, hash e prévia do código, linguagem, modelo,
+duração e resultado) é registrada em $XDG_DATA_HOME/code-explainer/history.jsonl
+(ou no arquivo da variável HISTORY_FILE). Use --no-history para não registrar. São registradas as respostas de explain,
+diff, commit, review, chat, document e gen-tests (além de batch, serve, lsp e mcp).
+
+Subcomandos:
+  list    - Lista as explicações mais recentes
+  show    - Mostra uma explicação completa
+  search  - Procura um termo no código e nas explicações
+  export  - Exporta o histórico (json, jsonl ou markdown)
+  delete  - Remove entradas do histórico (cada prefixo deve indicar uma única entrada)
+
+Exemplos:
+  code-explainer history list --since 168h
+  code-explainer history show 3f2a9c
+  code-explainer history search "defer"
+  code-explainer history export --format markdown --output historico.md
+  code-explainer history delete 3f2a9c`,
+}
+
+// historyListCmd lista as entradas mais recentes
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lista as explicações mais recentes",
+	Args:  cobra.NoArgs,
+	RunE:  runHistoryList,
+}
+
+// historyShowCmd mostra uma entrada completa
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Mostra uma explicação completa",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runHistoryShow,
+}
+
+// historySearchCmd procura um termo no histórico
+var historySearchCmd = &cobra.Command{
+	Use:   "search <termo>",
+	Short: "Procura um termo no código e nas explicações",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runHistorySearch,
+}
+
+// historyExportCmd exporta o histórico
+var historyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exporta o histórico",
+	Args:  cobra.NoArgs,
+	RunE:  runHistoryExport,
+}
+
+// historyDeleteCmd remove entradas do histórico
+var historyDeleteCmd = &cobra.Command{
+	Use:   "delete [id...]",
+	Short: "Remove entradas do histórico",
+	RunE:  runHistoryDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historySearchCmd)
+	historyCmd.AddCommand(historyExportCmd)
+	historyCmd.AddCommand(historyDeleteCmd)
+
+	rootCmd.PersistentFlags().BoolVar(&noHistory, "no-history", false, "Não registrar explicações no histórico")
+
+	for _, c := range []*cobra.Command{historyListCmd, historySearchCmd} {
+		c.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Número máximo de entradas (0 = todas)")
+	}
+	for _, c := range []*cobra.Command{historyListCmd, historySearchCmd, historyExportCmd} {
+		c.Flags().DurationVar(&historySince, "since", 0, "Apenas entradas mais recentes que a duração (ex.: 168h)")
+	}
+	historyExportCmd.Flags().StringVar(&historyFormat, "format", history.FormatJSON, "Formato de exportação (json, jsonl, markdown)")
+	historyDeleteCmd.Flags().BoolVar(&historyAll, "all", false, "Remove todas as entradas")
+}
+
+// openHistory abre o histórico compartilhado pelo processo, ou retorna nil se estiver desativado
+func openHistory() (*history.Store, error) {
+	if noHistory {
+		return nil, nil
+	}
+	return newHistory()
+}
+
+// newHistory retorna o histórico do arquivo de HISTORY_FILE ou do caminho padrão.
+// A mesma instância é reutilizada para que gravações concorrentes sejam serializadas.
+func newHistory() (*history.Store, error) {
+	historyOnce.Do(func() {
+		path := os.Getenv("HISTORY_FILE")
+		if path == "" {
+			path, historyErr = history.DefaultPath()
+		}
+		if historyErr == nil {
+			historyStore = history.New(path)
+		}
+	})
+	return historyStore, historyErr
+}
+
+// recordHistory registra uma resposta do comando informado; falhas são apenas registradas no log.
+// code e result devem ser os textos já ocultados pelo redator, para que dados sensíveis não
+// sejam gravados em disco.
+func recordHistory(ctx context.Context, command, code, lang, model, level, result string, duration time.Duration, cached bool, usage *openai.Usage) {
+	store, err := openHistory()
+	if store == nil {
+		if err != nil {
+			slog.WarnContext(ctx, "histórico indisponível", "error", err)
+		}
+		return
+	}
+
+	entry := history.NewEntry(code, result, duration)
+	entry.Command = command
+	entry.Language = lang
+	entry.Model = model
+	entry.Level = level
+	entry.Cached = cached
+	if usage != nil {
+		entry.PromptTokens = usage.PromptTokens
+		entry.CompletionTokens = usage.CompletionTokens
+		entry.EvalMs = usage.EvalMs
+	}
+
+	if err := store.Append(entry); err != nil {
+		slog.WarnContext(ctx, "não foi possível registrar no histórico", "error", err)
+	}
+}
+
+// loadHistory lê as entradas aplicando o filtro --since
+func loadHistory(query string) ([]history.Entry, error) {
+	store, err := newHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []history.Entry
+	if query == "" {
+		entries, err = store.All()
+	} else {
+		entries, err = store.Search(query)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler histórico: %w", err)
+	}
+
+	if historySince > 0 {
+		entries = history.Since(entries, time.Now().Add(-historySince))
+	}
+	return entries, nil
+}
+
+func runHistoryList(cmd *cobra.Command, args []string) error {
+	entries, err := loadHistory("")
+	if err != nil {
+		return err
+	}
+	printHistoryEntries(entries)
+	return nil
+}
+
+func runHistorySearch(cmd *cobra.Command, args []string) error {
+	entries, err := loadHistory(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	printHistoryEntries(entries)
+	return nil
+}
+
+// printHistoryEntries lista as entradas da mais recente para a mais antiga, respeitando --limit
+func printHistoryEntries(entries []history.Entry) {
+	if len(entries) == 0 {
+		fmt.Println("📭 Nenhuma explicação encontrada no histórico.")
+		return
+	}
+
+	fmt.Println("📜 Histórico de Explicações")
+	fmt.Println(strings.Repeat("=", 30))
+	fmt.Println()
+
+	shown := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		if historyLimit > 0 && shown == historyLimit {
+			break
+		}
+		e := entries[i]
+		cached := ""
+		if e.Cached {
+			cached = " ♻️"
+		}
+		fmt.Printf("🆔 %s  %s  %s · %s · %s · %dms%s\n", e.ID, e.Time.Format(time.DateTime), entryCommand(e), e.Language, e.Model, e.DurationMs, cached)
+		fmt.Printf("   %s\n", e.Preview)
+		shown++
+	}
+
+	if shown < len(entries) {
+		fmt.Printf("\n... e mais %d entrada(s) (use --limit 0 para ver todas)\n", len(entries)-shown)
+	}
+}
+
+func runHistoryShow(cmd *cobra.Command, args []string) error {
+	store, err := newHistory()
+	if err != nil {
+		return err
+	}
+
+	e, err := store.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	var out strings.Builder
+	out.WriteString("📘 Explicação do histórico:\n")
+	out.WriteString(strings.Repeat("=", 50) + "\n\n")
+	out.WriteString(fmt.Sprintf("🆔 **ID:** %s\n", e.ID))
+	out.WriteString(fmt.Sprintf("🕰️  **Data:** %s\n", e.Time.Format(time.DateTime)))
+	out.WriteString(fmt.Sprintf("⌨️  **Comando:** %s\n", entryCommand(e)))
+	out.WriteString(fmt.Sprintf("🔍 **Linguagem:** %s\n", e.Language))
+	out.WriteString(fmt.Sprintf("🤖 **Modelo:** %s\n", e.Model))
+	if e.Level != "" {
+		out.WriteString(fmt.Sprintf("📊 **Nível:** %s\n", e.Level))
+	}
+	out.WriteString(fmt.Sprintf("⏱️  **Duração:** %dms\n\n", e.DurationMs))
+	out.WriteString("💻 **Código analisado:**\n```\n" + e.Code + "\n```\n\n")
+	out.WriteString("🤖 **Explicação:**\n")
+	out.WriteString(e.Result + "\n")
+
+	return writeOutput(out.String())
+}
+
+func runHistoryExport(cmd *cobra.Command, args []string) error {
+	entries, err := loadHistory("")
+	if err != nil {
+		return err
+	}
+
+	var out strings.Builder
+	if err := history.Export(&out, entries, historyFormat); err != nil {
+		return err
+	}
+	return writeOutput(out.String())
+}
+
+func runHistoryDelete(cmd *cobra.Command, args []string) error {
+	if historyAll == (len(args) > 0) {
+		return fmt.Errorf("informe os IDs a remover ou use --all")
+	}
+
+	store, err := newHistory()
+	if err != nil {
+		return err
+	}
+
+	var removed int
+	if historyAll {
+		removed, err = store.Clear()
+	} else {
+		removed, err = store.Delete(args...)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao remover do histórico: %w", err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("nenhuma entrada corresponde a %s", strings.Join(args, ", "))
+	}
+
+	fmt.Printf("🗑️  %d entrada(s) removida(s) do histórico\n", removed)
+	return nil
+}
+
+// entryCommand retorna o comando que gerou a entrada; entradas antigas não o registram e são explicações
+func entryCommand(e history.Entry) string {
+	if e.Command == "" {
+		return "explain"
+	}
+	return e.Command
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExplainCodeRecordsRedactedHistory(t *testing.T) {
+	var prompt string
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(map[string]interface{}{"response": "explicação", "done": true})
+	}))
+	defer ollama.Close()
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	t.Setenv("HISTORY_FILE", path)
+	t.Setenv("MODEL_NAME", "")
+
+	restore := saveGlobals()
+	defer restore()
+	oldCache, oldHistory := noCache, noHistory
+	defer func() { noCache, noHistory = oldCache, oldHistory }()
+	noCache, noHistory = true, false
+	historyOnce, historyStore, historyErr = sync.Once{}, nil, nil
+	defer func() { historyOnce, historyStore, historyErr = sync.Once{}, nil, nil }()
+
+	cfg := newConfig()
+	cfg.APIURL, cfg.Language = ollama.URL+"/api/generate", "Go"
+
+	const secret = "maria@example.com"
+	code := `const contato = "` + secret + `"`
+	if _, err := explainCode(context.Background(), code, cfg); err != nil {
+		t.Fatalf("explainCode() error = %v", err)
+	}
+	if strings.Contains(prompt, secret) {
+		t.Fatalf("prompt sent to the model contains %q", secret)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 {
+		t.Fatal("Expected a history entry")
+	}
+	if strings.Contains(string(data), secret) {
+		t.Errorf("history contains %q:\n%s", secret, data)
+	}
+}
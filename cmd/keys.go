@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/auth"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keyAdmin         bool
+	keyDailyRequests int
+	keyDailyTokens   int
+)
+
+// keysCmd representa o comando keys
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Gerencia as API keys aceitas pelo comando serve",
+	Long: `Gerencia as API keys do modo serve, guardadas em um arquivo local que
+contém apenas o hash SHA-256 de cada chave. A chave em texto puro é mostrada
+uma única vez, ao ser criada.
+
+O arquivo fica em $XDG_STATE_HOME/code-explainer/keys.json (ou no caminho de
+auth.store no arquivo de configuração). Chaves também podem ser definidas em
+auth.keys no arquivo de configuração; o serve aceita as duas fontes.
+
+Subcomandos:
+  add     - Cria uma chave
+  list    - Lista as chaves do arquivo
+  remove  - Remove uma chave
+
+Exemplos:
+  code-explainer keys add ana --daily-requests 200 --daily-tokens 500000
+  code-explainer keys add ops --admin
+  code-explainer keys list
+  code-explainer keys remove ana`,
+}
+
+// keysAddCmd cria uma chave
+var keysAddCmd = &cobra.Command{
+	Use:   "add <nome>",
+	Short: "Cria uma chave e mostra seu valor uma única vez",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runKeysAdd,
+}
+
+// keysListCmd lista as chaves do arquivo
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lista as chaves do arquivo",
+	Args:  cobra.NoArgs,
+	RunE:  runKeysList,
+}
+
+// keysRemoveCmd remove uma chave
+var keysRemoveCmd = &cobra.Command{
+	Use:   "remove <nome>",
+	Short: "Remove uma chave",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runKeysRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.AddCommand(keysAddCmd)
+	keysCmd.AddCommand(keysListCmd)
+	keysCmd.AddCommand(keysRemoveCmd)
+
+	keysAddCmd.Flags().BoolVar(&keyAdmin, "admin", false, "Permite consultar o uso de todas as chaves em /v1/admin/usage")
+	keysAddCmd.Flags().IntVar(&keyDailyRequests, "daily-requests", 0, "Explicações por dia (0 = sem limite)")
+	keysAddCmd.Flags().IntVar(&keyDailyTokens, "daily-tokens", 0, "Tokens por dia (0 = sem limite)")
+}
+
+// keyStore retorna o arquivo de chaves configurado ou o padrão
+func keyStore() (*auth.Store, error) {
+	if loadedConfig != nil && loadedConfig.Auth.Store != "" {
+		return &auth.Store{Path: os.ExpandEnv(loadedConfig.Auth.Store)}, nil
+	}
+	path, err := auth.DefaultStorePath()
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Store{Path: path}, nil
+}
+
+// loadAuthenticator reúne as chaves do arquivo de configuração e do arquivo de
+// chaves. Retorna nil se nenhuma chave estiver definida.
+func loadAuthenticator() (*auth.Authenticator, error) {
+	var keys []auth.Key
+	if loadedConfig != nil {
+		for _, k := range loadedConfig.Auth.Keys {
+			hash := strings.ToLower(k.KeySHA256)
+			if k.Key != "" {
+				value := os.ExpandEnv(k.Key)
+				if value == "" {
+					return nil, fmt.Errorf("auth.keys (%s): a chave está vazia após expandir %s", k.Name, k.Key)
+				}
+				hash = auth.HashKey(value)
+			}
+			keys = append(keys, auth.Key{
+				Name:          k.Name,
+				Hash:          hash,
+				Admin:         k.Admin,
+				DailyRequests: k.DailyRequests,
+				DailyTokens:   k.DailyTokens,
+			})
+		}
+	}
+
+	store, err := keyStore()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	keys = append(keys, stored...)
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return auth.NewAuthenticator(keys)
+}
+
+func runKeysAdd(cmd *cobra.Command, args []string) error {
+	if keyDailyRequests < 0 || keyDailyTokens < 0 {
+		return usageError(fmt.Errorf("--daily-requests e --daily-tokens não podem ser negativos"))
+	}
+	store, err := keyStore()
+	if err != nil {
+		return err
+	}
+
+	token, err := store.Add(auth.Key{
+		Name:          args[0],
+		Admin:         keyAdmin,
+		DailyRequests: keyDailyRequests,
+		DailyTokens:   keyDailyTokens,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "🔑 Chave %s criada em %s. Guarde-a agora; ela não será mostrada novamente:\n", args[0], store.Path)
+	fmt.Println(token)
+	return nil
+}
+
+func runKeysList(cmd *cobra.Command, args []string) error {
+	store, err := keyStore()
+	if err != nil {
+		return err
+	}
+	keys, err := store.Load()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		fmt.Printf("Nenhuma chave em %s\n", store.Path)
+		return nil
+	}
+
+	fmt.Printf("📁 %s\n\n", store.Path)
+	for _, k := range keys {
+		role := ""
+		if k.Admin {
+			role = " · administrador"
+		}
+		created := ""
+		if !k.CreatedAt.IsZero() {
+			created = " · criada em " + k.CreatedAt.Local().Format(time.DateTime)
+		}
+		fmt.Printf("🔑 %s%s · %s requisições/dia · %s tokens/dia%s\n", k.Name, role, formatQuota(k.DailyRequests), formatQuota(k.DailyTokens), created)
+	}
+	return nil
+}
+
+func runKeysRemove(cmd *cobra.Command, args []string) error {
+	store, err := keyStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Remove(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("🗑️  Chave %s removida\n", args[0])
+	return nil
+}
+
+// formatQuota formata uma cota diária
+func formatQuota(n int) string {
+	if n == 0 {
+		return "∞"
+	}
+	return fmt.Sprint(n)
+}
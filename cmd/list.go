@@ -2,12 +2,16 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/mvcbotelho/code-explainer/openai"
 	"github.com/spf13/cobra"
 )
 
+var listModelsTimeout int
+
 // listCmd representa o comando list
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -15,7 +19,7 @@ var listCmd = &cobra.Command{
 	Long: `Lista informações sobre modelos de IA disponíveis e linguagens suportadas.
 
 Subcomandos:
-  models     - Lista modelos de IA recomendados
+  models     - Lista modelos de IA disponíveis
   languages  - Lista linguagens de programação suportadas
   config     - Mostra configuração atual
 
@@ -28,11 +32,14 @@ Exemplos:
 // listModelsCmd lista os modelos de IA
 var listModelsCmd = &cobra.Command{
 	Use:   "models",
-	Short: "Lista modelos de IA recomendados",
-	Long: `Lista os modelos de IA recomendados para uso com o Code Explainer.
+	Short: "Lista os modelos de IA disponíveis no servidor",
+	Long: `Lista os modelos instalados no servidor configurado (--api-url), com tamanho,
+família, quantização e data de modificação.
 
-Estes modelos são compatíveis com Ollama e podem ser usados
-para explicar código de programação.`,
+Consulta /api/tags do Ollama e, se o endpoint não existir, /v1/models de
+servidores compatíveis com OpenAI. Modelos especializados em código são
+marcados com ⭐. Se o servidor estiver inacessível, mostra a lista de
+modelos recomendados.`,
 	Run: runListModels,
 }
 
@@ -59,52 +66,114 @@ func init() {
 	listCmd.AddCommand(listModelsCmd)
 	listCmd.AddCommand(listLanguagesCmd)
 	listCmd.AddCommand(listConfigCmd)
+
+	listModelsCmd.Flags().IntVar(&listModelsTimeout, "probe-timeout", 5, "Timeout em segundos para consultar os modelos do servidor")
+}
+
+// recommendedModels é a lista estática exibida quando o servidor não responde
+var recommendedModels = []struct {
+	Name        string
+	Description string
+	Size        string
+	BestFor     string
+}{
+	{
+		Name:        "codellama",
+		Description: "Modelo especializado em código, baseado no Llama 2",
+		Size:        "~4GB",
+		BestFor:     "Explicação de código, análise de algoritmos",
+	},
+	{
+		Name:        "codellama:7b",
+		Description: "Versão menor do CodeLlama, mais rápida",
+		Size:        "~4GB",
+		BestFor:     "Desenvolvimento rápido, recursos limitados",
+	},
+	{
+		Name:        "codellama:13b",
+		Description: "Versão maior do CodeLlama, mais precisa",
+		Size:        "~8GB",
+		BestFor:     "Análises complexas, alta precisão",
+	},
+	{
+		Name:        "llama2",
+		Description: "Modelo geral, bom para código e texto",
+		Size:        "~4GB",
+		BestFor:     "Uso geral, documentação",
+	},
+	{
+		Name:        "gpt-3.5-turbo",
+		Description: "Modelo OpenAI (requer API key)",
+		Size:        "N/A",
+		BestFor:     "Alta qualidade, uso comercial",
+	},
 }
 
 func runListModels(cmd *cobra.Command, args []string) {
-	fmt.Println("🤖 Modelos de IA Recomendados")
+	config := newConfig()
+	if listModelsTimeout > 0 {
+		config.Timeout = time.Duration(listModelsTimeout) * time.Second
+	}
+
+	models, err := openai.ListModels(cmd.Context(), config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Não foi possível consultar os modelos em %s: %v\n", openai.BaseURL(apiURL), err)
+		fmt.Fprintln(os.Stderr, "   Mostrando a lista de modelos recomendados.")
+		fmt.Println()
+		printRecommendedModels()
+		return
+	}
+
+	fmt.Printf("🤖 Modelos Disponíveis em %s\n", openai.BaseURL(apiURL))
 	fmt.Println(strings.Repeat("=", 40))
 	fmt.Println()
 
-	models := []struct {
-		Name        string
-		Description string
-		Size        string
-		BestFor     string
-	}{
-		{
-			Name:        "codellama",
-			Description: "Modelo especializado em código, baseado no Llama 2",
-			Size:        "~4GB",
-			BestFor:     "Explicação de código, análise de algoritmos",
-		},
-		{
-			Name:        "codellama:7b",
-			Description: "Versão menor do CodeLlama, mais rápida",
-			Size:        "~4GB",
-			BestFor:     "Desenvolvimento rápido, recursos limitados",
-		},
-		{
-			Name:        "codellama:13b",
-			Description: "Versão maior do CodeLlama, mais precisa",
-			Size:        "~8GB",
-			BestFor:     "Análises complexas, alta precisão",
-		},
-		{
-			Name:        "llama2",
-			Description: "Modelo geral, bom para código e texto",
-			Size:        "~4GB",
-			BestFor:     "Uso geral, documentação",
-		},
-		{
-			Name:        "gpt-3.5-turbo",
-			Description: "Modelo OpenAI (requer API key)",
-			Size:        "N/A",
-			BestFor:     "Alta qualidade, uso comercial",
-		},
+	if len(models) == 0 {
+		fmt.Println("Nenhum modelo instalado.")
+		fmt.Println()
+		fmt.Println("📥 **Instalar:** ollama pull codellama")
+		return
 	}
 
 	for i, model := range models {
+		marks := ""
+		if model.Recommended {
+			marks += " ⭐"
+		}
+		if model.Name == modelName || strings.TrimSuffix(model.Name, ":latest") == modelName {
+			marks += " (atual)"
+		}
+		fmt.Printf("%d. **%s**%s\n", i+1, model.Name, marks)
+
+		var details []string
+		if model.Size > 0 {
+			details = append(details, openai.FormatSize(model.Size))
+		}
+		for _, d := range []string{model.Family, model.ParameterSize, model.Quantization} {
+			if d != "" {
+				details = append(details, d)
+			}
+		}
+		if len(details) > 0 {
+			fmt.Printf("   %s\n", strings.Join(details, " · "))
+		}
+		if !model.ModifiedAt.IsZero() {
+			fmt.Printf("   Modificado em: %s\n", model.ModifiedAt.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("⭐ Modelo especializado em código (recomendado)")
+	fmt.Println("💡 **Dica:** Use --model para escolher um dos modelos acima")
+}
+
+// printRecommendedModels mostra a lista estática de modelos, usada quando o servidor está inacessível
+func printRecommendedModels() {
+	fmt.Println("🤖 Modelos de IA Recomendados")
+	fmt.Println(strings.Repeat("=", 40))
+	fmt.Println()
+
+	for i, model := range recommendedModels {
 		fmt.Printf("%d. **%s** (%s)\n", i+1, model.Name, model.Size)
 		fmt.Printf("   %s\n", model.Description)
 		fmt.Printf("   Melhor para: %s\n", model.BestFor)
@@ -149,8 +218,15 @@ func runListConfig(cmd *cobra.Command, args []string) {
 	fmt.Printf("   API URL: %s\n", apiURL)
 	fmt.Printf("   Timeout: %ds\n", timeout)
 	fmt.Printf("   Verbose: %t\n", verbose)
+	fmt.Printf("   Logs: %s (%s)\n", logFormat, logLevel)
+	if traceEndpoint != "" || traceFile != "" {
+		fmt.Printf("   Rastreamento: %s\n", strings.Trim(traceEndpoint+" "+traceFile, " "))
+	}
 	fmt.Printf("   Output: %s\n", getOutputDisplay())
 	fmt.Printf("   Language: %s\n", getLanguageDisplay())
+	if loadedConfig != nil {
+		fmt.Printf("   Arquivo de configuração: %s\n", loadedConfig.Path)
+	}
 	fmt.Println()
 
 	fmt.Println("💡 **Dicas:**")
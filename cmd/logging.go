@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/mvcbotelho/code-explainer/logging"
+	"github.com/mvcbotelho/code-explainer/server"
+)
+
+var (
+	logFormat string
+	logLevel  string
+
+	// serveMetrics é definido pelo comando serve para contabilizar o uso do cache
+	serveMetrics *server.Metrics
+)
+
+// setupLogging configura o logger padrão (slog) em stderr. --verbose equivale a --log-level debug.
+func setupLogging() error {
+	level := logLevel
+	if verbose {
+		level = "debug"
+	}
+
+	logger, err := logging.New(os.Stderr, logFormat, level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// observeCache registra uma consulta ao cache nas métricas do servidor, se ativo
+func observeCache(hit bool) {
+	if serveMetrics != nil {
+		serveMetrics.CacheLookup(hit)
+	}
+}
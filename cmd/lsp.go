@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/mvcbotelho/code-explainer/lsp"
+	"github.com/spf13/cobra"
+)
+
+// lspCmd representa o comando lsp
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Inicia o servidor LSP para integração com editores",
+	Long: `Inicia um servidor Language Server Protocol sobre stdio.
+
+Recursos oferecidos ao editor:
+• Hover: explica o bloco de nível superior sob o cursor
+• Ação de código "Explicar seleção" (ou "Explicar bloco", sem seleção)
+
+A linguagem do documento (languageId) é usada como dica para a detecção.
+As explicações rodam em segundo plano com indicador de progresso ($/progress)
+e podem ser canceladas pelo editor ($/cancelRequest). Usa o cache e o
+histórico locais.
+
+Exemplo de configuração no Neovim:
+  vim.lsp.start({ name = "code-explainer", cmd = { "code-explainer", "lsp" } })`,
+	Args: cobra.NoArgs,
+	RunE: runLSP,
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
+
+func runLSP(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	// stdout é o canal do protocolo; os logs vão para stderr
+	slog.Debug("servidor LSP iniciado", "model", modelName)
+
+	server := lsp.New(newConfig(), explainCode, rootCmd.Version)
+	return server.Run(cmd.Context(), os.Stdin, os.Stdout)
+}
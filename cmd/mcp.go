@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/mcp"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/spf13/cobra"
+)
+
+// mcpCmd representa o comando mcp
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Inicia o servidor MCP (Model Context Protocol) para agentes",
+	Long: `Inicia um servidor Model Context Protocol sobre stdio, uma mensagem JSON por linha.
+
+Ferramentas expostas:
+• explain_code    Explica um trecho de código (code, language, model, level, timeout)
+• detect_language Detecta a linguagem de um trecho de código (code, filename)
+• list_languages  Lista as linguagens e níveis de detalhamento suportados
+
+Os argumentos seguem as flags da CLI, e os padrões vêm das flags globais usadas
+ao iniciar o servidor. Com MODEL_NAME definida, o modelo é fixo e outro valor de
+model é recusado. As explicações usam o cache e o histórico locais.
+
+Exemplo de configuração em um cliente MCP:
+  { "mcpServers": { "code-explainer": { "command": "code-explainer", "args": ["mcp"] } } }`,
+	Args: cobra.NoArgs,
+	RunE: runMCP,
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	// stdout é o canal do protocolo; os logs vão para stderr
+	slog.Debug("servidor MCP iniciado", "model", modelName)
+
+	server := mcp.New("code-explainer", rootCmd.Version)
+	registerMCPTools(server, newConfig())
+	return server.Run(cmd.Context(), os.Stdin, os.Stdout)
+}
+
+// registerMCPTools registra as ferramentas com schemas derivados das flags da CLI
+func registerMCPTools(server *mcp.Server, defaults *openai.Config) {
+	levelProp := flagProperty("level")
+	levelProp.Enum = openai.GetPromptLevels()
+	modelProp := flagProperty("model")
+	if fixed := openai.ModelOverride(); fixed != "" {
+		modelProp.Default = fixed
+		modelProp.Description += fmt.Sprintf(" (fixado em %s por MODEL_NAME; outro valor é recusado)", fixed)
+	}
+
+	server.AddTool(mcp.Tool{
+		Name:        "explain_code",
+		Description: "Explica um trecho de código em português usando o modelo local",
+		InputSchema: mcp.Schema{
+			Properties: map[string]mcp.Property{
+				"code":     {Type: "string", Description: "Código a ser explicado"},
+				"language": flagProperty("language"),
+				"model":    modelProp,
+				"level":    levelProp,
+				"timeout":  flagProperty("timeout"),
+			},
+			Required: []string{"code"},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		code := mcp.String(args, "code")
+		if strings.TrimSpace(code) == "" {
+			return "", fmt.Errorf("o argumento code não pode ser vazio")
+		}
+		config, err := mcpExplainConfig(defaults, args)
+		if err != nil {
+			return "", err
+		}
+		return explainCode(ctx, code, config)
+	})
+
+	server.AddTool(mcp.Tool{
+		Name:        "detect_language",
+		Description: "Detecta a linguagem de programação de um trecho de código",
+		InputSchema: mcp.Schema{
+			Properties: map[string]mcp.Property{
+				"code":     {Type: "string", Description: "Código a ser analisado"},
+				"filename": {Type: "string", Description: "Nome do arquivo; se informado, a extensão tem prioridade"},
+			},
+		},
+	}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		code, filename := mcp.String(args, "code"), mcp.String(args, "filename")
+		if strings.TrimSpace(code) == "" && filename == "" {
+			return "", fmt.Errorf("informe code ou filename")
+		}
+
+		lang := ""
+		if filename != "" {
+			lang = openai.LanguageFromFilename(filename)
+		}
+		if lang == "" {
+			lang = openai.DetectLanguage(code)
+		}
+		return lang, nil
+	})
+
+	server.AddTool(mcp.Tool{
+		Name:        "list_languages",
+		Description: "Lista as linguagens e os níveis de detalhamento suportados",
+	}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		return fmt.Sprintf("Linguagens: %s\nNíveis: %s",
+			strings.Join(openai.GetSupportedLanguages(), ", "),
+			strings.Join(openai.GetPromptLevels(), ", ")), nil
+	})
+}
+
+// mcpExplainConfig valida os argumentos de explain_code e monta a configuração a partir
+// dos padrões. Os erros viram um resultado com isError antes de qualquer chamada ao modelo.
+func mcpExplainConfig(defaults *openai.Config, args map[string]interface{}) (*openai.Config, error) {
+	config := *defaults
+	if model := mcp.String(args, "model"); model != "" {
+		if fixed := openai.ModelOverride(); fixed != "" && model != fixed {
+			return nil, fmt.Errorf("o modelo é fixado por MODEL_NAME (%s); omita o argumento model", fixed)
+		}
+		config.Model = model
+	}
+	if lvl := mcp.String(args, "level"); lvl != "" {
+		if err := openai.ValidateLevel(lvl); err != nil {
+			return nil, err
+		}
+		config.Level = lvl
+	}
+	if lang := mcp.String(args, "language"); lang != "" {
+		config.Language = lang
+	}
+	t := mcp.Int(args, "timeout")
+	if t < 0 {
+		return nil, fmt.Errorf("o argumento timeout deve ser maior que zero")
+	}
+	if t > 0 {
+		config.Timeout = time.Duration(t) * time.Second
+	}
+	config.Model = openai.EffectiveModel(&config)
+	return &config, nil
+}
+
+// flagProperty converte uma flag global em propriedade do JSON Schema,
+// reaproveitando a descrição e o valor padrão
+func flagProperty(name string) mcp.Property {
+	flag := rootCmd.PersistentFlags().Lookup(name)
+	prop := mcp.Property{Type: "string", Description: flag.Usage}
+
+	switch flag.Value.Type() {
+	case "int":
+		prop.Type = "integer"
+		if n, err := strconv.Atoi(flag.Value.String()); err == nil && n != 0 {
+			prop.Default = n
+		}
+	case "bool":
+		prop.Type = "boolean"
+	default:
+		if v := flag.Value.String(); v != "" {
+			prop.Default = v
+		}
+	}
+	return prop
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+func TestMCPExplainConfig(t *testing.T) {
+	defaults := &openai.Config{Model: "codellama", Level: "intermediario"}
+
+	tests := []struct {
+		name    string
+		env     string
+		args    map[string]interface{}
+		model   string
+		level   string
+		wantErr bool
+	}{
+		{name: "Padrões", args: map[string]interface{}{}, model: "codellama", level: "intermediario"},
+		{name: "Modelo e nível", args: map[string]interface{}{"model": "llama3", "level": "basico"}, model: "llama3", level: "basico"},
+		{name: "Nível inválido", args: map[string]interface{}{"level": "expert"}, wantErr: true},
+		{name: "Timeout negativo", args: map[string]interface{}{"timeout": -1.0}, wantErr: true},
+		{name: "MODEL_NAME é o modelo usado", env: "phi3", args: map[string]interface{}{}, model: "phi3", level: "intermediario"},
+		{name: "Mesmo modelo de MODEL_NAME", env: "phi3", args: map[string]interface{}{"model": "phi3"}, model: "phi3", level: "intermediario"},
+		{name: "Modelo sobreposto por MODEL_NAME", env: "phi3", args: map[string]interface{}{"model": "llama3"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MODEL_NAME", tt.env)
+			config, err := mcpExplainConfig(defaults, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mcpExplainConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if config.Model != tt.model || config.Level != tt.level {
+				t.Errorf("mcpExplainConfig() = model %q, level %q; want %q, %q", config.Model, config.Level, tt.model, tt.level)
+			}
+		})
+	}
+}
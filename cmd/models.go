@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/spf13/cobra"
+)
+
+var (
+	warmKeepAlive   string
+	warmLoadTimeout int
+)
+
+// modelsCmd representa o comando models
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "Baixa e pré-carrega modelos no servidor Ollama",
+	Long: `Gerencia os modelos do servidor Ollama configurado (--api-url).
+
+Subcomandos:
+  pull <nome>   - Baixa um modelo, mostrando o progresso
+  warm [nome]   - Carrega o modelo em memória antes de um lote
+
+Para listar os modelos instalados, use 'code-explainer list models'.
+
+Exemplos:
+  code-explainer models pull codellama:7b
+  code-explainer models warm --keep-alive 1h
+  code-explainer models warm && code-explainer batch --input requests.jsonl`,
+}
+
+// modelsPullCmd baixa um modelo
+var modelsPullCmd = &cobra.Command{
+	Use:   "pull <nome>",
+	Short: "Baixa um modelo via /api/pull",
+	Long: `Baixa um modelo via /api/pull do Ollama, mostrando uma barra de progresso.
+
+O download não tem limite de tempo (--timeout é ignorado); use Ctrl+C para interrompê-lo.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runModelsPull,
+}
+
+// modelsWarmCmd pré-carrega um modelo
+var modelsWarmCmd = &cobra.Command{
+	Use:   "warm [nome]",
+	Short: "Carrega o modelo em memória (padrão: --model)",
+	Long: `Carrega o modelo em memória e o mantém carregado por --keep-alive.
+
+O primeiro uso de um modelo pode levar mais de um minuto enquanto ele é
+carregado, estourando o --timeout das explicações. Rode este comando antes
+de um lote para que as requisições encontrem o modelo já carregado.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runModelsWarm,
+}
+
+func init() {
+	rootCmd.AddCommand(modelsCmd)
+	modelsCmd.AddCommand(modelsPullCmd)
+	modelsCmd.AddCommand(modelsWarmCmd)
+
+	modelsWarmCmd.Flags().StringVar(&warmKeepAlive, "keep-alive", "30m", "Por quanto tempo manter o modelo carregado (ex.: 30m, 1h, -1 para sempre)")
+	modelsWarmCmd.Flags().IntVar(&warmLoadTimeout, "load-timeout", 300, "Timeout em segundos para carregar o modelo")
+}
+
+func runModelsPull(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	name := args[0]
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	config := newConfig()
+	config.Timeout = 0
+
+	fmt.Printf("📥 Baixando %s de %s\n", name, openai.BaseURL(apiURL))
+
+	bar := &progressBar{}
+	err := openai.Pull(ctx, name, config, bar.update)
+	bar.finish()
+	if err != nil {
+		return fmt.Errorf("erro ao baixar modelo %s: %w", name, err)
+	}
+
+	fmt.Printf("✅ Modelo %s pronto para uso\n", name)
+	return nil
+}
+
+func runModelsWarm(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	config := newConfig()
+	name := config.Model
+	if len(args) > 0 {
+		name = args[0]
+	}
+	config.Timeout = time.Duration(warmLoadTimeout) * time.Second
+
+	fmt.Printf("🔥 Carregando %s (keep-alive: %s)...\n", name, warmKeepAlive)
+
+	start := time.Now()
+	if err := openai.Warm(cmd.Context(), name, warmKeepAlive, config); err != nil {
+		return fmt.Errorf("erro ao carregar modelo %s: %w", name, err)
+	}
+
+	fmt.Printf("✅ Modelo %s carregado em %s\n", name, time.Since(start).Round(100*time.Millisecond))
+	return nil
+}
+
+// progressBar desenha o progresso do download em stderr, uma linha por etapa
+type progressBar struct {
+	status string
+	inline bool // A linha atual é uma barra que será redesenhada
+}
+
+// update redesenha a barra ou inicia uma nova linha quando a etapa muda
+func (b *progressBar) update(p openai.PullProgress) {
+	if p.Status != b.status {
+		b.finish()
+		b.status = p.Status
+		if p.Total == 0 {
+			fmt.Fprintf(os.Stderr, "   %s\n", p.Status)
+			return
+		}
+	}
+	if p.Total == 0 {
+		return
+	}
+
+	const width = 30
+	filled := int(float64(width) * float64(p.Completed) / float64(p.Total))
+	if filled > width {
+		filled = width
+	}
+	completed := "0 B"
+	if p.Completed > 0 {
+		completed = openai.FormatSize(p.Completed)
+	}
+	fmt.Fprintf(os.Stderr, "\r   %s [%s%s] %3d%% %s/%s",
+		shortStatus(p.Status), strings.Repeat("█", filled), strings.Repeat("░", width-filled),
+		p.Completed*100/p.Total, completed, openai.FormatSize(p.Total))
+	b.inline = true
+}
+
+// finish encerra a linha da barra atual
+func (b *progressBar) finish() {
+	if b.inline {
+		fmt.Fprintln(os.Stderr)
+		b.inline = false
+	}
+}
+
+// shortStatus encurta o digest nas mensagens "pulling sha256:..."
+func shortStatus(status string) string {
+	if len(status) > 24 {
+		return status[:24] + "…"
+	}
+	return status
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/mvcbotelho/code-explainer/config"
+	"github.com/mvcbotelho/code-explainer/policy"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// policyGuard verifica as URLs da API contra a política do arquivo de configuração;
+	// nil se nenhuma política estiver ativa
+	policyGuard func(url string) error
+
+	// repoConfig é o .code-explainer.yaml do repositório, quando outro arquivo foi carregado
+	// (com --config ou do diretório do usuário). Sua política continua valendo.
+	repoConfig *config.File
+)
+
+// currentPolicy retorna a política do arquivo de configuração combinada com a do
+// repositório, no sentido mais restritivo
+func currentPolicy() policy.Policy {
+	var policies []policy.Policy
+	for _, f := range []*config.File{loadedConfig, repoConfig} {
+		if f != nil {
+			policies = append(policies, policy.Policy{
+				AllowRemote:  f.Policy.AllowRemote,
+				AllowedHosts: f.Policy.AllowedHosts,
+				Source:       f.Path,
+			})
+		}
+	}
+	return policy.Merge(policies...)
+}
+
+// loadRepoConfig carrega o arquivo do repositório, a partir do diretório atual, se ele
+// não for o arquivo já carregado por applyConfigFile
+func loadRepoConfig() error {
+	repoConfig = nil
+
+	path, err := config.FindRepo(".")
+	if err != nil || path == "" {
+		return err
+	}
+	if loadedConfig != nil && samePath(loadedConfig.Path, path) {
+		return nil
+	}
+
+	f, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	repoConfig = f
+	return nil
+}
+
+// samePath informa se os dois caminhos apontam para o mesmo arquivo
+func samePath(a, b string) bool {
+	ia, errA := os.Stat(a)
+	ib, errB := os.Stat(b)
+	if errA == nil && errB == nil {
+		return os.SameFile(ia, ib)
+	}
+	absA, _ := filepath.Abs(a)
+	absB, _ := filepath.Abs(b)
+	return absA == absB
+}
+
+// setupPolicy prepara o guard da política para o comando em execução
+func setupPolicy(cmd *cobra.Command) error {
+	if err := loadRepoConfig(); err != nil {
+		return err
+	}
+
+	p := currentPolicy()
+	if !p.Active() {
+		policyGuard = nil
+		return nil
+	}
+
+	// O log de auditoria do arquivo carregado tem prioridade sobre o do repositório
+	var path string
+	for _, f := range []*config.File{loadedConfig, repoConfig} {
+		if f != nil && path == "" {
+			path = f.Policy.AuditLog
+		}
+	}
+	if path == "" {
+		path, _ = policy.DefaultAuditPath()
+	}
+	var audit *policy.AuditLog
+	if path != "" {
+		audit = &policy.AuditLog{Path: os.ExpandEnv(path)}
+	}
+	policyGuard = policy.Guard(p, audit, cmd.CommandPath())
+	return nil
+}
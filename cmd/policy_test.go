@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mvcbotelho/code-explainer/config"
+	"github.com/mvcbotelho/code-explainer/policy"
+)
+
+func TestCurrentPolicy(t *testing.T) {
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	nested := filepath.Join(repo, "internal", "app")
+	for _, d := range []string{filepath.Join(repo, ".git"), nested} {
+		os.MkdirAll(d, 0o755)
+	}
+	os.WriteFile(filepath.Join(repo, config.FileName), []byte("policy:\n  allow_remote: false\n"), 0o644)
+
+	explicit := filepath.Join(dir, "permissivo.yaml")
+	os.WriteFile(explicit, []byte("policy:\n  allow_remote: true\n"), 0o644)
+	restrictive := filepath.Join(dir, "restritivo.yaml")
+	os.WriteFile(restrictive, []byte("policy:\n  allowed_hosts: [ollama.interno:11434]\n"), 0o644)
+
+	tests := []struct {
+		name    string
+		dir     string
+		config  string
+		url     string
+		blocked bool
+	}{
+		{name: "Subdiretório do repositório", dir: nested, url: "https://api.openai.com/v1", blocked: true},
+		{name: "--config permissivo não relaxa o repositório", dir: nested, config: explicit, url: "https://api.openai.com/v1", blocked: true},
+		{name: "--config restritivo também se aplica", dir: nested, config: restrictive, url: "http://ollama.interno:11434/api/generate", blocked: true},
+		{name: "Local permitido", dir: nested, config: restrictive, url: "http://localhost:11434/api/generate"},
+		{name: "Fora do repositório", dir: dir, config: explicit, url: "https://api.openai.com/v1"},
+	}
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := saveGlobals()
+			defer restore()
+			defer func() { repoConfig = nil }()
+
+			if err := os.Chdir(tt.dir); err != nil {
+				t.Fatal(err)
+			}
+			loadedConfig = nil
+			if tt.config != "" {
+				f, err := config.Load(tt.config)
+				if err != nil {
+					t.Fatal(err)
+				}
+				loadedConfig = f
+			}
+
+			if err := loadRepoConfig(); err != nil {
+				t.Fatalf("loadRepoConfig() error = %v", err)
+			}
+			err := currentPolicy().Check(tt.url)
+			if blocked := errors.Is(err, policy.ErrBlocked); blocked != tt.blocked {
+				t.Errorf("Check(%q) = %v, want bloqueado = %v", tt.url, err, tt.blocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/mvcbotelho/code-explainer/redact"
+	"github.com/mvcbotelho/code-explainer/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	noRedact        bool
+	restoreRedacted bool
+
+	redactorOnce sync.Once
+	redactor     *redact.Redactor
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noRedact, "no-redact", false, "Envia o código sem ocultar segredos e dados pessoais")
+	rootCmd.PersistentFlags().BoolVar(&restoreRedacted, "restore-redacted", false, "Restaura na resposta os valores ocultados antes do envio")
+}
+
+// newRedactor monta o Redactor com as regras padrão e as do arquivo de configuração,
+// ou retorna nil se a redação estiver desativada
+func newRedactor() *redact.Redactor {
+	redactorOnce.Do(func() {
+		if noRedact || (loadedConfig != nil && loadedConfig.Redact.Disabled) {
+			return
+		}
+
+		var extra []redact.Rule
+		if loadedConfig != nil {
+			for _, p := range loadedConfig.Redact.Patterns {
+				// As expressões já foram validadas ao carregar o arquivo
+				if rule, err := redact.NewRule(p.Name, p.Regex); err == nil {
+					extra = append(extra, rule)
+				}
+			}
+		}
+		redactor = redact.New(extra...)
+	})
+	return redactor
+}
+
+// redactCode oculta os dados sensíveis do texto e registra no log o que foi ocultado
+func redactCode(ctx context.Context, text string) *redact.Result {
+	return redactTurn(ctx, nil, text)
+}
+
+// redactTurn oculta os dados sensíveis de uma nova mensagem de uma conversa,
+// mantendo os placeholders das mensagens anteriores (prev)
+func redactTurn(ctx context.Context, prev *redact.Result, text string) *redact.Result {
+	r := newRedactor()
+	if r == nil {
+		return &redact.Result{Text: text}
+	}
+
+	_, span := tracing.Start(ctx, "redact")
+	res := r.Extend(prev, text)
+	span.SetAttributes(attribute.Bool("redact.redacted", res.Redacted()))
+	span.End()
+
+	if res.Redacted() && (prev == nil || res.Summary() != prev.Summary()) {
+		slog.DebugContext(ctx, "dados sensíveis ocultados antes do envio", "summary", res.Summary())
+	}
+	return res
+}
+
+// restoreOutput devolve os valores originais na resposta, se --restore-redacted
+// (ou redact.restore no arquivo de configuração) estiver ativo
+func restoreOutput(res *redact.Result, text string) string {
+	if restoreRedacted || (loadedConfig != nil && loadedConfig.Redact.Restore) {
+		return res.Restore(text)
+	}
+	return text
+}
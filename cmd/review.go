@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mvcbotelho/code-explainer/gitdiff"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/review"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewFiles  []string
+	reviewDiff   bool
+	reviewPatch  string
+	reviewFormat string
+	reviewFailOn string
+)
+
+// reviewCmd representa o comando review
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Revisa código e aponta problemas com severidade",
+	Long: `Revisa arquivos ou diffs usando IA local e converte a resposta do modelo em
+achados estruturados: arquivo, linha, severidade, categoria, mensagem e sugestão.
+
+Fontes de código:
+  --file x.go         revisa o arquivo inteiro (pode ser repetido)
+  --diff [args...]    revisa as alterações de git diff (argumentos repassados ao git)
+  --patch fix.patch   revisa um arquivo de patch ("-" para entrada padrão)
+
+Formatos de saída (--format): text, json, sarif. O formato SARIF pode ser
+enviado a painéis de code scanning.
+
+Severidades: info, low, medium, high, critical. Se algum achado tiver
+severidade igual ou maior que --fail-on, o comando termina com código
+20 + nível da maior severidade (info=20, low=21, medium=22, high=23,
+critical=24). Use --fail-on none para sempre terminar com sucesso.
+
+Exemplos:
+  code-explainer review --file main.go
+  code-explainer review --file main.go --file util.go --format json
+  code-explainer review --diff --staged --fail-on medium
+  code-explainer review --diff main...HEAD --format sarif --output review.sarif`,
+	RunE: runReview,
+}
+
+func init() {
+	rootCmd.AddCommand(reviewCmd)
+
+	reviewCmd.Flags().StringArrayVarP(&reviewFiles, "file", "f", nil, "Arquivo a ser revisado (pode ser repetido)")
+	reviewCmd.Flags().BoolVar(&reviewDiff, "diff", false, "Revisa as alterações de git diff")
+	reviewCmd.Flags().StringVarP(&reviewPatch, "patch", "p", "", "Arquivo de patch a ser revisado (\"-\" para entrada padrão)")
+	reviewCmd.Flags().StringVar(&reviewFormat, "format", review.FormatText, "Formato de saída: text, json ou sarif")
+	reviewCmd.Flags().StringVar(&reviewFailOn, "fail-on", review.SeverityHigh, "Severidade mínima que faz o comando falhar (ou none)")
+
+	reviewCmd.MarkFlagsMutuallyExclusive("file", "diff", "patch")
+	reviewCmd.MarkFlagsOneRequired("file", "diff", "patch")
+}
+
+// reviewTarget é uma unidade enviada ao modelo: um arquivo ou as alterações de um arquivo
+type reviewTarget struct {
+	file   string
+	prompt string
+	source promptSource // Registrado no histórico
+}
+
+func runReview(cmd *cobra.Command, args []string) error {
+	if err := review.ValidateFailOn(reviewFailOn); err != nil {
+		return err
+	}
+	if err := review.ValidateFormat(reviewFormat); err != nil {
+		return err
+	}
+	if len(args) > 0 && !reviewDiff {
+		return fmt.Errorf("argumentos extras só são aceitos com --diff")
+	}
+
+	// A partir daqui os erros não são de uso; não exibir a ajuda
+	cmd.SilenceUsage = true
+
+	targets, err := collectReviewTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("nenhuma alteração de texto encontrada para revisar")
+	}
+
+	config := newConfig()
+	jobs := make([]openai.Job, len(targets))
+	sources := make(map[string]promptSource, len(targets))
+	for i, t := range targets {
+		jobs[i] = openai.Job{ID: t.file, Code: t.prompt, Config: config}
+		sources[t.prompt] = t.source
+	}
+
+	slog.Debug("revisando arquivos", "files", len(targets), "model", config.Model)
+
+	results := openai.ExplainAll(cmd.Context(), jobs, concurrency, promptFunc("review", sources))
+
+	var findings []review.Finding
+	failures := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failures++
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", r.ID, r.Err)
+			continue
+		}
+		parsed, err := review.ParseFindings(r.Explanation, r.ID)
+		if err != nil {
+			failures++
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", r.ID, err)
+			continue
+		}
+		findings = append(findings, parsed...)
+	}
+	review.Sort(findings)
+
+	var out strings.Builder
+	if err := review.Render(&out, findings, reviewFormat, rootCmd.Version); err != nil {
+		return err
+	}
+	if err := writeOutput(out.String()); err != nil {
+		return err
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d de %d arquivo(s) não puderam ser revisados", failures, len(results))
+	}
+
+	if code := review.ExitCode(findings, reviewFailOn); code != 0 {
+		return &exitError{
+			code: code,
+			err:  fmt.Errorf("revisão encontrou achados com severidade %s (limite: %s)", review.MaxSeverity(findings), reviewFailOn),
+		}
+	}
+
+	return nil
+}
+
+// collectReviewTargets monta os prompts de revisão a partir de --file, --diff ou --patch
+func collectReviewTargets(cmd *cobra.Command, args []string) ([]reviewTarget, error) {
+	if len(reviewFiles) > 0 {
+		targets := make([]reviewTarget, 0, len(reviewFiles))
+		for _, path := range reviewFiles {
+			code, err := readFile(path)
+			if err != nil {
+				return nil, inputError(fmt.Errorf("erro ao ler arquivo %s: %w", path, err))
+			}
+			lang := language
+			if lang == "" {
+				lang = openai.LanguageFromFilename(path)
+			}
+			if lang == "" {
+				lang = openai.DetectLanguage(code)
+			}
+			targets = append(targets, reviewTarget{
+				file:   path,
+				prompt: review.BuildPrompt(code, path, lang, level),
+				source: promptSource{command: "review", code: code, language: lang},
+			})
+		}
+		return targets, nil
+	}
+
+	var files []gitdiff.File
+	var err error
+	switch {
+	case reviewPatch == "-":
+		files, err = gitdiff.Parse(os.Stdin)
+	case reviewPatch != "":
+		var f *os.File
+		f, err = os.Open(reviewPatch)
+		if err != nil {
+			return nil, inputError(fmt.Errorf("erro ao ler patch %s: %w", reviewPatch, err))
+		}
+		defer f.Close()
+		files, err = gitdiff.Parse(f)
+	default:
+		files, err = gitdiff.Diff(cmd.Context(), "", args...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao interpretar diff: %w", err)
+	}
+
+	var targets []reviewTarget
+	for _, f := range files {
+		if f.Binary || f.Deleted || len(f.Hunks) == 0 {
+			continue
+		}
+		targets = append(targets, reviewTarget{
+			file:   f.Path(),
+			prompt: review.BuildDiffPrompt(f, level),
+			source: promptSource{command: "review", code: f.Text(), language: f.Language()},
+		})
+	}
+	return targets, nil
+}
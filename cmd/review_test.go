@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectReviewTargetsMissingFile(t *testing.T) {
+	old := reviewFiles
+	defer func() { reviewFiles = old }()
+	reviewFiles = []string{filepath.Join(t.TempDir(), "nao-existe.go")}
+
+	_, err := collectReviewTargets(reviewCmd, nil)
+	if err == nil {
+		t.Fatal("Expected an error for a missing file")
+	}
+	if code, _ := exitCode(err, true); code != exitInput {
+		t.Errorf("exitCode() = %d, want %d (%v)", code, exitInput, err)
+	}
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"runtime/debug"
+	"time"
 
+	"github.com/mvcbotelho/code-explainer/config"
+	"github.com/mvcbotelho/code-explainer/openai"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +20,16 @@ var (
 	verbose   bool
 	output    string
 	language  string
+	level     string
+
+	concurrency int
+
+	configPath  string
+	errorFormat string
+	// commandStarted indica que as flags foram validadas e o comando começou a executar
+	commandStarted bool
+	// loadedConfig é o arquivo de configuração aplicado, ou nil se nenhum foi encontrado
+	loadedConfig *config.File
 )
 
 // rootCmd representa o comando base quando chamado sem subcomandos
@@ -35,16 +50,122 @@ Exemplos:
   code-explainer explain --file main.go
   code-explainer explain --code "func main() { fmt.Println('Hello') }"
   code-explainer detect --file script.py
+  code-explainer batch --input requests.jsonl --output results.jsonl
   code-explainer list-models`,
 	Version: "1.0.0",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupCommand(cmd, nil); err != nil {
+			return err
+		}
+		commandStarted = true
+		return nil
+	},
+}
+
+// setupErrors guarda os erros de configuração que o doctor reporta como verificações
+type setupErrors struct {
+	config error // Arquivo de configuração inválido
+	level  error // Nível de detalhamento inválido
+	policy error // Política do repositório inválida
+}
+
+// setupCommand prepara a execução de um comando: flags, arquivo de configuração, nível,
+// logs, rastreamento e política. Se errs for nil, os erros de configuração interrompem a
+// preparação como erros de uso; caso contrário, são guardados em errs e ela continua.
+func setupCommand(cmd *cobra.Command, errs *setupErrors) error {
+	fatal := errs == nil
+	if fatal {
+		errs = &setupErrors{}
+	}
+	// collect guarda err em field e, se os erros de configuração forem fatais, o retorna
+	collect := func(field *error, err error) error {
+		*field = err
+		if fatal && err != nil {
+			return usageError(err)
+		}
+		return nil
+	}
+
+	if err := validateFlags(cmd); err != nil {
+		return err
+	}
+	if err := collect(&errs.config, applyConfigFile(cmd)); err != nil {
+		return err
+	}
+	if err := collect(&errs.level, openai.ValidateLevel(level)); err != nil {
+		return err
+	}
+	if err := setupLogging(); err != nil {
+		return usageError(err)
+	}
+	if err := setupTracing(cmd); err != nil {
+		return usageError(err)
+	}
+	return collect(&errs.policy, setupPolicy(cmd))
 }
 
-// Execute adiciona todos os comandos filhos ao comando root e define flags
+// exitError permite que um comando termine com um código de saída específico
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// Execute adiciona todos os comandos filhos ao comando root e define flags.
+// Erros são exibidos em stderr (como JSON com --format json) e definem o código de saída.
 func Execute() {
-	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	// A mensagem de erro e o uso são exibidos aqui, para respeitar --format
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+
+	var cmd *cobra.Command
+	defer func() {
+		if r := recover(); r != nil {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "%s\n", debug.Stack())
+			}
+			printError(os.Stderr, fmt.Errorf("erro interno: %v", r), exitInternal,
+				"Reporte o problema com a saída de --verbose", errorFormatJSON(cmd))
+			os.Exit(exitInternal)
+		}
+	}()
+
+	cmd, err := rootCmd.ExecuteC()
+	finishTracing(err)
+	if err == nil {
+		return
+	}
+
+	code, hint := exitCode(err, commandStarted)
+	asJSON := errorFormatJSON(cmd)
+
+	// Erros de flags e argumentos detectados pelo cobra mostram o uso do comando
+	var exitErr *exitError
+	if !commandStarted && !asJSON && !errors.As(err, &exitErr) {
+		fmt.Fprintln(os.Stderr, cmd.UsageString())
+	}
+
+	printError(os.Stderr, err, code, hint, asJSON)
+	os.Exit(code)
+}
+
+// errorFormatJSON informa se os erros devem ser emitidos como JSON. Comandos com
+// --format próprio (review, history export) também emitem erros em JSON com --format json.
+func errorFormatJSON(cmd *cobra.Command) bool {
+	if cmd == nil {
+		cmd = rootCmd
+	}
+	if f := cmd.Flags().Lookup("format"); f != nil {
+		return f.Value.String() == "json"
 	}
+	return errorFormat == "json"
 }
 
 func init() {
@@ -52,9 +173,86 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&modelName, "model", "m", getEnvOrDefault("MODEL_NAME", "codellama"), "Modelo de IA a ser usado")
 	rootCmd.PersistentFlags().StringVarP(&apiURL, "api-url", "u", getEnvOrDefault("OLLAMA_API_URL", "http://localhost:11434/api/generate"), "URL da API Ollama")
 	rootCmd.PersistentFlags().IntVarP(&timeout, "timeout", "t", getEnvIntOrDefault("REQUEST_TIMEOUT", 30), "Timeout em segundos para requisições")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Modo verboso")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Modo verboso (logs de depuração em stderr)")
 	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "", "Arquivo de saída (padrão: stdout)")
 	rootCmd.PersistentFlags().StringVarP(&language, "language", "l", "", "Forçar linguagem específica (opcional)")
+	rootCmd.PersistentFlags().StringVar(&level, "level", getEnvOrDefault("PROMPT_LEVEL", ""), "Nível de detalhamento da explicação (basico, intermediario, avancado)")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", os.Getenv("CODE_EXPLAINER_CONFIG"), "Arquivo de configuração YAML (padrão: "+config.FileName+" do repositório ou ~/.config/code-explainer/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "format", "text", "Formato das mensagens de erro em stderr: text ou json")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", getEnvOrDefault("LOG_FORMAT", "text"), "Formato dos logs em stderr: text ou json")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", getEnvOrDefault("LOG_LEVEL", "info"), "Nível dos logs: debug, info, warn ou error (--verbose equivale a debug)")
+	rootCmd.PersistentFlags().StringVar(&traceEndpoint, "trace-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "Endpoint OTLP/HTTP para exportar os spans (ex.: http://localhost:4318)")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace-file", os.Getenv("TRACE_FILE"), "Arquivo JSON onde os spans são gravados para inspeção local")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", getEnvIntOrDefault("REQUEST_CONCURRENCY", 1), "Número máximo de requisições simultâneas (modos diretório e lote)")
+}
+
+// validateFlags antecipa as validações de flags do cobra, para que falhem como erro de uso
+func validateFlags(cmd *cobra.Command) error {
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return err
+	}
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return err
+	}
+	if f := cmd.Flags().Lookup("format"); f == cmd.Root().PersistentFlags().Lookup("format") && errorFormat != "text" && errorFormat != "json" {
+		return fmt.Errorf("formato inválido: %s (use text ou json)", errorFormat)
+	}
+	return nil
+}
+
+// applyConfigFile carrega o arquivo de configuração e aplica seus valores às
+// flags globais. Flags passadas na linha de comando e variáveis de ambiente
+// têm prioridade sobre o arquivo.
+func applyConfigFile(cmd *cobra.Command) error {
+	path, err := config.Find(configPath)
+	if err != nil || path == "" {
+		return err
+	}
+
+	f, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	loadedConfig = f
+
+	flags := cmd.Flags()
+	unset := func(name, env string) bool {
+		return !flags.Changed(name) && (env == "" || os.Getenv(env) == "")
+	}
+
+	if f.Model != "" && unset("model", "MODEL_NAME") {
+		modelName = f.Model
+	}
+	if f.APIURL != "" && unset("api-url", "OLLAMA_API_URL") {
+		apiURL = f.APIURL
+	}
+	if f.Timeout > 0 && unset("timeout", "REQUEST_TIMEOUT") {
+		timeout = f.Timeout
+	}
+	if f.Level != "" && unset("level", "PROMPT_LEVEL") {
+		level = f.Level
+	}
+	if f.Language != "" && unset("language", "") {
+		language = f.Language
+	}
+	if f.Concurrency > 0 && unset("concurrency", "REQUEST_CONCURRENCY") {
+		concurrency = f.Concurrency
+	}
+
+	return nil
+}
+
+// newConfig monta a configuração do cliente a partir das flags globais
+func newConfig() *openai.Config {
+	return &openai.Config{
+		APIURL:   apiURL,
+		Model:    modelName,
+		Timeout:  time.Duration(timeout) * time.Second,
+		Language: language,
+		Level:    level,
+		Guard:    policyGuard,
+		OnUsage:  logUsage,
+	}
 }
 
 // getEnvOrDefault retorna o valor da variável de ambiente ou o valor padrão
@@ -74,3 +272,13 @@ func getEnvIntOrDefault(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvDurationOrDefault retorna a duração da variável de ambiente (ex.: "24h") ou o valor padrão
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mvcbotelho/code-explainer/config"
+	"github.com/spf13/cobra"
+)
+
+func TestApplyConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	data := "model: llama3\napi_url: http://ollama:11434/api/generate\ntimeout: 90\nlevel: avancado\nlanguage: Go\nconcurrency: 4\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		model    string
+		timeout  int
+		level    string
+		language string
+	}{
+		{name: "Arquivo sobre os padrões", model: "llama3", timeout: 90, level: "avancado", language: "Go"},
+		{name: "Flag sobre o arquivo", args: []string{"--model", "mistral", "--timeout", "10"}, model: "mistral", timeout: 10, level: "avancado", language: "Go"},
+		{name: "Variável de ambiente sobre o arquivo", env: map[string]string{"MODEL_NAME": "phi3", "PROMPT_LEVEL": "basico"}, model: "phi3", timeout: 90, level: "basico", language: "Go"},
+		{name: "Flag de linguagem", args: []string{"--language", "Python"}, model: "llama3", timeout: 90, level: "avancado", language: "Python"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := saveGlobals()
+			defer restore()
+
+			for _, env := range []string{"MODEL_NAME", "OLLAMA_API_URL", "REQUEST_TIMEOUT", "PROMPT_LEVEL", "REQUEST_CONCURRENCY"} {
+				t.Setenv(env, tt.env[env])
+			}
+
+			// Os padrões das flags já consideram as variáveis de ambiente, como em rootCmd
+			modelName, timeout, level = getEnvOrDefault("MODEL_NAME", "codellama"), 30, getEnvOrDefault("PROMPT_LEVEL", "")
+			c := newFlagsCommand()
+			if err := c.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			configPath = path
+
+			if err := applyConfigFile(c); err != nil {
+				t.Fatalf("applyConfigFile() error = %v", err)
+			}
+			if modelName != tt.model || timeout != tt.timeout || level != tt.level || language != tt.language {
+				t.Errorf("applyConfigFile() = model %q, timeout %d, level %q, language %q; want %q, %d, %q, %q",
+					modelName, timeout, level, language, tt.model, tt.timeout, tt.level, tt.language)
+			}
+			if loadedConfig == nil || loadedConfig.Path != path {
+				t.Errorf("Expected loadedConfig from %s, got %+v", path, loadedConfig)
+			}
+		})
+	}
+}
+
+// newFlagsCommand cria um comando com as flags globais usadas por applyConfigFile,
+// ligadas às mesmas variáveis, mas com o estado "Changed" isolado de rootCmd
+func newFlagsCommand() *cobra.Command {
+	c := &cobra.Command{Use: "teste"}
+	c.Flags().StringVarP(&modelName, "model", "m", modelName, "")
+	c.Flags().StringVarP(&apiURL, "api-url", "u", apiURL, "")
+	c.Flags().IntVarP(&timeout, "timeout", "t", timeout, "")
+	c.Flags().StringVar(&level, "level", level, "")
+	c.Flags().StringVarP(&language, "language", "l", "", "")
+	c.Flags().IntVar(&concurrency, "concurrency", concurrency, "")
+	return c
+}
+
+// saveGlobals guarda as flags globais alteradas por applyConfigFile e retorna a função que as restaura
+func saveGlobals() func() {
+	m, u, tm, l, lang, c, cfg, loaded := modelName, apiURL, timeout, level, language, concurrency, configPath, loadedConfig
+	return func() {
+		modelName, apiURL, timeout, level, language, concurrency, configPath, loadedConfig = m, u, tm, l, lang, c, cfg, loaded
+	}
+}
+
+func TestSetupCommandPolicyError(t *testing.T) {
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	os.MkdirAll(filepath.Join(repo, ".git"), 0o755)
+	os.WriteFile(filepath.Join(repo, config.FileName), []byte("policy: [invalida\n"), 0o644)
+	explicit := filepath.Join(dir, "config.yaml")
+	os.WriteFile(explicit, []byte("model: llama3\n"), 0o644)
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(repo); err != nil {
+		t.Fatal(err)
+	}
+
+	restore := saveGlobals()
+	defer restore()
+	logger := slog.Default()
+	defer slog.SetDefault(logger)
+	defer func() { repoConfig, policyGuard = nil, nil }()
+
+	tests := []struct {
+		name      string
+		errs      *setupErrors
+		expectErr bool
+	}{
+		{name: "Erro de uso nos comandos", expectErr: true},
+		{name: "Guardado para o doctor", errs: &setupErrors{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath = explicit
+			c := newFlagsCommand()
+			c.SetContext(context.Background())
+
+			err := setupCommand(c, tt.errs)
+			if tt.expectErr {
+				if code, _ := exitCode(err, false); err == nil || code != exitUsage {
+					t.Errorf("setupCommand() error = %v (code %d), want usage error", err, code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setupCommand() error = %v", err)
+			}
+			if tt.errs.config != nil || tt.errs.policy == nil {
+				t.Errorf("setupCommand() errs = %+v, want only a policy error", tt.errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/auth"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr       string
+	serveMaxBody    int64
+	serveMaxTimeout time.Duration
+	serveRateLimit  int
+	serveBurst      int
+	serveInFlight   int
+	serveMaxQueue   int
+	serveTrustProxy bool
+	serveHistory    bool
+)
+
+// serveCmd representa o comando serve
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Inicia a API REST do code-explainer",
+	Long: `Inicia um servidor HTTP que expõe a explicação e a detecção de linguagem.
+
+Endpoints:
+  POST /v1/explain         {"code": "...", "language": "", "model": "", "level": "", "timeout": 30}
+  GET|POST /v1/explain/stream  Mesmos campos (GET via parâmetros de consulta), resposta em
+                               Server-Sent Events: language, token..., done ou error
+  POST /v1/detect          {"code": "...", "filename": "main.go"}
+  GET  /v1/languages       Linguagens e níveis suportados
+  GET  /healthz            Verificação de saúde
+  GET  /metrics            Métricas no formato do Prometheus (apenas administradores,
+                           se houver API keys)
+  GET  /v1/admin/usage     Uso do dia de cada API key (apenas administradores)
+
+Com --rate-limit, cada cliente (identificado pelo nome da API key autenticada
+ou pelo IP) pode fazer até N requisições por minuto nas rotas /v1, com rajadas
+de até --burst. Tentativas com chave inválida consomem o limite do IP. Com --max-in-flight, no máximo N
+explicações rodam ao mesmo tempo e até --max-queue aguardam na fila. Acima
+desses limites a resposta é 429 com o cabeçalho Retry-After. Os limites também
+podem vir da seção server do arquivo de configuração.
+
+Se houver API keys (auth.keys no arquivo de configuração ou criadas com
+"code-explainer keys add"), as rotas /v1 exigem "Authorization: Bearer <chave>"
+e cada chave tem cotas diárias de explicações e de tokens. Chaves de
+administrador consultam o uso de todas em GET /v1/admin/usage e as métricas em
+GET /metrics.
+
+Cada requisição recebe um ID (cabeçalho X-Request-ID, reaproveitado se enviado
+pelo cliente) incluído nos logs. Use --log-format json para logs estruturados.
+
+Os campos opcionais de /v1/explain sobrescrevem os valores das flags globais
+(--model, --level, --language, --timeout). O timeout pedido pelo cliente é
+limitado por --max-timeout. Com MODEL_NAME definida, o modelo é fixo e pedidos
+com outro "model" são recusados. As explicações usam o cache local; o histórico
+fica desativado, pois guardaria o código de todos os clientes, a menos que se
+use --history.
+
+O servidor termina de forma graciosa ao receber SIGINT ou SIGTERM, aguardando
+as requisições em andamento.
+
+Exemplos:
+  code-explainer serve
+  code-explainer serve --addr :9090 --max-body 2097152
+  code-explainer serve --log-format json
+  code-explainer serve --rate-limit 30 --max-in-flight 2 --max-queue 20
+  curl -s localhost:8080/v1/explain -d '{"code": "fmt.Println(1)"}'
+  curl -N localhost:8080/v1/explain/stream -d '{"code": "fmt.Println(1)"}'`,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", getEnvOrDefault("SERVER_ADDR", ":8080"), "Endereço de escuta do servidor")
+	serveCmd.Flags().Int64Var(&serveMaxBody, "max-body", server.DefaultMaxBodyBytes, "Tamanho máximo do corpo das requisições em bytes")
+	serveCmd.Flags().DurationVar(&serveMaxTimeout, "max-timeout", server.DefaultMaxTimeout, "Timeout máximo que um cliente pode pedir")
+	serveCmd.Flags().IntVar(&serveRateLimit, "rate-limit", 0, "Requisições por minuto por cliente (API key ou IP); 0 desliga")
+	serveCmd.Flags().IntVar(&serveBurst, "burst", server.DefaultBurst, "Rajada de requisições permitida acima de --rate-limit")
+	serveCmd.Flags().IntVar(&serveInFlight, "max-in-flight", 0, "Explicações simultâneas; 0 desliga o limite")
+	serveCmd.Flags().IntVar(&serveMaxQueue, "max-queue", server.DefaultMaxQueue, "Explicações aguardando vaga quando --max-in-flight é atingido")
+	serveCmd.Flags().BoolVar(&serveTrustProxy, "trust-proxy", false, "Identifica o cliente pelo cabeçalho X-Forwarded-For")
+	serveCmd.Flags().BoolVar(&serveHistory, "history", false, "Registra as explicações dos clientes no histórico local")
+}
+
+// applyServerConfig aplica a seção server do arquivo de configuração às flags não informadas
+func applyServerConfig(cmd *cobra.Command) {
+	if loadedConfig == nil {
+		return
+	}
+	c := loadedConfig.Server
+	flags := cmd.Flags()
+
+	if c.RateLimit > 0 && !flags.Changed("rate-limit") {
+		serveRateLimit = c.RateLimit
+	}
+	if c.Burst > 0 && !flags.Changed("burst") {
+		serveBurst = c.Burst
+	}
+	if c.MaxInFlight > 0 && !flags.Changed("max-in-flight") {
+		serveInFlight = c.MaxInFlight
+	}
+	if c.MaxQueue != nil && !flags.Changed("max-queue") {
+		serveMaxQueue = *c.MaxQueue
+	}
+	if c.TrustProxy && !flags.Changed("trust-proxy") {
+		serveTrustProxy = true
+	}
+}
+
+// knownModels retorna o modelo efetivo e os modelos instalados, aceitos como rótulos
+// nas métricas do servidor. Se o servidor do modelo não responder, apenas o efetivo.
+func knownModels(ctx context.Context) []string {
+	config := newConfig()
+	models := []string{openai.EffectiveModel(config)}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	installed, err := openai.ListModels(ctx, config)
+	if err != nil {
+		slog.Debug("não foi possível listar os modelos instalados", "error", err)
+		return models
+	}
+	for _, m := range installed {
+		models = append(models, m.Name)
+	}
+	return models
+}
+
+// authenticatorLen retorna o número de chaves aceitas, ou 0 sem autenticação
+func authenticatorLen(a *auth.Authenticator) int {
+	if a == nil {
+		return 0
+	}
+	return a.Len()
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	applyServerConfig(cmd)
+	if serveRateLimit < 0 || serveBurst < 0 || serveInFlight < 0 || serveMaxQueue < 0 {
+		return usageError(fmt.Errorf("--rate-limit, --burst, --max-in-flight e --max-queue não podem ser negativos"))
+	}
+
+	// O histórico é do operador; o código dos clientes só entra nele se pedido
+	if !serveHistory {
+		noHistory = true
+	}
+
+	authenticator, err := loadAuthenticator()
+	if err != nil {
+		return fmt.Errorf("erro ao carregar API keys: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveMetrics = server.NewMetrics()
+	srv := server.New(server.Options{
+		Models:       knownModels(ctx),
+		Config:       newConfig(),
+		Explain:      explainCode,
+		Stream:       streamCode,
+		MaxBodyBytes: serveMaxBody,
+		MaxTimeout:   serveMaxTimeout,
+		Version:      rootCmd.Version,
+		Metrics:      serveMetrics,
+		Logger:       slog.Default(),
+		RateLimit:    serveRateLimit,
+		Burst:        serveBurst,
+		MaxInFlight:  serveInFlight,
+		MaxQueue:     serveMaxQueue,
+		TrustProxy:   serveTrustProxy,
+		Auth:         authenticator,
+	})
+
+	if authenticator == nil {
+		slog.Warn("autenticação desativada: nenhuma API key configurada; qualquer cliente na rede pode usar o servidor")
+	}
+
+	slog.Info("servidor ouvindo",
+		"addr", serveAddr,
+		"model", modelName,
+		"api_url", apiURL,
+		"rate_limit", serveRateLimit,
+		"max_in_flight", serveInFlight,
+		"max_queue", serveMaxQueue,
+		"api_keys", authenticatorLen(authenticator),
+	)
+
+	if err := srv.ListenAndServe(ctx, serveAddr); err != nil {
+		return fmt.Errorf("erro no servidor: %w", err)
+	}
+
+	slog.Info("servidor encerrado")
+	return nil
+}
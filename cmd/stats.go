@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/history"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsSince  time.Duration
+	statsFormat string
+)
+
+// statsCmd representa o comando stats
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Mostra latência, tokens e custo estimado por modelo",
+	Long: `Agrega o histórico de explicações por modelo: número de requisições,
+respostas do cache, latência média, tokens de entrada e gerados, velocidade
+de geração (tokens/s) e custo estimado para provedores pagos.
+
+Os preços vêm de uma tabela de referência e podem ser definidos na seção
+pricing do arquivo de configuração (dólares por milhão de tokens).
+
+Exemplos:
+  code-explainer stats
+  code-explainer stats --since 168h
+  code-explainer stats --format json`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().DurationVar(&statsSince, "since", 0, "Apenas entradas mais recentes que a duração (ex.: 168h)")
+	statsCmd.Flags().StringVar(&statsFormat, "format", "text", "Formato de saída: text ou json")
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if statsFormat != "text" && statsFormat != "json" {
+		return usageError(fmt.Errorf("formato inválido: %s (use text ou json)", statsFormat))
+	}
+
+	store, err := newHistory()
+	if err != nil {
+		return err
+	}
+	entries, err := store.All()
+	if err != nil {
+		return fmt.Errorf("erro ao ler histórico: %w", err)
+	}
+	if statsSince > 0 {
+		entries = history.Since(entries, time.Now().Add(-statsSince))
+	}
+
+	stats := history.Stats(entries, pricing())
+
+	if statsFormat == "json" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeOutput(string(data) + "\n")
+	}
+
+	return writeOutput(formatStats(stats))
+}
+
+// pricing combina a tabela de referência com os preços do arquivo de configuração
+func pricing() map[string]history.Price {
+	prices := make(map[string]history.Price, len(history.DefaultPrices))
+	for model, p := range history.DefaultPrices {
+		prices[model] = p
+	}
+	if loadedConfig != nil {
+		for model, p := range loadedConfig.Pricing {
+			prices[model] = history.Price{Input: p.Input, Output: p.Output}
+		}
+	}
+	return prices
+}
+
+// formatStats monta o relatório em texto
+func formatStats(stats []history.ModelStats) string {
+	if len(stats) == 0 {
+		return "📭 Nenhuma explicação encontrada no histórico.\n"
+	}
+
+	var out strings.Builder
+	out.WriteString("📊 Estatísticas por Modelo\n")
+	out.WriteString(strings.Repeat("=", 30) + "\n\n")
+
+	for _, s := range stats {
+		model := s.Model
+		if model == "" {
+			model = "(desconhecido)"
+		}
+		out.WriteString(fmt.Sprintf("🤖 %s\n", model))
+		out.WriteString(fmt.Sprintf("   Requisições: %d (%d do cache)\n", s.Requests, s.Cached))
+		out.WriteString(fmt.Sprintf("   Latência média: %v\n", (time.Duration(s.AvgLatencyMs) * time.Millisecond).Round(time.Millisecond)))
+		out.WriteString(fmt.Sprintf("   Tokens: %d de entrada, %d gerados\n", s.PromptTokens, s.CompletionTokens))
+		if s.TokensPerSecond > 0 {
+			out.WriteString(fmt.Sprintf("   Velocidade: %.1f tokens/s\n", s.TokensPerSecond))
+		}
+		if s.Priced {
+			out.WriteString(fmt.Sprintf("   Custo estimado: US$ %.6f\n", s.Cost))
+		} else {
+			out.WriteString("   Custo estimado: sem preço configurado (gratuito se local)\n")
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// logUsage registra no log, em nível debug, os tokens e tempos de uma resposta
+func logUsage(u openai.Usage) {
+	slog.Debug("uso de tokens",
+		"prompt_tokens", u.PromptTokens,
+		"completion_tokens", u.CompletionTokens,
+		"tokens_per_second", math.Round(u.TokensPerSecond*10)/10,
+		"total_ms", u.TotalMs,
+		"load_ms", u.LoadMs,
+	)
+}
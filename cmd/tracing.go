@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/tracing"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingShutdownTimeout limita a espera pelo envio dos spans ao final do comando
+const tracingShutdownTimeout = 5 * time.Second
+
+var (
+	traceEndpoint string
+	traceFile     string
+
+	commandSpan     trace.Span
+	shutdownTracing tracing.ShutdownFunc
+)
+
+// setupTracing configura a exportação dos spans e inicia o span do comando,
+// que passa a ser o pai de todos os spans criados a partir de cmd.Context()
+func setupTracing(cmd *cobra.Command) error {
+	shutdown, err := tracing.Setup(cmd.Context(), tracing.Options{
+		Endpoint:    traceEndpoint,
+		File:        traceFile,
+		ServiceName: "code-explainer",
+		Version:     cmd.Root().Version,
+	})
+	if err != nil {
+		return err
+	}
+	shutdownTracing = shutdown
+
+	ctx, span := tracing.Start(cmd.Context(), cmd.CommandPath())
+	commandSpan = span
+	cmd.SetContext(ctx)
+	return nil
+}
+
+// finishTracing encerra o span do comando e envia os spans pendentes
+func finishTracing(err error) {
+	if commandSpan != nil {
+		tracing.End(commandSpan, err)
+	}
+	if shutdownTracing == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Warn("não foi possível exportar os spans", "error", err)
+	}
+}
@@ -0,0 +1,232 @@
+// Package config carrega o arquivo de configuração YAML do Code Explainer.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName é o nome do arquivo do repositório, procurado do diretório atual até a raiz do repositório
+const FileName = ".code-explainer.yaml"
+
+var sha256Hex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// File representa o conteúdo do arquivo de configuração. Campos vazios
+// mantêm o padrão das flags e variáveis de ambiente.
+type File struct {
+	Model       string `yaml:"model"`
+	APIURL      string `yaml:"api_url"`
+	Timeout     int    `yaml:"timeout"` // Em segundos
+	Level       string `yaml:"level"`
+	Language    string `yaml:"language"`
+	Concurrency int    `yaml:"concurrency"`
+
+	Redact Redact `yaml:"redact"`
+	Policy Policy `yaml:"policy"`
+	Server Server `yaml:"server"`
+	Auth   Auth   `yaml:"auth"`
+
+	Pricing map[string]Price `yaml:"pricing"` // Preços por modelo usados pelo comando stats
+
+	Path string `yaml:"-"` // Caminho de onde o arquivo foi carregado
+}
+
+// Redact configura a ocultação de dados sensíveis antes do envio ao modelo
+type Redact struct {
+	Disabled bool      `yaml:"disabled"`
+	Restore  bool      `yaml:"restore"` // Devolve os valores originais na resposta
+	Patterns []Pattern `yaml:"patterns"`
+}
+
+// Policy restringe os servidores para onde o código pode ser enviado
+type Policy struct {
+	AllowRemote  *bool    `yaml:"allow_remote"`  // false permite apenas servidores locais
+	AllowedHosts []string `yaml:"allowed_hosts"` // Hosts remotos permitidos ("host", "host:porta" ou "*.dominio")
+	AuditLog     string   `yaml:"audit_log"`     // Padrão: ~/.local/state/code-explainer/audit.log
+}
+
+// Server configura os limites do comando serve
+type Server struct {
+	RateLimit   int  `yaml:"rate_limit"`    // Requisições por minuto por cliente (API key ou IP)
+	Burst       int  `yaml:"burst"`         // Rajada permitida acima da taxa
+	MaxInFlight int  `yaml:"max_in_flight"` // Explicações simultâneas
+	MaxQueue    *int `yaml:"max_queue"`     // Explicações aguardando vaga; 0 recusa de imediato
+	TrustProxy  bool `yaml:"trust_proxy"`   // Usa o X-Forwarded-For para identificar o cliente
+}
+
+// Auth define as API keys aceitas pelo comando serve
+type Auth struct {
+	Keys  []APIKey `yaml:"keys"`
+	Store string   `yaml:"store"` // Arquivo do comando keys; padrão: ~/.local/state/code-explainer/keys.json
+}
+
+// APIKey é uma chave definida no arquivo, em texto puro (aceita ${VAR}) ou pelo hash
+type APIKey struct {
+	Name          string `yaml:"name"`
+	Key           string `yaml:"key"`
+	KeySHA256     string `yaml:"key_sha256"`
+	Admin         bool   `yaml:"admin"`
+	DailyRequests int    `yaml:"daily_requests"` // 0 = sem limite
+	DailyTokens   int    `yaml:"daily_tokens"`   // 0 = sem limite
+}
+
+// Price é o preço de um modelo em dólares por milhão de tokens
+type Price struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// Pattern é uma expressão regular personalizada de dados sensíveis
+type Pattern struct {
+	Name  string `yaml:"name"`
+	Regex string `yaml:"regex"`
+}
+
+// Paths retorna os caminhos procurados, em ordem de prioridade: o arquivo do
+// repositório (veja FindRepo), se houver, e $XDG_CONFIG_HOME/code-explainer/config.yaml
+func Paths() []string {
+	var paths []string
+	if path, err := FindRepo("."); err == nil && path != "" {
+		paths = append(paths, path)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "code-explainer", "config.yaml"))
+	}
+	return paths
+}
+
+// Find retorna o caminho do arquivo de configuração. Se explicit não for vazio,
+// ele precisa existir; caso contrário, o primeiro de Paths que existir é usado.
+// Retorna "" se nenhum arquivo for encontrado.
+func Find(explicit string) (string, error) {
+	if explicit != "" {
+		if _, err := os.Stat(explicit); err != nil {
+			return "", fmt.Errorf("arquivo de configuração %s: %w", explicit, err)
+		}
+		return explicit, nil
+	}
+
+	for _, path := range Paths() {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// FindRepo procura FileName em dir e nos diretórios acima dele, até a raiz do
+// repositório (o diretório que contém .git). Fora de um repositório, apenas dir
+// é verificado. Retorna "" se nenhum arquivo for encontrado.
+func FindRepo(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	root := repoRoot(dir)
+	if root == "" {
+		root = dir
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// repoRoot retorna o primeiro diretório, a partir de dir e subindo, que contém .git, ou ""
+func repoRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load lê e valida o arquivo. Campos desconhecidos são rejeitados para
+// que erros de digitação não passem despercebidos.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	f.Path = path
+	return f, nil
+}
+
+// Parse decodifica e valida o conteúdo YAML
+func Parse(data []byte) (*File, error) {
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("configuração inválida: %w", err)
+	}
+
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Validate verifica os valores numéricos e as expressões do arquivo
+func (f *File) Validate() error {
+	for i, p := range f.Redact.Patterns {
+		if p.Name == "" {
+			return fmt.Errorf("configuração inválida: redact.patterns[%d] sem name", i)
+		}
+		if _, err := regexp.Compile(p.Regex); err != nil {
+			return fmt.Errorf("configuração inválida: redact.patterns[%d] (%s): %w", i, p.Name, err)
+		}
+	}
+	for model, p := range f.Pricing {
+		if p.Input < 0 || p.Output < 0 {
+			return fmt.Errorf("configuração inválida: pricing.%s não pode ter preço negativo", model)
+		}
+	}
+	if f.Timeout < 0 {
+		return fmt.Errorf("configuração inválida: timeout deve ser maior ou igual a zero")
+	}
+	if f.Concurrency < 0 {
+		return fmt.Errorf("configuração inválida: concurrency deve ser maior ou igual a zero")
+	}
+	if f.Server.RateLimit < 0 || f.Server.Burst < 0 || f.Server.MaxInFlight < 0 || (f.Server.MaxQueue != nil && *f.Server.MaxQueue < 0) {
+		return fmt.Errorf("configuração inválida: os limites de server devem ser maiores ou iguais a zero")
+	}
+	for i, k := range f.Auth.Keys {
+		switch {
+		case k.Name == "":
+			return fmt.Errorf("configuração inválida: auth.keys[%d] sem name", i)
+		case (k.Key == "") == (k.KeySHA256 == ""):
+			return fmt.Errorf("configuração inválida: auth.keys[%d] (%s) precisa de key ou key_sha256", i, k.Name)
+		case k.KeySHA256 != "" && !sha256Hex.MatchString(k.KeySHA256):
+			return fmt.Errorf("configuração inválida: auth.keys[%d] (%s): key_sha256 deve ter 64 dígitos hexadecimais", i, k.Name)
+		case k.DailyRequests < 0 || k.DailyTokens < 0:
+			return fmt.Errorf("configuração inválida: auth.keys[%d] (%s): cotas devem ser maiores ou iguais a zero", i, k.Name)
+		}
+	}
+	return nil
+}
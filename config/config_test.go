@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected File
+		wantErr  string
+	}{
+		{
+			name:     "Completo",
+			data:     "model: codellama:13b\napi_url: http://ollama:11434/api/generate\ntimeout: 120\nlevel: avancado\nconcurrency: 4\n",
+			expected: File{Model: "codellama:13b", APIURL: "http://ollama:11434/api/generate", Timeout: 120, Level: "avancado", Concurrency: 4},
+		},
+		{name: "Vazio", data: ""},
+		{
+			name:     "Padrões de redação",
+			data:     "redact:\n  restore: true\n  patterns:\n    - name: cpf\n      regex: '\\d{3}\\.\\d{3}\\.\\d{3}-\\d{2}'\n",
+			expected: File{Redact: Redact{Restore: true, Patterns: []Pattern{{Name: "cpf", Regex: `\d{3}\.\d{3}\.\d{3}-\d{2}`}}}},
+		},
+		{
+			name:     "Preços",
+			data:     "pricing:\n  gpt-4o:\n    input: 2.5\n    output: 10\n",
+			expected: File{Pricing: map[string]Price{"gpt-4o": {Input: 2.5, Output: 10}}},
+		},
+		{
+			name:     "Limites do servidor",
+			data:     "server:\n  rate_limit: 30\n  burst: 10\n  max_in_flight: 2\n  max_queue: 0\n  trust_proxy: true\n",
+			expected: File{Server: Server{RateLimit: 30, Burst: 10, MaxInFlight: 2, MaxQueue: new(int), TrustProxy: true}},
+		},
+		{
+			name: "Chaves de API",
+			data: "auth:\n  store: /srv/keys.json\n  keys:\n    - name: ana\n      key: ${CE_KEY_ANA}\n      daily_requests: 100\n    - name: ops\n      key_sha256: " + strings.Repeat("ab", 32) + "\n      admin: true\n",
+			expected: File{Auth: Auth{Store: "/srv/keys.json", Keys: []APIKey{
+				{Name: "ana", Key: "${CE_KEY_ANA}", DailyRequests: 100},
+				{Name: "ops", KeySHA256: strings.Repeat("ab", 32), Admin: true},
+			}}},
+		},
+		{name: "Chave sem valor", data: "auth:\n  keys:\n    - name: ana\n", wantErr: "precisa de key ou key_sha256"},
+		{name: "Hash inválido", data: "auth:\n  keys:\n    - name: ana\n      key_sha256: abc\n", wantErr: "64 dígitos hexadecimais"},
+		{name: "Cota negativa", data: "auth:\n  keys:\n    - name: ana\n      key: x\n      daily_tokens: -5\n", wantErr: "cotas devem ser maiores"},
+		{name: "Limite negativo", data: "server:\n  max_in_flight: -1\n", wantErr: "limites de server"},
+		{name: "Preço negativo", data: "pricing:\n  gpt-4o:\n    input: -1\n", wantErr: "pricing.gpt-4o não pode ter preço negativo"},
+		{name: "Regex inválida", data: "redact:\n  patterns:\n    - name: ruim\n      regex: '('\n", wantErr: "redact.patterns[0] (ruim)"},
+		{name: "Campo desconhecido", data: "modle: llama2\n", wantErr: "field modle not found"},
+		{name: "Tipo errado", data: "timeout: trinta\n", wantErr: "configuração inválida"},
+		{name: "Timeout negativo", data: "timeout: -1\n", wantErr: "timeout deve ser maior ou igual a zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() erro = %v, want contendo %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() erro inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(*f, tt.expected) {
+				t.Errorf("Parse() = %+v, want %+v", *f, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if path, err := Find(""); err != nil || path != "" {
+		t.Errorf("Find() sem arquivos = %q, %v; want \"\", nil", path, err)
+	}
+
+	global := filepath.Join(dir, "xdg", "code-explainer", "config.yaml")
+	os.MkdirAll(filepath.Dir(global), 0o755)
+	os.WriteFile(global, []byte("model: llama2\n"), 0o644)
+	if path, _ := Find(""); path != global {
+		t.Errorf("Find() = %q, want %q", path, global)
+	}
+
+	os.WriteFile(FileName, []byte("model: codellama\n"), 0o644)
+	if path, _ := Find(""); path != filepath.Join(dir, FileName) {
+		t.Errorf("Find() = %q, want %q (diretório atual tem prioridade)", path, filepath.Join(dir, FileName))
+	}
+
+	if _, err := Find("inexistente.yaml"); err == nil {
+		t.Error("Find() com caminho explícito inexistente deveria falhar")
+	}
+}
+
+func TestFindRepo(t *testing.T) {
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	nested := filepath.Join(repo, "cmd", "app")
+	outside := filepath.Join(dir, "fora", "sub")
+	for _, d := range []string{filepath.Join(repo, ".git"), nested, outside} {
+		os.MkdirAll(d, 0o755)
+	}
+	// Arquivos acima da raiz do repositório ou do diretório atual (fora de um repositório) são ignorados
+	os.WriteFile(filepath.Join(dir, FileName), []byte("model: llama2\n"), 0o644)
+	os.WriteFile(filepath.Join(repo, FileName), []byte("model: codellama\n"), 0o644)
+
+	tests := []struct {
+		name     string
+		dir      string
+		expected string
+	}{
+		{name: "Raiz do repositório", dir: repo, expected: filepath.Join(repo, FileName)},
+		{name: "Subdiretório", dir: nested, expected: filepath.Join(repo, FileName)},
+		{name: "Fora de um repositório", dir: outside, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := FindRepo(tt.dir)
+			if err != nil || path != tt.expected {
+				t.Errorf("FindRepo() = %q, %v; want %q", path, err, tt.expected)
+			}
+		})
+	}
+
+	os.WriteFile(filepath.Join(nested, FileName), []byte("model: mistral\n"), 0o644)
+	if path, _ := FindRepo(nested); path != filepath.Join(nested, FileName) {
+		t.Errorf("FindRepo() = %q, want the closest file", path)
+	}
+}
@@ -0,0 +1,135 @@
+// Package docgen encontra identificadores sem documentação em arquivos de código
+// e insere os comentários de documentação gerados pelo modelo.
+package docgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// Target é uma declaração que precisa de comentário de documentação
+type Target struct {
+	Name   string // Nome do identificador
+	Kind   string // func, method, type, const, var, class...
+	Line   int    // Linha (a partir de 1) acima da qual o comentário será inserido
+	Indent string // Indentação da declaração
+	Source string // Trecho de código da declaração, enviado ao modelo
+}
+
+// Comment associa o texto gerado a um Target
+type Comment struct {
+	Target Target
+	Text   string // Texto do comentário, sem marcadores de comentário
+}
+
+// FindTargets retorna as declarações públicas sem documentação do arquivo.
+// Para Go usa go/ast; para as demais linguagens, expressões regulares.
+func FindTargets(src, filename, lang string) ([]Target, error) {
+	if lang == "Go" {
+		return findGoTargets(src, filename)
+	}
+	if _, ok := styles[lang]; !ok {
+		return nil, fmt.Errorf("linguagem não suportada para documentação: %s", lang)
+	}
+	return findRegexTargets(src, lang), nil
+}
+
+// Apply insere os comentários no código e retorna o novo conteúdo.
+// Para Go o resultado é formatado com go/printer.
+func Apply(src, lang string, comments []Comment) (string, error) {
+	lines := strings.SplitAfter(src, "\n")
+
+	// Insere de baixo para cima para não deslocar as linhas ainda não processadas
+	sorted := append([]Comment(nil), comments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Target.Line > sorted[j].Target.Line
+	})
+
+	for _, c := range sorted {
+		idx := c.Target.Line - 1
+		if idx < 0 || idx > len(lines) {
+			return "", fmt.Errorf("linha fora do arquivo para %s: %d", c.Target.Name, c.Target.Line)
+		}
+		block := renderComment(c.Text, c.Target.Indent, lang)
+		lines = append(lines[:idx], append([]string{block}, lines[idx:]...)...)
+	}
+
+	result := strings.Join(lines, "")
+	if lang == "Go" {
+		return formatGo(src, result)
+	}
+	return result, nil
+}
+
+// BuildPrompt monta o prompt que pede ao modelo o comentário de um identificador
+func BuildPrompt(t Target, lang string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Escreva um comentário de documentação conciso para %s %s em %s.\n", kindLabel(t.Kind), t.Name, lang)
+	if lang == "Go" {
+		fmt.Fprintf(&b, "Siga a convenção de Go: a primeira frase deve começar com o nome %s.\n", t.Name)
+	}
+	b.WriteString("Responda apenas com o texto do comentário, sem marcadores de comentário, sem bloco de código e com no máximo 3 frases.\n\n")
+	fmt.Fprintf(&b, "```\n%s\n```\n", strings.TrimSpace(t.Source))
+
+	return b.String()
+}
+
+// CleanComment remove cercas de código, marcadores de comentário e linhas vazias
+// da resposta do modelo. Para Go, garante que o comentário comece com o nome do identificador.
+func CleanComment(answer string, t Target, lang string) string {
+	var lines []string
+
+	for _, line := range strings.Split(strings.TrimSpace(answer), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			continue
+		}
+		for _, marker := range []string{"///", "//", "/**", "*/", "/*", "#", "*"} {
+			if strings.HasPrefix(line, marker) {
+				line = strings.TrimSpace(strings.TrimPrefix(line, marker))
+				break
+			}
+		}
+		line = strings.TrimSuffix(line, "*/")
+		if line != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+
+	text := strings.Join(lines, "\n")
+	if lang == "Go" && text != "" && !strings.HasPrefix(text, t.Name) {
+		text = t.Name + ": " + text
+	}
+	return text
+}
+
+// kindLabel traduz o tipo de declaração para o prompt
+func kindLabel(kind string) string {
+	labels := map[string]string{
+		"func":   "a função",
+		"method": "o método",
+		"type":   "o tipo",
+		"const":  "a constante",
+		"var":    "a variável",
+		"class":  "a classe",
+	}
+	if label, ok := labels[kind]; ok {
+		return label
+	}
+	return "o identificador"
+}
+
+// GetSupportedLanguages retorna as linguagens suportadas pelo gerador de documentação
+func GetSupportedLanguages() []string {
+	var langs []string
+	for _, lang := range openai.GetSupportedLanguages() {
+		if _, ok := styles[lang]; ok || lang == "Go" {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
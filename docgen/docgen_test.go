@@ -0,0 +1,322 @@
+package docgen
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/mvcbotelho/code-explainer/gitdiff"
+)
+
+const goSource = `package sample
+
+import "fmt"
+
+// Documented já tem documentação
+func Documented() {}
+
+func Exported(a int) int {
+	return a * 2
+}
+
+func unexported() {}
+
+type Config struct {
+	Name string
+}
+
+func (c *Config) Print() {
+	fmt.Println(c.Name)
+}
+
+func (c *config) Hidden() {}
+
+type config struct{}
+
+const Version = "1.0"
+
+var (
+	Grouped = 1
+)
+
+type (
+	// Alias já documentado
+	Alias = string
+	Pair  struct{ A, B int }
+)
+`
+
+func TestFindGoTargets(t *testing.T) {
+	targets, err := FindTargets(goSource, "sample.go", "Go")
+	if err != nil {
+		t.Fatalf("FindTargets() error = %v", err)
+	}
+
+	expected := []struct {
+		name string
+		kind string
+		line int
+	}{
+		{name: "Exported", kind: "func", line: 8},
+		{name: "Config", kind: "type", line: 14},
+		{name: "Print", kind: "method", line: 18},
+		{name: "Version", kind: "const", line: 26},
+		{name: "Pair", kind: "type", line: 35},
+	}
+
+	if len(targets) != len(expected) {
+		t.Fatalf("Expected %d targets, got %d: %+v", len(expected), len(targets), targets)
+	}
+
+	for i, tt := range expected {
+		t.Run(tt.name, func(t *testing.T) {
+			got := targets[i]
+			if got.Name != tt.name || got.Kind != tt.kind || got.Line != tt.line {
+				t.Errorf("Got %s/%s at line %d, want %s/%s at line %d", got.Name, got.Kind, got.Line, tt.name, tt.kind, tt.line)
+			}
+		})
+	}
+
+	if !strings.Contains(targets[0].Source, "return a * 2") {
+		t.Errorf("Expected source snippet to include the function body, got %q", targets[0].Source)
+	}
+	if targets[4].Indent != "\t" {
+		t.Errorf("Expected grouped type to keep indentation, got %q", targets[4].Indent)
+	}
+}
+
+func TestApplyGo(t *testing.T) {
+	targets, _ := FindTargets(goSource, "sample.go", "Go")
+
+	var comments []Comment
+	for _, target := range targets {
+		comments = append(comments, Comment{Target: target, Text: target.Name + " faz algo útil.\nSegunda linha."})
+	}
+
+	result, err := Apply(goSource, "Go", comments)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sample.go", result, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Result does not parse: %v\n%s", err, result)
+	}
+
+	remaining, _ := FindTargets(result, "sample.go", "Go")
+	if len(remaining) != 0 {
+		t.Errorf("Expected all targets to be documented, still missing %+v", remaining)
+	}
+	if !strings.Contains(result, "\t// Pair faz algo útil.\n\t// Segunda linha.\n\tPair") {
+		t.Errorf("Expected indented comment inside grouped declaration, got:\n%s", result)
+	}
+	if len(file.Comments) == 0 {
+		t.Errorf("Expected comments in parsed result")
+	}
+}
+
+func TestApplyGoKeepsFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "Arquivo formatado",
+			src:      "package sample\n\nfunc Exported() {}\n",
+			expected: "package sample\n\n// Exported faz algo.\nfunc Exported() {}\n",
+		},
+		{
+			name:     "Arquivo fora do gofmt não é reformatado",
+			src:      "package sample\n\nfunc Exported()  {\n    x :=  1\n    _ = x\n}\n",
+			expected: "package sample\n\n// Exported faz algo.\nfunc Exported()  {\n    x :=  1\n    _ = x\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := FindTargets(tt.src, "sample.go", "Go")
+			if err != nil || len(targets) != 1 {
+				t.Fatalf("FindTargets() = %+v, %v", targets, err)
+			}
+			result, err := Apply(tt.src, "Go", []Comment{{Target: targets[0], Text: "Exported faz algo."}})
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Apply() =\n%q\nwant\n%q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindRegexTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		src      string
+		expected []string
+	}{
+		{
+			name: "Python",
+			lang: "Python",
+			src: `import os
+
+@decorator
+def public(a):
+    return a
+
+def _private():
+    pass
+
+# já documentada
+def documented():
+    pass
+
+class Service:
+    def method(self):
+        pass
+`,
+			expected: []string{"public@3", "Service@14", "method@15"},
+		},
+		{
+			name: "JavaScript",
+			lang: "JavaScript",
+			src: `export function sum(a, b) {
+  return a + b;
+}
+
+/** doc */
+function documented() {}
+
+const double = (x) => x * 2;
+`,
+			expected: []string{"sum@1", "double@8"},
+		},
+		{
+			name: "Rust",
+			lang: "Rust",
+			src: `#[derive(Debug)]
+pub struct Point { x: i32 }
+
+fn private() {}
+
+pub fn origin() -> Point { Point { x: 0 } }
+`,
+			expected: []string{"Point@1", "origin@6"},
+		},
+		{
+			name: "C",
+			lang: "C",
+			src: `#include <stdio.h>
+
+static int helper(void) { return 1; }
+
+int main(void) {
+    if (helper()) {
+        return 0;
+    }
+}
+`,
+			expected: []string{"main@5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := FindTargets(tt.src, "arquivo", tt.lang)
+			if err != nil {
+				t.Fatalf("FindTargets() error = %v", err)
+			}
+
+			var got []string
+			for _, target := range targets {
+				got = append(got, fmt.Sprintf("%s@%d", target.Name, target.Line))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("FindTargets() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := FindTargets("x", "arquivo", "Cobol"); err == nil {
+		t.Errorf("Expected error for unsupported language")
+	}
+}
+
+func TestApplyRegexStyles(t *testing.T) {
+	src := "class Service:\n    def run(self):\n        pass\n"
+	targets, _ := FindTargets(src, "service.py", "Python")
+
+	comments := []Comment{
+		{Target: targets[0], Text: "Serviço principal."},
+		{Target: targets[1], Text: "Executa o serviço."},
+	}
+
+	result, err := Apply(src, "Python", comments)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	expected := "# Serviço principal.\nclass Service:\n    # Executa o serviço.\n    def run(self):\n        pass\n"
+	if result != expected {
+		t.Errorf("Apply() =\n%s\nwant\n%s", result, expected)
+	}
+
+	js, _ := Apply("function a() {}\n", "JavaScript", []Comment{{Target: Target{Name: "a", Line: 1}, Text: "Faz a."}})
+	if js != "/**\n * Faz a.\n */\nfunction a() {}\n" {
+		t.Errorf("Unexpected JSDoc comment: %q", js)
+	}
+}
+
+func TestCleanComment(t *testing.T) {
+	target := Target{Name: "Exported"}
+
+	tests := []struct {
+		name     string
+		answer   string
+		lang     string
+		expected string
+	}{
+		{name: "Com marcadores Go", answer: "```go\n// Exported dobra o valor.\n```", lang: "Go", expected: "Exported dobra o valor."},
+		{name: "Sem o nome", answer: "Dobra o valor.", lang: "Go", expected: "Exported: Dobra o valor."},
+		{name: "JSDoc", answer: "/**\n * Soma dois números.\n */", lang: "JavaScript", expected: "Soma dois números."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CleanComment(tt.answer, target, tt.lang); got != tt.expected {
+				t.Errorf("CleanComment() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	b := "a\nnovo\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nmudado\n"
+
+	diff := UnifiedDiff("a/x.txt", "b/x.txt", a, b)
+
+	files, err := gitdiff.Parse(strings.NewReader(diff))
+	if err != nil {
+		t.Fatalf("Generated diff does not parse: %v\n%s", err, diff)
+	}
+	if len(files) != 1 || len(files[0].Hunks) != 2 {
+		t.Fatalf("Expected 1 file with 2 hunks, got:\n%s", diff)
+	}
+	added, removed := files[0].Stats()
+	if added != 2 || removed != 1 {
+		t.Errorf("Expected +2 -1, got +%d -%d", added, removed)
+	}
+	if files[0].Hunks[0].OldStart != 1 || files[0].Hunks[1].NewStart != 11 {
+		t.Errorf("Unexpected hunk positions:\n%s", diff)
+	}
+
+	if UnifiedDiff("a", "b", a, a) != "" {
+		t.Errorf("Expected empty diff for identical content")
+	}
+}
@@ -0,0 +1,119 @@
+package docgen
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// findGoTargets usa go/ast para encontrar declarações exportadas sem comentário de documentação
+func findGoTargets(src, filename string) ([]Target, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []Target
+	add := func(name, kind string, pos, end token.Pos) {
+		start := fset.Position(pos)
+		targets = append(targets, Target{
+			Name:   name,
+			Kind:   kind,
+			Line:   start.Line,
+			Indent: leadingIndent(src, start.Offset-start.Column+1),
+			Source: src[start.Offset:fset.Position(end).Offset],
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil || !d.Name.IsExported() {
+				continue
+			}
+			kind := "func"
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				recv := receiverName(d.Recv.List[0].Type)
+				if !ast.IsExported(recv) {
+					continue
+				}
+				kind = "method"
+			}
+			// Envia apenas a assinatura e o corpo, sem documentação
+			add(d.Name.Name, kind, d.Pos(), d.End())
+
+		case *ast.GenDecl:
+			grouped := d.Lparen.IsValid()
+			if !grouped && d.Doc != nil {
+				continue
+			}
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if !s.Name.IsExported() || (grouped && s.Doc != nil) {
+						continue
+					}
+					if grouped {
+						add(s.Name.Name, "type", s.Pos(), s.End())
+					} else {
+						add(s.Name.Name, "type", d.Pos(), d.End())
+					}
+
+				case *ast.ValueSpec:
+					// Em blocos agrupados de const/var a documentação costuma ficar no bloco
+					if grouped || len(s.Names) == 0 || !s.Names[0].IsExported() {
+						continue
+					}
+					add(s.Names[0].Name, d.Tok.String(), d.Pos(), d.End())
+				}
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// receiverName extrai o nome do tipo do receptor (T, *T, T[K], *T[K])
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// formatGo valida que o resultado continua compilável e o reformata com go/printer
+// apenas se o original já seguia o gofmt. Caso contrário, reformatar alteraria linhas
+// sem relação com os comentários inseridos.
+func formatGo(original, result string) (string, error) {
+	if formatted, err := format.Source([]byte(original)); err == nil && string(formatted) == original {
+		out, err := format.Source([]byte(result))
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", result, parser.ParseComments); err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// leadingIndent retorna os espaços e tabs no início da linha que começa em offset
+func leadingIndent(src string, offset int) string {
+	if offset < 0 || offset > len(src) {
+		return ""
+	}
+	line := src[offset:]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
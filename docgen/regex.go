@@ -0,0 +1,164 @@
+package docgen
+
+import (
+	"regexp"
+	"strings"
+)
+
+// commentStyle define como os comentários de documentação são escritos em cada linguagem
+type commentStyle struct {
+	open   string // Linha de abertura (vazia para comentários de linha)
+	prefix string // Prefixo de cada linha do texto
+	close  string // Linha de fechamento (vazia para comentários de linha)
+}
+
+// definition reconhece definições de funções e classes de uma linguagem.
+// O grupo "name" captura o identificador.
+type definition struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+// styles contém o estilo de comentário de cada linguagem suportada além de Go
+var styles = map[string]commentStyle{
+	"Python":     {prefix: "# "},
+	"JavaScript": {open: "/**", prefix: " * ", close: " */"},
+	"Java":       {open: "/**", prefix: " * ", close: " */"},
+	"PHP":        {open: "/**", prefix: " * ", close: " */"},
+	"C":          {open: "/**", prefix: " * ", close: " */"},
+	"Rust":       {prefix: "/// "},
+	"C#":         {prefix: "/// "},
+}
+
+// definitions contém os padrões de definição de cada linguagem
+var definitions = map[string][]definition{
+	"Python": {
+		{kind: "func", pattern: regexp.MustCompile(`^\s*(?:async\s+)?def\s+(?P<name>\w+)\s*\(`)},
+		{kind: "class", pattern: regexp.MustCompile(`^\s*class\s+(?P<name>\w+)`)},
+	},
+	"JavaScript": {
+		{kind: "func", pattern: regexp.MustCompile(`^\s*(?:export\s+(?:default\s+)?)?(?:async\s+)?function\s*\*?\s*(?P<name>\w+)\s*\(`)},
+		{kind: "func", pattern: regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(?P<name>\w+)\s*=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*=>|\w+\s*=>)`)},
+		{kind: "class", pattern: regexp.MustCompile(`^\s*(?:export\s+(?:default\s+)?)?class\s+(?P<name>\w+)`)},
+	},
+	"Java": {
+		{kind: "class", pattern: regexp.MustCompile(`^\s*public\s+(?:(?:abstract|final|static)\s+)*(?:class|interface|enum|record)\s+(?P<name>\w+)`)},
+		{kind: "method", pattern: regexp.MustCompile(`^\s*public\s+(?:(?:static|final|abstract|synchronized|default)\s+)*(?:<[^>]+>\s+)?[\w<>\[\],.?\s]+?\s+(?P<name>\w+)\s*\([^;]*$`)},
+	},
+	"C#": {
+		{kind: "class", pattern: regexp.MustCompile(`^\s*public\s+(?:(?:abstract|sealed|static|partial)\s+)*(?:class|interface|struct|enum|record)\s+(?P<name>\w+)`)},
+		{kind: "method", pattern: regexp.MustCompile(`^\s*public\s+(?:(?:static|virtual|override|abstract|async|sealed)\s+)*[\w<>\[\],.?\s]+?\s+(?P<name>\w+)\s*\([^;]*$`)},
+	},
+	"PHP": {
+		{kind: "func", pattern: regexp.MustCompile(`^\s*(?:(?:public|static|final|abstract)\s+)*function\s+(?P<name>\w+)\s*\(`)},
+		{kind: "class", pattern: regexp.MustCompile(`^\s*(?:(?:abstract|final)\s+)*class\s+(?P<name>\w+)`)},
+	},
+	"Rust": {
+		{kind: "func", pattern: regexp.MustCompile(`^\s*pub(?:\([^)]*\))?\s+(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?fn\s+(?P<name>\w+)`)},
+		{kind: "type", pattern: regexp.MustCompile(`^\s*pub(?:\([^)]*\))?\s+(?:struct|enum|trait)\s+(?P<name>\w+)`)},
+	},
+	"C": {
+		{kind: "func", pattern: regexp.MustCompile(`^(?:[A-Za-z_][\w]*[\s\*]+)+(?P<name>[A-Za-z_]\w*)\s*\([^;]*\)\s*\{?\s*$`)},
+	},
+}
+
+// controlKeywords evita confundir estruturas de controle com definições de função em C
+var controlKeywords = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "return": true, "else": true}
+
+// findRegexTargets encontra definições públicas sem comentário na linha anterior
+func findRegexTargets(src, lang string) []Target {
+	lines := strings.Split(src, "\n")
+	var targets []Target
+
+	for i, line := range lines {
+		for _, def := range definitions[lang] {
+			m := def.pattern.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			name := m[def.pattern.SubexpIndex("name")]
+			if strings.HasPrefix(name, "_") || controlKeywords[name] || isPrivate(line, lang) {
+				break
+			}
+
+			// O comentário vai acima de decorators e atributos
+			insertAt := i
+			for insertAt > 0 && isAttribute(lines[insertAt-1]) {
+				insertAt--
+			}
+			if insertAt > 0 && isComment(lines[insertAt-1]) {
+				break
+			}
+
+			targets = append(targets, Target{
+				Name:   name,
+				Kind:   def.kind,
+				Line:   insertAt + 1,
+				Indent: line[:len(line)-len(strings.TrimLeft(line, " \t"))],
+				Source: snippet(lines, i, 15),
+			})
+			break
+		}
+	}
+
+	return targets
+}
+
+// isPrivate identifica definições explicitamente privadas
+func isPrivate(line, lang string) bool {
+	trimmed := strings.TrimSpace(line)
+	switch lang {
+	case "PHP", "Java", "C#":
+		return strings.HasPrefix(trimmed, "private ") || strings.HasPrefix(trimmed, "protected ")
+	case "C":
+		return strings.HasPrefix(trimmed, "static ")
+	}
+	return false
+}
+
+// isAttribute identifica decorators (Python, Java) e atributos (Rust, C#)
+func isAttribute(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "@") || strings.HasPrefix(trimmed, "#[") ||
+		(strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"))
+}
+
+// isComment identifica linhas de comentário
+func isComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, marker := range []string{"//", "#", "*", "/*", `"""`} {
+		if strings.HasPrefix(trimmed, marker) && !strings.HasPrefix(trimmed, "#[") && !strings.HasPrefix(trimmed, "#include") && !strings.HasPrefix(trimmed, "#define") {
+			return true
+		}
+	}
+	return false
+}
+
+// snippet retorna até n linhas a partir de start
+func snippet(lines []string, start, n int) string {
+	end := start + n
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(lines[start:end], "\n")
+}
+
+// renderComment formata o texto como comentário de documentação da linguagem
+func renderComment(text, indent, lang string) string {
+	style, ok := styles[lang]
+	if lang == "Go" || !ok {
+		style = commentStyle{prefix: "// "}
+	}
+
+	var b strings.Builder
+	if style.open != "" {
+		b.WriteString(indent + style.open + "\n")
+	}
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString(indent + strings.TrimRight(style.prefix+line, " ") + "\n")
+	}
+	if style.close != "" {
+		b.WriteString(indent + style.close + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,204 @@
+package docgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines é a quantidade de linhas de contexto ao redor de cada trecho
+const contextLines = 3
+
+// opKind identifica uma operação de edição entre duas listas de linhas
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// edit é uma operação sobre uma linha
+type edit struct {
+	kind opKind
+	line string
+}
+
+// UnifiedDiff retorna o diff unificado entre os conteúdos a e b, ou string vazia se forem iguais
+func UnifiedDiff(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Agrupa as edições em trechos com até contextLines linhas de contexto
+	for i := 0; i < len(edits); {
+		if edits[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		for start < i && edits[start].kind != opEqual {
+			start++
+		}
+
+		end := i
+		for end < len(edits) {
+			if edits[end].kind != opEqual {
+				end++
+				continue
+			}
+			// Continua o trecho se a próxima alteração estiver próxima
+			next := end
+			for next < len(edits) && edits[next].kind == opEqual {
+				next++
+			}
+			if next == len(edits) || next-end > 2*contextLines {
+				end += min(contextLines, next-end)
+				break
+			}
+			end = next
+		}
+
+		oldStart, newStart := positions(edits, start)
+		var oldCount, newCount int
+		var body strings.Builder
+		for _, e := range edits[start:end] {
+			switch e.kind {
+			case opEqual:
+				oldCount++
+				newCount++
+				body.WriteString(" " + e.line + "\n")
+			case opDelete:
+				oldCount++
+				body.WriteString("-" + e.line + "\n")
+			case opInsert:
+				newCount++
+				body.WriteString("+" + e.line + "\n")
+			}
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		out.WriteString(body.String())
+		i = end
+	}
+
+	return out.String()
+}
+
+// positions retorna as linhas (a partir de 1) de a e b correspondentes à edição idx
+func positions(edits []edit, idx int) (int, int) {
+	oldLine, newLine := 1, 1
+	for _, e := range edits[:idx] {
+		if e.kind != opInsert {
+			oldLine++
+		}
+		if e.kind != opDelete {
+			newLine++
+		}
+	}
+	return oldLine, newLine
+}
+
+// hunkRange formata o intervalo de um cabeçalho de trecho
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines divide o conteúdo em linhas, sem a quebra final
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines calcula a menor sequência de edições entre a e b (algoritmo de Myers)
+func diffLines(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backtrack reconstrói as edições a partir dos estados registrados por diffLines
+func backtrack(trace [][]int, a, b []string, offset int) []edit {
+	x, y := len(a), len(b)
+	var edits []edit
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{kind: opEqual, line: a[x]})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				edits = append(edits, edit{kind: opInsert, line: b[y]})
+			} else {
+				x--
+				edits = append(edits, edit{kind: opDelete, line: a[x]})
+			}
+		}
+	}
+
+	// As edições foram coletadas do fim para o início
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
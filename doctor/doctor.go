@@ -0,0 +1,286 @@
+// Package doctor implementa as verificações de ambiente do comando doctor.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/policy"
+)
+
+// Status é o resultado de uma verificação
+type Status int
+
+const (
+	Pass Status = iota
+	Warn
+	Fail
+	Skip
+)
+
+// String retorna o nome do status
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "ok"
+	case Warn:
+		return "aviso"
+	case Fail:
+		return "falha"
+	}
+	return "ignorado"
+}
+
+// Result descreve o resultado de uma verificação e, se houver problema, como resolvê-lo
+type Result struct {
+	Name    string
+	Status  Status
+	Message string
+	Hint    string
+}
+
+// Endpoints suportados pela URL da API
+const (
+	EndpointGenerate = "generate"
+	EndpointChat     = "chat"
+	EndpointOpenAI   = "openai"
+	EndpointUnknown  = "desconhecido"
+)
+
+// SlowThreshold é a latência a partir da qual a verificação de ida e volta gera um aviso
+var SlowThreshold = 10 * time.Second
+
+// CheckConfigFile informa se o arquivo de configuração foi carregado; err é o erro de carregamento
+func CheckConfigFile(path string, err error) Result {
+	r := Result{Name: "Arquivo de configuração"}
+	switch {
+	case err != nil:
+		r.Status = Fail
+		r.Message = err.Error()
+		r.Hint = "Corrija o arquivo ou indique outro com --config"
+	case path == "":
+		r.Status = Pass
+		r.Message = "nenhum arquivo encontrado; usando flags e variáveis de ambiente"
+	default:
+		r.Status = Pass
+		r.Message = fmt.Sprintf("%s é válido", path)
+	}
+	return r
+}
+
+// EnvConflict descreve uma variável de ambiente que pode contrariar o valor esperado
+type EnvConflict struct {
+	Env   string // Variável de ambiente
+	Flag  string // Flag afetada
+	Value string // Valor que a flag (ou o arquivo de configuração) define
+}
+
+// CheckEnv avisa sobre variáveis de ambiente que se sobrepõem à configuração.
+// MODEL_NAME, por exemplo, tem precedência sobre --model em todas as chamadas à API.
+func CheckEnv(conflicts []EnvConflict, getenv func(string) string) Result {
+	r := Result{Name: "Variáveis de ambiente", Status: Pass, Message: "sem conflitos"}
+
+	var msgs []string
+	for _, c := range conflicts {
+		env := getenv(c.Env)
+		if env == "" || env == c.Value {
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("%s=%s se sobrepõe a --%s=%s", c.Env, env, c.Flag, c.Value))
+	}
+
+	if host := getenv("OLLAMA_HOST"); host != "" && getenv("OLLAMA_API_URL") == "" {
+		msgs = append(msgs, fmt.Sprintf("OLLAMA_HOST=%s é ignorado; o Code Explainer usa OLLAMA_API_URL", host))
+	}
+
+	if len(msgs) > 0 {
+		r.Status = Warn
+		r.Message = strings.Join(msgs, "; ")
+		r.Hint = "Remova a variável (unset) ou alinhe seu valor com a flag"
+	}
+	return r
+}
+
+// EndpointKind identifica o tipo de endpoint pela URL configurada
+func EndpointKind(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return EndpointUnknown
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	switch {
+	case strings.HasSuffix(path, "/api/generate"):
+		return EndpointGenerate
+	case strings.HasSuffix(path, "/api/chat"):
+		return EndpointChat
+	case strings.Contains(path, "/v1"):
+		return EndpointOpenAI
+	}
+	return EndpointUnknown
+}
+
+// CheckEndpoint verifica se a URL aponta para um endpoint que o cliente sabe usar
+func CheckEndpoint(apiURL string) Result {
+	r := Result{Name: "Tipo de endpoint"}
+	generate := openai.BaseURL(apiURL) + "/api/generate"
+
+	switch kind := EndpointKind(apiURL); kind {
+	case EndpointGenerate:
+		r.Status = Pass
+		r.Message = "Ollama /api/generate"
+	case EndpointChat:
+		r.Status = Fail
+		r.Message = "a URL aponta para /api/chat, mas as explicações usam /api/generate (o chat é derivado dela)"
+		r.Hint = "Use --api-url " + generate
+	case EndpointOpenAI:
+		// A listagem de modelos tem fallback para /v1/models; as explicações não
+		r.Status = Warn
+		r.Message = "API compatível com OpenAI: models list funciona (via /v1/models), mas explain, chat e os demais comandos usam o formato do Ollama e vão falhar"
+		r.Hint = "Para explicar código, use o endpoint nativo do Ollama: --api-url " + generate
+	default:
+		r.Status = Fail
+		r.Message = fmt.Sprintf("não foi possível identificar o endpoint em %s", apiURL)
+		r.Hint = "Use a URL completa do endpoint, por exemplo --api-url " + generate
+	}
+	return r
+}
+
+// CheckPolicy verifica se a política de destinos permite a URL da API
+func CheckPolicy(p policy.Policy, apiURL string) Result {
+	r := Result{Name: "Política de destinos"}
+	if !p.Active() {
+		r.Status = Pass
+		r.Message = "nenhuma restrição configurada"
+		return r
+	}
+
+	if err := p.Check(apiURL); err != nil {
+		r.Status = Fail
+		r.Message = err.Error()
+		r.Hint = "Use um servidor local ou inclua o host em policy.allowed_hosts"
+		return r
+	}
+	r.Status = Pass
+	r.Message = "a URL da API é permitida pela política"
+	return r
+}
+
+// CheckReachable verifica se o servidor responde na URL base
+func CheckReachable(ctx context.Context, config *openai.Config) Result {
+	r := Result{Name: "Conexão com a API"}
+	base := openai.BaseURL(config.APIURL)
+
+	ctx, cancel := context.WithTimeout(openai.WithGuard(ctx, config.Guard), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/", nil)
+	if err != nil {
+		r.Status = Fail
+		r.Message = fmt.Sprintf("URL inválida: %v", err)
+		r.Hint = "Corrija --api-url ou OLLAMA_API_URL"
+		return r
+	}
+
+	start := time.Now()
+	resp, err := openai.HTTPClient().Do(req)
+	if err != nil {
+		r.Status = Fail
+		r.Message = fmt.Sprintf("%s inacessível: %v", base, err)
+		r.Hint = "Inicie o Ollama com 'ollama serve' ou ajuste --api-url / OLLAMA_API_URL"
+		return r
+	}
+	resp.Body.Close()
+
+	r.Status = Pass
+	r.Message = fmt.Sprintf("%s respondeu (HTTP %d) em %s", base, resp.StatusCode, time.Since(start).Round(time.Millisecond))
+	return r
+}
+
+// CheckModel verifica se o modelo configurado está instalado no servidor
+func CheckModel(ctx context.Context, config *openai.Config, model string) Result {
+	r := Result{Name: "Modelo"}
+
+	models, err := openai.ListModels(ctx, config)
+	if err != nil {
+		r.Status = Warn
+		r.Message = fmt.Sprintf("não foi possível listar os modelos: %v", err)
+		r.Hint = "Confira manualmente com 'ollama list'"
+		return r
+	}
+
+	for _, m := range models {
+		if m.Name == model || strings.TrimSuffix(m.Name, ":latest") == model {
+			r.Status = Pass
+			r.Message = fmt.Sprintf("%s está instalado", m.Name)
+			return r
+		}
+	}
+
+	r.Status = Fail
+	r.Message = fmt.Sprintf("o modelo %s não está instalado (%d modelos disponíveis)", model, len(models))
+	r.Hint = fmt.Sprintf("Baixe-o com 'code-explainer models pull %s' ou escolha outro com --model (veja 'code-explainer list models')", model)
+	return r
+}
+
+// CheckLatency mede o tempo de ida e volta de um prompt mínimo
+func CheckLatency(ctx context.Context, config *openai.Config) Result {
+	r := Result{Name: "Latência"}
+
+	start := time.Now()
+	_, err := openai.Generate(ctx, "Responda apenas com a palavra: ok", config)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		r.Status = Fail
+		r.Message = fmt.Sprintf("o prompt de teste falhou após %s: %v", elapsed, err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			r.Hint = "O modelo pode estar sendo carregado; rode 'code-explainer models warm' ou aumente --timeout"
+		} else {
+			r.Hint = "Veja os logs do Ollama ('ollama serve') para mais detalhes"
+		}
+		return r
+	}
+
+	r.Message = fmt.Sprintf("prompt de teste respondido em %s", elapsed)
+	r.Status = Pass
+	if elapsed >= SlowThreshold {
+		r.Status = Warn
+		r.Hint = "Respostas lentas podem estourar --timeout; rode 'code-explainer models warm' antes de lotes ou use um modelo menor"
+	}
+	return r
+}
+
+// CheckDir verifica se é possível criar e gravar arquivos no diretório
+func CheckDir(name, dir string) Result {
+	r := Result{Name: name}
+
+	fail := func(err error) Result {
+		r.Status = Fail
+		r.Message = fmt.Sprintf("%s não é gravável: %v", dir, err)
+		r.Hint = fmt.Sprintf("Ajuste as permissões (chmod u+rwx %s) ou aponte o diretório para outro local", dir)
+		return r
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fail(err)
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return fail(err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	r.Status = Pass
+	r.Message = fmt.Sprintf("%s é gravável", filepath.Clean(dir))
+	return r
+}
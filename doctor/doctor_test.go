@@ -0,0 +1,211 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/policy"
+)
+
+func TestEndpointKind(t *testing.T) {
+	tests := []struct {
+		apiURL   string
+		expected string
+	}{
+		{apiURL: "http://localhost:11434/api/generate", expected: EndpointGenerate},
+		{apiURL: "http://localhost:11434/api/chat/", expected: EndpointChat},
+		{apiURL: "http://localhost:8000/v1/chat/completions", expected: EndpointOpenAI},
+		{apiURL: "http://localhost:11434", expected: EndpointUnknown},
+		{apiURL: "://", expected: EndpointUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.apiURL, func(t *testing.T) {
+			if got := EndpointKind(tt.apiURL); got != tt.expected {
+				t.Errorf("EndpointKind() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	tests := []struct {
+		apiURL   string
+		expected Status
+	}{
+		{apiURL: "http://localhost:11434/api/generate", expected: Pass},
+		{apiURL: "http://localhost:11434/api/chat", expected: Fail},
+		{apiURL: "http://localhost:8000/v1/chat/completions", expected: Warn},
+		{apiURL: "http://localhost:11434", expected: Fail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.apiURL, func(t *testing.T) {
+			r := CheckEndpoint(tt.apiURL)
+			if r.Status != tt.expected {
+				t.Errorf("CheckEndpoint() = %v (%s), want %v", r.Status, r.Message, tt.expected)
+			}
+			if r.Status != Pass && !strings.Contains(r.Hint, "/api/generate") {
+				t.Errorf("CheckEndpoint() hint = %q, want the Ollama endpoint", r.Hint)
+			}
+		})
+	}
+}
+
+func TestCheckEnv(t *testing.T) {
+	conflicts := []EnvConflict{{Env: "MODEL_NAME", Flag: "model", Value: "codellama"}}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected Status
+		message  string
+	}{
+		{name: "Sem variáveis", env: map[string]string{}, expected: Pass},
+		{name: "Mesmo valor", env: map[string]string{"MODEL_NAME": "codellama"}, expected: Pass},
+		{name: "MODEL_NAME diferente", env: map[string]string{"MODEL_NAME": "llama2"}, expected: Warn, message: "MODEL_NAME=llama2 se sobrepõe a --model=codellama"},
+		{name: "OLLAMA_HOST", env: map[string]string{"OLLAMA_HOST": "0.0.0.0:11434"}, expected: Warn, message: "OLLAMA_HOST=0.0.0.0:11434 é ignorado"},
+		{name: "OLLAMA_HOST com OLLAMA_API_URL", env: map[string]string{"OLLAMA_HOST": "x", "OLLAMA_API_URL": "http://x/api/generate"}, expected: Pass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := CheckEnv(conflicts, func(k string) string { return tt.env[k] })
+			if r.Status != tt.expected {
+				t.Errorf("CheckEnv() status = %v, want %v (%s)", r.Status, tt.expected, r.Message)
+			}
+			if !strings.Contains(r.Message, tt.message) {
+				t.Errorf("CheckEnv() message = %q, want contendo %q", r.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	if r := CheckConfigFile("", nil); r.Status != Pass {
+		t.Errorf("sem arquivo: status = %v, want ok", r.Status)
+	}
+	if r := CheckConfigFile(".code-explainer.yaml", errors.New("field modle not found")); r.Status != Fail || r.Hint == "" {
+		t.Errorf("arquivo inválido: %+v, want falha com dica", r)
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	deny := false
+	p := policy.Policy{AllowRemote: &deny, AllowedHosts: []string{"ollama.interno:11434"}}
+
+	tests := []struct {
+		name     string
+		policy   policy.Policy
+		apiURL   string
+		expected Status
+	}{
+		{name: "Sem política", apiURL: "https://api.openai.com/v1/chat/completions", expected: Pass},
+		{name: "Servidor local", policy: p, apiURL: "http://localhost:11434/api/generate", expected: Pass},
+		{name: "Host permitido", policy: p, apiURL: "http://ollama.interno:11434/api/generate", expected: Pass},
+		{name: "Host remoto", policy: p, apiURL: "https://api.openai.com/v1/chat/completions", expected: Fail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := CheckPolicy(tt.policy, tt.apiURL); r.Status != tt.expected {
+				t.Errorf("CheckPolicy() status = %v, want %v (%s)", r.Status, tt.expected, r.Message)
+			}
+		})
+	}
+}
+
+func TestServerChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte("Ollama is running"))
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"codellama:latest"},{"name":"llama2:7b"}]}`))
+		case "/api/generate":
+			w.Write([]byte(`{"response":"ok","done":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := &openai.Config{APIURL: server.URL + "/api/generate", Model: "codellama", Timeout: 5 * time.Second}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		result   Result
+		expected Status
+	}{
+		{name: "Conexão", result: CheckReachable(ctx, config), expected: Pass},
+		{name: "Endpoint", result: CheckEndpoint(config.APIURL), expected: Pass},
+		{name: "Modelo instalado (:latest)", result: CheckModel(ctx, config, "codellama"), expected: Pass},
+		{name: "Modelo ausente", result: CheckModel(ctx, config, "codellama:13b"), expected: Fail},
+		{name: "Latência", result: CheckLatency(ctx, config), expected: Pass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.result.Status != tt.expected {
+				t.Errorf("status = %v, want %v (%s)", tt.result.Status, tt.expected, tt.result.Message)
+			}
+			if tt.result.Status == Fail && tt.result.Hint == "" {
+				t.Error("falha sem dica de correção")
+			}
+		})
+	}
+}
+
+func TestCheckUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	config := &openai.Config{APIURL: server.URL + "/api/generate", Timeout: time.Second}
+	r := CheckReachable(context.Background(), config)
+	if r.Status != Fail || !strings.Contains(r.Hint, "ollama serve") {
+		t.Errorf("CheckReachable() = %+v, want falha com dica 'ollama serve'", r)
+	}
+}
+
+func TestCheckLatencyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := &openai.Config{APIURL: server.URL + "/api/generate", Timeout: 50 * time.Millisecond}
+	r := CheckLatency(context.Background(), config)
+	if r.Status != Fail || !strings.Contains(r.Hint, "models warm") {
+		t.Errorf("CheckLatency() = %+v, want falha com dica 'models warm'", r)
+	}
+}
+
+func TestCheckDir(t *testing.T) {
+	dir := t.TempDir()
+	if r := CheckDir("Cache", filepath.Join(dir, "cache")); r.Status != Pass {
+		t.Errorf("CheckDir() = %+v, want ok", r)
+	}
+
+	if os.Getuid() == 0 {
+		t.Skip("root ignora permissões de diretório")
+	}
+	readOnly := filepath.Join(dir, "ro")
+	os.Mkdir(readOnly, 0o555)
+	if r := CheckDir("Cache", readOnly); r.Status != Fail {
+		t.Errorf("CheckDir() em diretório somente leitura = %+v, want falha", r)
+	}
+}
@@ -0,0 +1,300 @@
+// Package gitdiff interpreta diffs unificados (como os gerados por git diff)
+// e obtém diffs e commits de um repositório git local.
+package gitdiff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// devNull é o caminho usado pelo git para arquivos criados ou removidos
+const devNull = "/dev/null"
+
+// hunkHeader reconhece cabeçalhos como "@@ -1,4 +1,6 @@ func main() {"
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// File representa as alterações de um arquivo
+type File struct {
+	OldPath string
+	NewPath string
+	IsNew   bool
+	Deleted bool
+	Binary  bool
+	Hunks   []Hunk
+}
+
+// Hunk representa um trecho alterado de um arquivo
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Section  string   // Contexto exibido após o cabeçalho (ex.: assinatura da função)
+	Lines    []string // Linhas do trecho, com o prefixo ' ', '+' ou '-'
+	Language string   // Linguagem detectada pelo nome do arquivo ou pelo conteúdo
+}
+
+// Path retorna o caminho mais relevante do arquivo (o antigo, se ele foi removido)
+func (f File) Path() string {
+	if f.Deleted || f.NewPath == "" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// Language retorna a linguagem do arquivo, com base no nome ou no primeiro trecho
+func (f File) Language() string {
+	if lang := openai.LanguageFromFilename(f.Path()); lang != "" {
+		return lang
+	}
+	for _, h := range f.Hunks {
+		if h.Language != "" {
+			return h.Language
+		}
+	}
+	return "linguagem desconhecida"
+}
+
+// Stats retorna a quantidade de linhas adicionadas e removidas
+func (f File) Stats() (added, removed int) {
+	for _, h := range f.Hunks {
+		for _, line := range h.Lines {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				added++
+			case strings.HasPrefix(line, "-"):
+				removed++
+			}
+		}
+	}
+	return added, removed
+}
+
+// Text reconstrói os trechos do arquivo no formato de diff unificado, com os cabeçalhos --- e +++
+func (f File) Text() string {
+	var b strings.Builder
+	oldPath, newPath := "a/"+f.OldPath, "b/"+f.NewPath
+	if f.IsNew || f.OldPath == "" {
+		oldPath = devNull
+	}
+	if f.Deleted || f.NewPath == "" {
+		newPath = devNull
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldPath, newPath)
+	for _, h := range f.Hunks {
+		b.WriteString(h.Text())
+	}
+	return b.String()
+}
+
+// Text reconstrói o trecho no formato de diff unificado
+func (h Hunk) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+	if h.Section != "" {
+		b.WriteString(" " + h.Section)
+	}
+	b.WriteString("\n")
+	for _, line := range h.Lines {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// Parse interpreta um diff unificado. Aceita tanto a saída de git diff/git show
+// quanto patches gerados por diff -u. Linhas fora de arquivos (ex.: mensagem de
+// commit) são ignoradas.
+func Parse(r io.Reader) ([]File, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	var files []File
+	var current *File
+	var hunk *Hunk
+	oldLeft, newLeft := 0, 0
+	lineNo := 0
+
+	flushHunk := func() {
+		if hunk != nil && current != nil {
+			hunk.Language = hunkLanguage(current, hunk)
+			current.Hunks = append(current.Hunks, *hunk)
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if current != nil {
+			files = append(files, *current)
+		}
+		current = nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNo++
+
+		// Dentro de um trecho, as contagens do cabeçalho dizem quantas linhas pertencem a ele
+		if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, " "), line == "":
+				oldLeft--
+				newLeft--
+				if line == "" {
+					line = " "
+				}
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+			default:
+				return files, fmt.Errorf("linha %d: trecho incompleto", lineNo)
+			}
+			hunk.Lines = append(hunk.Lines, line)
+			continue
+		}
+		if hunk != nil && strings.HasPrefix(line, `\`) {
+			hunk.Lines = append(hunk.Lines, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			current = &File{}
+			if a, b, ok := parseGitHeader(strings.TrimPrefix(line, "diff --git ")); ok {
+				current.OldPath, current.NewPath = a, b
+			}
+
+		case strings.HasPrefix(line, "--- "):
+			path := parsePathLine(strings.TrimPrefix(line, "--- "))
+			// Patches sem cabeçalho "diff --git" começam diretamente em "---"
+			if current == nil || len(current.Hunks) > 0 || hunk != nil {
+				flushFile()
+				current = &File{}
+			}
+			if path == devNull {
+				current.IsNew = true
+			} else {
+				current.OldPath = path
+			}
+
+		case strings.HasPrefix(line, "+++ ") && current != nil:
+			path := parsePathLine(strings.TrimPrefix(line, "+++ "))
+			if path == devNull {
+				current.Deleted = true
+			} else {
+				current.NewPath = path
+			}
+
+		case strings.HasPrefix(line, "@@ ") && current != nil:
+			flushHunk()
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return files, fmt.Errorf("linha %d: cabeçalho de trecho inválido: %s", lineNo, line)
+			}
+			hunk = &Hunk{
+				OldStart: atoi(m[1]),
+				OldLines: atoiDefault(m[2], 1),
+				NewStart: atoi(m[3]),
+				NewLines: atoiDefault(m[4], 1),
+				Section:  m[5],
+			}
+			oldLeft, newLeft = hunk.OldLines, hunk.NewLines
+
+		case current != nil && strings.HasPrefix(line, "new file mode"):
+			current.IsNew = true
+
+		case current != nil && strings.HasPrefix(line, "deleted file mode"):
+			current.Deleted = true
+
+		case current != nil && strings.HasPrefix(line, "rename from "):
+			current.OldPath = strings.TrimPrefix(line, "rename from ")
+
+		case current != nil && strings.HasPrefix(line, "rename to "):
+			current.NewPath = strings.TrimPrefix(line, "rename to ")
+
+		case current != nil && strings.HasPrefix(line, "Binary files "):
+			current.Binary = true
+
+		default:
+			// Fim do trecho atual; linhas extras (ex.: "index ...") são ignoradas
+			flushHunk()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return files, err
+	}
+	flushFile()
+
+	return files, nil
+}
+
+// parseGitHeader extrai os caminhos de "a/arquivo b/arquivo"
+func parseGitHeader(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, "a/") {
+		return "", "", false
+	}
+	idx := strings.Index(s, " b/")
+	if idx == -1 {
+		return "", "", false
+	}
+	return s[2:idx], s[idx+3:], true
+}
+
+// parsePathLine extrai o caminho das linhas "---" e "+++", removendo prefixos
+// a/ e b/ e o horário que diff -u acrescenta após um tab
+func parsePathLine(s string) string {
+	if idx := strings.Index(s, "\t"); idx != -1 {
+		s = s[:idx]
+	}
+	s = strings.TrimSpace(s)
+	if s == devNull {
+		return s
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		return s[2:]
+	}
+	return s
+}
+
+// hunkLanguage detecta a linguagem do trecho pelo nome do arquivo ou, se não for
+// possível, pelo código adicionado e de contexto
+func hunkLanguage(f *File, h *Hunk) string {
+	if lang := openai.LanguageFromFilename(f.Path()); lang != "" {
+		return lang
+	}
+
+	var code strings.Builder
+	for _, line := range h.Lines {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, " ") {
+			code.WriteString(line[1:] + "\n")
+		}
+	}
+
+	lang := openai.DetectLanguage(code.String())
+	if lang == "linguagem desconhecida" {
+		return ""
+	}
+	return lang
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	return atoi(s)
+}
@@ -0,0 +1,234 @@
+package gitdiff
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const samplePatch = `diff --git a/main.go b/main.go
+index 3b18e51..a5c1966 100644
+--- a/main.go
++++ b/main.go
+@@ -1,5 +1,6 @@ package main
+ import "fmt"
+
+ func main() {
+-	fmt.Println("hello")
++	defer fmt.Println("bye")
++	fmt.Println("hello, world")
+ }
+diff --git a/scripts/run.py b/scripts/run.py
+new file mode 100644
+index 0000000..e69de29
+--- /dev/null
++++ b/scripts/run.py
+@@ -0,0 +1,2 @@
++def run():
++    print("ok")
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index e69de29..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-conteúdo
+diff --git a/logo.png b/logo.png
+index 1111111..2222222 100644
+Binary files a/logo.png and b/logo.png differ
+`
+
+func TestParse(t *testing.T) {
+	files, err := Parse(strings.NewReader(samplePatch))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		language string
+		isNew    bool
+		deleted  bool
+		binary   bool
+		hunks    int
+		added    int
+		removed  int
+	}{
+		{path: "main.go", language: "Go", hunks: 1, added: 2, removed: 1},
+		{path: "scripts/run.py", language: "Python", isNew: true, hunks: 1, added: 2},
+		{path: "old.txt", language: "linguagem desconhecida", deleted: true, hunks: 1, removed: 1},
+		{path: "logo.png", language: "linguagem desconhecida", binary: true},
+	}
+
+	if len(files) != len(tests) {
+		t.Fatalf("Expected %d files, got %d", len(tests), len(files))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			f := files[i]
+			if f.Path() != tt.path {
+				t.Errorf("Path() = %v, want %v", f.Path(), tt.path)
+			}
+			if f.Language() != tt.language {
+				t.Errorf("Language() = %v, want %v", f.Language(), tt.language)
+			}
+			if f.IsNew != tt.isNew || f.Deleted != tt.deleted || f.Binary != tt.binary {
+				t.Errorf("Unexpected flags: new=%v deleted=%v binary=%v", f.IsNew, f.Deleted, f.Binary)
+			}
+			if len(f.Hunks) != tt.hunks {
+				t.Errorf("Expected %d hunks, got %d", tt.hunks, len(f.Hunks))
+			}
+			added, removed := f.Stats()
+			if added != tt.added || removed != tt.removed {
+				t.Errorf("Stats() = +%d -%d, want +%d -%d", added, removed, tt.added, tt.removed)
+			}
+		})
+	}
+
+	hunk := files[0].Hunks[0]
+	if hunk.OldStart != 1 || hunk.OldLines != 5 || hunk.NewStart != 1 || hunk.NewLines != 6 {
+		t.Errorf("Unexpected hunk range: %+v", hunk)
+	}
+	if hunk.Section != "package main" {
+		t.Errorf("Expected section 'package main', got %q", hunk.Section)
+	}
+	if hunk.Language != "Go" {
+		t.Errorf("Expected hunk language Go, got %q", hunk.Language)
+	}
+
+	expected := "--- /dev/null\n+++ b/scripts/run.py\n@@ -0,0 +1,2 @@\n+def run():\n+    print(\"ok\")\n"
+	if text := files[1].Text(); text != expected {
+		t.Errorf("Text() = %q, want %q", text, expected)
+	}
+}
+
+func TestParsePlainUnifiedDiff(t *testing.T) {
+	// Patch gerado por diff -u, sem cabeçalho "diff --git", com linha removida que começa com "--"
+	patch := `--- a/util.c	2024-01-01 10:00:00
++++ b/util.c	2024-01-02 10:00:00
+@@ -1,3 +1,3 @@
+ #include <stdio.h>
+--- i;
++++i;
+ int x;
+--- lib.js
++++ lib.js
+@@ -1 +1 @@
+-var a = 1;
++const a = 1;
+`
+
+	files, err := Parse(strings.NewReader(patch))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if files[0].Path() != "util.c" || files[0].Language() != "C" {
+		t.Errorf("Unexpected first file: %s (%s)", files[0].Path(), files[0].Language())
+	}
+	if added, removed := files[0].Stats(); added != 1 || removed != 1 {
+		t.Errorf("Expected +1 -1 in util.c, got +%d -%d", added, removed)
+	}
+	if files[1].Path() != "lib.js" || files[1].Language() != "JavaScript" {
+		t.Errorf("Unexpected second file: %s (%s)", files[1].Path(), files[1].Language())
+	}
+}
+
+func TestParseInvalidHunk(t *testing.T) {
+	patch := "--- a/x.go\n+++ b/x.go\n@@ -1,2 +1,2 @@\n context\nlixo\n"
+
+	if _, err := Parse(strings.NewReader(patch)); err == nil {
+		t.Errorf("Expected error for truncated hunk")
+	}
+}
+
+func TestBuildPrompt(t *testing.T) {
+	files, _ := Parse(strings.NewReader(samplePatch))
+	prompt := BuildPrompt(files[0], "Adiciona mensagem de despedida", "")
+
+	for _, expected := range []string{"main.go (Go)", "Mensagem do commit:", "Adiciona mensagem de despedida", `+	defer fmt.Println("bye")`, "Trecho 1 (Go)"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected prompt to contain %q", expected)
+		}
+	}
+}
+
+// newTestRepo cria um repositório git temporário com um commit inicial
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git não encontrado")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Teste", "GIT_AUTHOR_EMAIL=teste@example.com",
+			"GIT_COMMITTER_NAME=Teste", "GIT_COMMITTER_EMAIL=teste@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	git("init", "-q")
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644)
+	git("add", ".")
+	git("commit", "-q", "-m", "Commit inicial")
+
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"oi\")\n}\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "app.py"), []byte("print('oi')\n"), 0o644)
+	git("add", ".")
+	git("commit", "-q", "-m", "Imprime saudação", "-m", "Corpo explicando o motivo.")
+
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644)
+
+	return dir
+}
+
+func TestGitDiffAndShow(t *testing.T) {
+	dir := newTestRepo(t)
+	ctx := context.Background()
+
+	files, err := Diff(ctx, dir)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(files) != 1 || files[0].Path() != "main.go" {
+		t.Fatalf("Expected working tree change in main.go, got %+v", files)
+	}
+	if _, removed := files[0].Stats(); removed == 0 {
+		t.Errorf("Expected removed lines in working tree diff")
+	}
+
+	commit, err := Show(ctx, dir, "HEAD")
+	if err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+	if commit.Subject != "Imprime saudação" || commit.Body != "Corpo explicando o motivo." {
+		t.Errorf("Unexpected commit message: %q / %q", commit.Subject, commit.Body)
+	}
+	if !strings.Contains(commit.Author, "teste@example.com") {
+		t.Errorf("Unexpected author: %s", commit.Author)
+	}
+	if len(commit.Files) != 2 {
+		t.Fatalf("Expected 2 files in commit, got %d", len(commit.Files))
+	}
+	if commit.Files[0].Path() != "app.py" || !commit.Files[0].IsNew || commit.Files[0].Language() != "Python" {
+		t.Errorf("Unexpected first commit file: %+v", commit.Files[0])
+	}
+
+	if _, err := Show(ctx, dir, "nao-existe"); err == nil {
+		t.Errorf("Expected error for unknown revision")
+	}
+}
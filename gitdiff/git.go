@@ -0,0 +1,87 @@
+package gitdiff
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Commit reúne os metadados e as alterações de um commit
+type Commit struct {
+	Hash    string
+	Author  string
+	Subject string
+	Body    string
+	Files   []File
+}
+
+// Message retorna a mensagem completa do commit
+func (c Commit) Message() string {
+	if c.Body == "" {
+		return c.Subject
+	}
+	return c.Subject + "\n\n" + c.Body
+}
+
+// Diff executa git diff no diretório informado (vazio = diretório atual),
+// repassando args (ex.: "--staged", "HEAD~1") e interpreta a saída
+func Diff(ctx context.Context, dir string, args ...string) ([]File, error) {
+	out, err := runGit(ctx, dir, append([]string{"diff", "--no-color", "--no-ext-diff"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(strings.NewReader(out))
+}
+
+// Show obtém os metadados e as alterações do commit rev
+func Show(ctx context.Context, dir, rev string) (*Commit, error) {
+	// Separadores NUL evitam ambiguidade com o conteúdo da mensagem
+	meta, err := runGit(ctx, dir, "show", "-s", "--format=%H%x00%an <%ae>%x00%s%x00%b", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.SplitN(strings.TrimRight(meta, "\n"), "\x00", 4)
+	if len(parts) < 4 {
+		return nil, fmt.Errorf("saída inesperada do git show para %s", rev)
+	}
+
+	out, err := runGit(ctx, dir, "show", "--no-color", "--no-ext-diff", "--format=", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+	files, err := Parse(strings.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Commit{
+		Hash:    parts[0],
+		Author:  parts[1],
+		Subject: parts[2],
+		Body:    strings.TrimSpace(parts[3]),
+		Files:   files,
+	}, nil
+}
+
+// runGit executa o git e retorna a saída padrão, incluindo a saída de erro na mensagem em caso de falha
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+
+	return stdout.String(), nil
+}
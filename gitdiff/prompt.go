@@ -0,0 +1,47 @@
+package gitdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// BuildPrompt monta o prompt que pede ao modelo para descrever as alterações de
+// um arquivo. message é a mensagem do commit, quando houver, usada como contexto.
+func BuildPrompt(f File, message, level string) string {
+	var b strings.Builder
+
+	if instructions := openai.LevelInstructions(level); instructions != "" {
+		b.WriteString(instructions + "\n\n")
+	}
+
+	added, removed := f.Stats()
+	fmt.Fprintf(&b, "Descreva o que mudou no arquivo %s (%s) e por que essa mudança importa.\n", f.Path(), f.Language())
+	b.WriteString("Explique o comportamento antes e depois, e aponte riscos ou impactos para quem revisa o código.\n")
+
+	switch {
+	case f.IsNew:
+		b.WriteString("O arquivo foi criado neste diff.\n")
+	case f.Deleted:
+		b.WriteString("O arquivo foi removido neste diff.\n")
+	case f.OldPath != "" && f.NewPath != "" && f.OldPath != f.NewPath:
+		fmt.Fprintf(&b, "O arquivo foi renomeado de %s.\n", f.OldPath)
+	}
+	fmt.Fprintf(&b, "Linhas adicionadas: %d, removidas: %d.\n", added, removed)
+
+	if message != "" {
+		fmt.Fprintf(&b, "\nMensagem do commit:\n%s\n", message)
+	}
+
+	b.WriteString("\nDiff:\n")
+	for i, h := range f.Hunks {
+		lang := h.Language
+		if lang == "" {
+			lang = "linguagem desconhecida"
+		}
+		fmt.Fprintf(&b, "\nTrecho %d (%s):\n```diff\n%s```\n", i+1, lang, h.Text())
+	}
+
+	return b.String()
+}
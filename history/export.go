@@ -0,0 +1,61 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Formatos de exportação suportados
+const (
+	FormatJSON     = "json"
+	FormatJSONL    = "jsonl"
+	FormatMarkdown = "markdown"
+)
+
+// GetExportFormats retorna os formatos de exportação suportados
+func GetExportFormats() []string {
+	return []string{FormatJSON, FormatJSONL, FormatMarkdown}
+}
+
+// Export escreve as entradas em w no formato informado
+func Export(w io.Writer, entries []Entry, format string) error {
+	switch format {
+	case FormatJSON:
+		if entries == nil {
+			entries = []Entry{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatMarkdown:
+		var b strings.Builder
+		b.WriteString("# Histórico de explicações\n")
+		for _, e := range entries {
+			fmt.Fprintf(&b, "\n## %s — %s\n\n", e.ID, e.Time.Format(time.DateTime))
+			fmt.Fprintf(&b, "- **Linguagem:** %s\n", e.Language)
+			fmt.Fprintf(&b, "- **Modelo:** %s\n", e.Model)
+			fmt.Fprintf(&b, "- **Duração:** %dms\n\n", e.DurationMs)
+			b.WriteString("```\n" + e.Code + "\n```\n\n")
+			b.WriteString(e.Result + "\n")
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	return fmt.Errorf("formato de exportação inválido: %s (use %s)", format, strings.Join(GetExportFormats(), ", "))
+}
@@ -0,0 +1,297 @@
+// Package history mantém um registro local, em JSONL somente-anexação, das
+// explicações geradas, para consulta posterior sem nova chamada ao modelo.
+package history
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// previewLength é o número máximo de caracteres da prévia do código
+const previewLength = 80
+
+// ErrNotFound indica que nenhuma entrada corresponde ao ID informado
+var ErrNotFound = errors.New("entrada não encontrada no histórico")
+
+// Entry é uma explicação registrada no histórico
+type Entry struct {
+	ID         string    `json:"id"`
+	Time       time.Time `json:"time"`
+	Command    string    `json:"command,omitempty"` // Comando que gerou a entrada (explain, diff, review, chat...)
+	Hash       string    `json:"hash"`              // sha256 do código
+	Preview    string    `json:"preview"`
+	Code       string    `json:"code"`
+	Language   string    `json:"language,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	Level      string    `json:"level,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Cached     bool      `json:"cached,omitempty"`
+	Result     string    `json:"result"`
+
+	// Métricas informadas pelo servidor; zeradas em respostas do cache
+	PromptTokens     int   `json:"prompt_tokens,omitempty"`
+	CompletionTokens int   `json:"completion_tokens,omitempty"`
+	EvalMs           int64 `json:"eval_ms,omitempty"` // Tempo de geração da resposta
+}
+
+// Store é o arquivo de histórico
+type Store struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// DefaultPath retorna o caminho padrão do histórico: $XDG_DATA_HOME/code-explainer/history.jsonl,
+// ou ~/.local/share/code-explainer/history.jsonl
+func DefaultPath() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("não foi possível determinar o diretório de dados: %w", err)
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, "code-explainer", "history.jsonl"), nil
+}
+
+// New cria um Store para o arquivo informado
+func New(path string) *Store {
+	return &Store{Path: path}
+}
+
+// NewEntry cria uma entrada preenchendo ID, hash e prévia a partir do código
+func NewEntry(code, result string, duration time.Duration) Entry {
+	sum := sha256.Sum256([]byte(code))
+	now := time.Now()
+
+	idSum := sha256.Sum256([]byte(strconv.FormatInt(now.UnixNano(), 10) + hex.EncodeToString(sum[:])))
+
+	return Entry{
+		ID:         hex.EncodeToString(idSum[:])[:10],
+		Time:       now,
+		Hash:       hex.EncodeToString(sum[:]),
+		Preview:    Preview(code),
+		Code:       code,
+		DurationMs: duration.Milliseconds(),
+		Result:     result,
+	}
+}
+
+// Preview resume o código em uma única linha
+func Preview(code string) string {
+	preview := strings.Join(strings.Fields(code), " ")
+	if utf8.RuneCountInString(preview) > previewLength {
+		preview = string([]rune(preview)[:previewLength-3]) + "..."
+	}
+	return preview
+}
+
+// Append adiciona a entrada ao final do arquivo, criando-o se necessário
+func (s *Store) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// All retorna todas as entradas, da mais antiga para a mais recente.
+// Linhas inválidas (por exemplo, gravações interrompidas) são ignoradas.
+func (s *Store) All() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// Find retorna a entrada cujo ID começa com o prefixo informado
+func (s *Store) Find(prefix string) (Entry, error) {
+	entries, err := s.All()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	i, err := resolve(entries, prefix)
+	if err != nil {
+		return Entry{}, err
+	}
+	return entries[i], nil
+}
+
+// Search retorna as entradas cujo código, resultado, linguagem ou modelo contêm o termo (sem diferenciar maiúsculas)
+func (s *Store) Search(query string) ([]Entry, error) {
+	entries, err := s.All()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	var found []Entry
+	for _, e := range entries {
+		for _, field := range []string{e.Code, e.Result, e.Language, e.Model} {
+			if strings.Contains(strings.ToLower(field), query) {
+				found = append(found, e)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+// Delete remove as entradas indicadas pelos prefixos e retorna quantas foram removidas.
+// Como em Find, cada prefixo deve corresponder a exatamente uma entrada; caso contrário
+// nada é removido.
+func (s *Store) Delete(prefixes ...string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+
+	remove := make(map[int]bool, len(prefixes))
+	for _, prefix := range prefixes {
+		i, err := resolve(entries, prefix)
+		if err != nil {
+			return 0, err
+		}
+		remove[i] = true
+	}
+	if len(remove) == 0 {
+		return 0, nil
+	}
+
+	kept := make([]Entry, 0, len(entries)-len(remove))
+	for i, e := range entries {
+		if !remove[i] {
+			kept = append(kept, e)
+		}
+	}
+	return len(remove), s.rewrite(kept)
+}
+
+// Clear remove todas as entradas e retorna quantas foram removidas
+func (s *Store) Clear() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// Since filtra as entradas registradas a partir do instante informado
+func Since(entries []Entry, t time.Time) []Entry {
+	var filtered []Entry
+	for _, e := range entries {
+		if !e.Time.Before(t) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// read lê o arquivo; o chamador deve manter o mutex
+func (s *Store) read() ([]Entry, error) {
+	file, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.ID == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// rewrite substitui o arquivo de forma atômica; o chamador deve manter o mutex
+func (s *Store) rewrite(entries []Entry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".history-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// resolve retorna o índice da única entrada cujo ID começa com o prefixo
+func resolve(entries []Entry, prefix string) (int, error) {
+	found := -1
+	matches := 0
+	for i, e := range entries {
+		if strings.HasPrefix(e.ID, prefix) {
+			found = i
+			matches++
+		}
+	}
+
+	switch {
+	case prefix == "" || matches == 0:
+		return 0, fmt.Errorf("%w: %s", ErrNotFound, prefix)
+	case matches > 1:
+		return 0, fmt.Errorf("o prefixo %s corresponde a %d entradas; informe mais caracteres", prefix, matches)
+	}
+	return found, nil
+}
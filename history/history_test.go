@@ -0,0 +1,258 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	return New(filepath.Join(t.TempDir(), "sub", "history.jsonl"))
+}
+
+func TestAppendAndFind(t *testing.T) {
+	s := newTestStore(t)
+
+	e := NewEntry("func main() {\n\tfmt.Println(\"oi\")\n}", "Imprime oi.", 1500*time.Millisecond)
+	e.Language, e.Model = "Go", "codellama"
+	if err := s.Append(e); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	s.Append(NewEntry("print('oi')", "Imprime oi em Python.", time.Second))
+
+	entries, err := s.All()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("All() = %d entries, %v", len(entries), err)
+	}
+
+	got := entries[0]
+	if got.Preview != `func main() { fmt.Println("oi") }` || got.DurationMs != 1500 || len(got.Hash) != 64 {
+		t.Errorf("Unexpected entry: %+v", got)
+	}
+
+	found, err := s.Find(e.ID[:4])
+	if err != nil || found.Result != "Imprime oi." {
+		t.Errorf("Find() = %+v, %v", found, err)
+	}
+	if _, err := s.Find("zzzz"); err == nil {
+		t.Errorf("Expected error for unknown ID")
+	}
+
+	info, _ := os.Stat(s.Path)
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected history file to be private, got %v", info.Mode().Perm())
+	}
+}
+
+func TestSearchAndSince(t *testing.T) {
+	s := newTestStore(t)
+
+	old := NewEntry("SELECT * FROM users", "Consulta todos os usuários.", 0)
+	old.Time = time.Now().Add(-10 * 24 * time.Hour)
+	old.Language = "SQL"
+	s.Append(old)
+	s.Append(NewEntry("const x = 1", "Declara uma constante.", 0))
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{query: "usuários", expected: 1},
+		{query: "select", expected: 1},
+		{query: "sql", expected: 1},
+		{query: "constante", expected: 1},
+		{query: "rust", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			found, err := s.Search(tt.query)
+			if err != nil || len(found) != tt.expected {
+				t.Errorf("Search(%q) = %d entries (%v), want %d", tt.query, len(found), err, tt.expected)
+			}
+		})
+	}
+
+	all, _ := s.All()
+	if recent := Since(all, time.Now().Add(-7*24*time.Hour)); len(recent) != 1 {
+		t.Errorf("Since() = %d entries, want 1", len(recent))
+	}
+}
+
+func TestDeleteAndClear(t *testing.T) {
+	s := newTestStore(t)
+
+	var ids []string
+	for _, code := range []string{"a", "b", "c"} {
+		e := NewEntry(code, "r", 0)
+		ids = append(ids, e.ID)
+		s.Append(e)
+	}
+
+	// Linha corrompida deve ser ignorada e descartada na reescrita
+	f, _ := os.OpenFile(s.Path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("{incompleto\n")
+	f.Close()
+
+	removed, err := s.Delete(ids[1])
+	if err != nil || removed != 1 {
+		t.Fatalf("Delete() = %d, %v", removed, err)
+	}
+
+	entries, _ := s.All()
+	if len(entries) != 2 || entries[0].ID != ids[0] || entries[1].ID != ids[2] {
+		t.Errorf("Unexpected entries after delete: %+v", entries)
+	}
+
+	removed, err = s.Clear()
+	if err != nil || removed != 2 {
+		t.Errorf("Clear() = %d, %v", removed, err)
+	}
+	if entries, _ := s.All(); len(entries) != 0 {
+		t.Errorf("Expected empty history after Clear()")
+	}
+}
+
+func TestDeletePrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		removed  int
+		kept     int
+		wantErr  bool
+	}{
+		{name: "Prefixo único", prefixes: []string{"ab1"}, removed: 1, kept: 2},
+		{name: "Vários prefixos", prefixes: []string{"ab1", "cd"}, removed: 2, kept: 1},
+		{name: "Mesma entrada duas vezes", prefixes: []string{"ab1", "ab12"}, removed: 1, kept: 2},
+		{name: "Prefixo ambíguo", prefixes: []string{"ab"}, wantErr: true, kept: 3},
+		{name: "Prefixo inexistente", prefixes: []string{"cd", "ff"}, wantErr: true, kept: 3},
+		{name: "Prefixo vazio", prefixes: []string{""}, wantErr: true, kept: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			for _, id := range []string{"ab1234", "ab5678", "cd9012"} {
+				e := NewEntry(id, "r", 0)
+				e.ID = id
+				s.Append(e)
+			}
+
+			removed, err := s.Delete(tt.prefixes...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Delete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if removed != tt.removed {
+				t.Errorf("Delete() = %d, want %d", removed, tt.removed)
+			}
+			if entries, _ := s.All(); len(entries) != tt.kept {
+				t.Errorf("Expected %d entries after Delete(), got %d", tt.kept, len(entries))
+			}
+		})
+	}
+}
+
+func TestConcurrentAppend(t *testing.T) {
+	s := newTestStore(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Append(NewEntry(strings.Repeat("x", 1000), strings.Repeat("y", 1000), 0))
+		}()
+	}
+	wg.Wait()
+
+	if entries, _ := s.All(); len(entries) != 20 {
+		t.Errorf("Expected 20 entries, got %d", len(entries))
+	}
+}
+
+func TestPreview(t *testing.T) {
+	long := strings.Repeat("é", 200)
+	if got := Preview(long); len([]rune(got)) != previewLength || !strings.HasSuffix(got, "...") {
+		t.Errorf("Preview() = %q", got)
+	}
+}
+
+func TestExport(t *testing.T) {
+	entries := []Entry{NewEntry("a := 1", "Atribui 1 a a.", 0), NewEntry("<b>", "Tag HTML.", 0)}
+
+	var jsonOut bytes.Buffer
+	if err := Export(&jsonOut, entries, FormatJSON); err != nil {
+		t.Fatalf("Export(json) error = %v", err)
+	}
+	var decoded []Entry
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil || len(decoded) != 2 {
+		t.Errorf("Invalid JSON export: %v", err)
+	}
+
+	var jsonl bytes.Buffer
+	Export(&jsonl, entries, FormatJSONL)
+	if lines := strings.Count(jsonl.String(), "\n"); lines != 2 || !strings.Contains(jsonl.String(), "<b>") {
+		t.Errorf("Unexpected JSONL export: %s", jsonl.String())
+	}
+
+	var md bytes.Buffer
+	Export(&md, entries, FormatMarkdown)
+	if !strings.Contains(md.String(), "Atribui 1 a a.") || !strings.Contains(md.String(), "## "+entries[0].ID) {
+		t.Errorf("Unexpected Markdown export: %s", md.String())
+	}
+
+	if err := Export(&md, entries, "xml"); err == nil {
+		t.Errorf("Expected error for unknown format")
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/tmp/xdg-data")
+
+	path, err := DefaultPath()
+	if err != nil || path != "/tmp/xdg-data/code-explainer/history.jsonl" {
+		t.Errorf("DefaultPath() = %v, %v", path, err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	entries := []Entry{
+		{Model: "codellama", DurationMs: 2000, PromptTokens: 100, CompletionTokens: 50, EvalMs: 1000},
+		{Model: "codellama", DurationMs: 4000, PromptTokens: 100, CompletionTokens: 150, EvalMs: 3000},
+		{Model: "codellama", DurationMs: 1, Cached: true},
+		{Model: "gpt-4o-mini:2024", DurationMs: 1000, PromptTokens: 1_000_000, CompletionTokens: 500},
+	}
+
+	stats := Stats(entries, DefaultPrices)
+	if len(stats) != 2 {
+		t.Fatalf("Stats() retornou %d modelos, want 2", len(stats))
+	}
+
+	local := stats[0]
+	if local.Model != "codellama" || local.Requests != 3 || local.Cached != 1 {
+		t.Errorf("codellama = %+v", local)
+	}
+	if local.AvgLatencyMs != 3000 || local.PromptTokens != 200 || local.CompletionTokens != 200 {
+		t.Errorf("codellama latência/tokens = %+v", local)
+	}
+	if local.TokensPerSecond != 50 {
+		t.Errorf("codellama tokens/s = %v, want 50", local.TokensPerSecond)
+	}
+	if local.Priced || local.Cost != 0 {
+		t.Errorf("codellama não deveria ter custo: %+v", local)
+	}
+
+	paid := stats[1]
+	if !paid.Priced || paid.Cost != 0.15+500*0.60/1e6 {
+		t.Errorf("gpt-4o-mini custo = %v (priced %v)", paid.Cost, paid.Priced)
+	}
+	if paid.TokensPerSecond != 500 {
+		t.Errorf("gpt-4o-mini tokens/s = %v, want 500 (pela duração total)", paid.TokensPerSecond)
+	}
+}
@@ -0,0 +1,111 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Price é o preço de um modelo em dólares por milhão de tokens
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// DefaultPrices são valores de referência de provedores pagos; modelos locais não têm custo.
+// Podem ser sobrepostos pela seção pricing do arquivo de configuração.
+var DefaultPrices = map[string]Price{
+	"gpt-4o":       {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"gpt-4.1":      {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+}
+
+// ModelStats agrega as explicações de um modelo
+type ModelStats struct {
+	Model            string  `json:"model"`
+	Requests         int     `json:"requests"`
+	Cached           int     `json:"cached"`
+	AvgLatencyMs     int64   `json:"avg_latency_ms"` // Apenas respostas fora do cache
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TokensPerSecond  float64 `json:"tokens_per_second,omitempty"`
+	Cost             float64 `json:"cost_usd,omitempty"`
+	Priced           bool    `json:"priced"` // Se o modelo tem preço conhecido
+}
+
+// Stats agrupa as entradas por modelo, do mais usado para o menos usado.
+// prices é consultado pelo nome do modelo, com e sem a tag (ex.: "llama3:8b").
+func Stats(entries []Entry, prices map[string]Price) []ModelStats {
+	type totals struct {
+		stats      ModelStats
+		latencyMs  int64
+		generated  int   // Tokens gerados nas entradas com tempo medido
+		generateMs int64 // Tempo correspondente
+	}
+
+	byModel := make(map[string]*totals)
+	for _, e := range entries {
+		t := byModel[e.Model]
+		if t == nil {
+			t = &totals{stats: ModelStats{Model: e.Model}}
+			byModel[e.Model] = t
+		}
+
+		t.stats.Requests++
+		if e.Cached {
+			t.stats.Cached++
+			continue
+		}
+		t.latencyMs += e.DurationMs
+		t.stats.PromptTokens += e.PromptTokens
+		t.stats.CompletionTokens += e.CompletionTokens
+
+		// Sem o tempo de geração (ex.: servidores OpenAI), usa a duração total
+		ms := e.EvalMs
+		if ms == 0 {
+			ms = e.DurationMs
+		}
+		if e.CompletionTokens > 0 && ms > 0 {
+			t.generated += e.CompletionTokens
+			t.generateMs += ms
+		}
+	}
+
+	result := make([]ModelStats, 0, len(byModel))
+	for _, t := range byModel {
+		s := t.stats
+		if fresh := s.Requests - s.Cached; fresh > 0 {
+			s.AvgLatencyMs = t.latencyMs / int64(fresh)
+		}
+		if t.generateMs > 0 {
+			s.TokensPerSecond = float64(t.generated) / (time.Duration(t.generateMs) * time.Millisecond).Seconds()
+		}
+		if price, ok := lookupPrice(prices, s.Model); ok {
+			s.Priced = true
+			s.Cost = (float64(s.PromptTokens)*price.Input + float64(s.CompletionTokens)*price.Output) / 1e6
+		}
+		result = append(result, s)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Requests != result[j].Requests {
+			return result[i].Requests > result[j].Requests
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result
+}
+
+// lookupPrice procura o preço do modelo pelo nome completo e depois sem a tag
+func lookupPrice(prices map[string]Price, model string) (Price, bool) {
+	if p, ok := prices[model]; ok {
+		return p, true
+	}
+	if name, _, found := strings.Cut(model, ":"); found {
+		p, ok := prices[name]
+		return p, ok
+	}
+	return Price{}, false
+}
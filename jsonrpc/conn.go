@@ -0,0 +1,250 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Request é uma requisição ou notificação recebida
+type Request struct {
+	Conn   *Conn
+	ID     *ID // nil para notificações
+	Method string
+	Params json.RawMessage
+}
+
+// IsNotification informa se a requisição não espera resposta
+func (r *Request) IsNotification() bool {
+	return r.ID == nil
+}
+
+// Handler atende requisições e notificações. Requisições são atendidas em
+// goroutines próprias, com um contexto cancelado por Conn.Cancel; notificações
+// são atendidas em ordem, na goroutine de leitura.
+type Handler func(ctx context.Context, req *Request) (result interface{}, err error)
+
+// Conn é uma conexão JSON-RPC bidirecional sobre um Stream
+type Conn struct {
+	stream  Stream
+	handler Handler
+
+	mu      sync.Mutex
+	active  map[string]context.CancelFunc // Requisições recebidas em andamento
+	pending map[string]chan *Message      // Requisições enviadas aguardando resposta
+	nextID  int64
+
+	wg     sync.WaitGroup
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewConn cria uma conexão que atende as mensagens recebidas com handler
+func NewConn(stream Stream, handler Handler) *Conn {
+	return &Conn{
+		stream:  stream,
+		handler: handler,
+		active:  make(map[string]context.CancelFunc),
+		pending: make(map[string]chan *Message),
+		closed:  make(chan struct{}),
+	}
+}
+
+// Run lê e atende mensagens até o fim da entrada, o cancelamento de ctx ou Close.
+// Antes de retornar, cancela e aguarda as requisições em andamento.
+func (c *Conn) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		c.wg.Wait()
+	}()
+
+	type readResult struct {
+		msg *Message
+		err error
+	}
+	messages := make(chan readResult)
+	go func() {
+		for {
+			msg, err := c.stream.Read()
+			select {
+			case messages <- readResult{msg, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				var rpcErr *Error
+				if !errors.As(err, &rpcErr) {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.closed:
+			return nil
+		case r := <-messages:
+			if r.err != nil {
+				var rpcErr *Error
+				if errors.As(r.err, &rpcErr) {
+					// Mensagem malformada: responde com erro e continua lendo
+					c.stream.Write(&Message{JSONRPC: "2.0", Error: rpcErr})
+					continue
+				}
+				if errors.Is(r.err, io.EOF) {
+					return nil
+				}
+				return r.err
+			}
+			c.dispatch(ctx, r.msg)
+		}
+	}
+}
+
+// Close encerra Run
+func (c *Conn) Close() {
+	c.once.Do(func() { close(c.closed) })
+}
+
+// Cancel cancela a requisição recebida com o ID informado, se ainda estiver em andamento
+func (c *Conn) Cancel(id ID) {
+	c.mu.Lock()
+	cancel, ok := c.active[id.String()]
+	c.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// Notify envia uma notificação
+func (c *Conn) Notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.stream.Write(&Message{JSONRPC: "2.0", Method: method, Params: raw})
+}
+
+// Call envia uma requisição e aguarda a resposta, decodificando-a em result (pode ser nil)
+func (c *Conn) Call(ctx context.Context, method string, params, result interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.nextID++
+	id := NewNumberID(c.nextID)
+	ch := make(chan *Message, 1)
+	c.pending[id.String()] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id.String())
+		c.mu.Unlock()
+	}()
+
+	if err := c.stream.Write(&Message{JSONRPC: "2.0", ID: &id, Method: method, Params: raw}); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.closed:
+		return errors.New("jsonrpc: conexão encerrada")
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, result)
+		}
+		return nil
+	}
+}
+
+// dispatch encaminha a mensagem recebida
+func (c *Conn) dispatch(ctx context.Context, msg *Message) {
+	switch {
+	case msg.IsResponse():
+		c.mu.Lock()
+		ch, ok := c.pending[msg.ID.String()]
+		c.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+
+	case msg.IsNotification():
+		c.handler(ctx, &Request{Conn: c, Method: msg.Method, Params: msg.Params})
+
+	case msg.IsRequest():
+		reqCtx, cancel := context.WithCancel(ctx)
+		key := msg.ID.String()
+		c.mu.Lock()
+		c.active[key] = cancel
+		c.mu.Unlock()
+
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			defer func() {
+				c.mu.Lock()
+				delete(c.active, key)
+				c.mu.Unlock()
+				cancel()
+			}()
+
+			result, err := c.handler(reqCtx, &Request{Conn: c, ID: msg.ID, Method: msg.Method, Params: msg.Params})
+			c.reply(reqCtx, msg.ID, result, err)
+		}()
+
+	default:
+		c.stream.Write(&Message{JSONRPC: "2.0", ID: msg.ID, Error: NewError(CodeInvalidRequest, "mensagem inválida")})
+	}
+}
+
+// reply envia a resposta de uma requisição
+func (c *Conn) reply(ctx context.Context, id *ID, result interface{}, err error) {
+	resp := &Message{JSONRPC: "2.0", ID: id}
+
+	if err != nil {
+		var rpcErr *Error
+		switch {
+		case errors.As(err, &rpcErr):
+			resp.Error = rpcErr
+		case ctx.Err() != nil:
+			resp.Error = NewError(CodeRequestCancelled, "requisição cancelada")
+		default:
+			resp.Error = NewError(CodeInternalError, "%v", err)
+		}
+		c.stream.Write(resp)
+		return
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		resp.Error = NewError(CodeInternalError, "erro ao codificar resultado: %v", err)
+	} else {
+		resp.Result = raw
+	}
+	c.stream.Write(resp)
+}
+
+// UnmarshalParams decodifica os parâmetros da requisição, retornando CodeInvalidParams em caso de erro
+func (r *Request) UnmarshalParams(v interface{}) error {
+	if len(r.Params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Params, v); err != nil {
+		return NewError(CodeInvalidParams, "parâmetros inválidos: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreams(t *testing.T) {
+	tests := []struct {
+		name      string
+		newStream func(r io.Reader, w io.Writer) Stream
+		prefix    string
+	}{
+		{name: "Content-Length", newStream: NewHeaderStream, prefix: "Content-Length: "},
+		{name: "Linhas", newStream: NewLineStream, prefix: "{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := tt.newStream(nil, &buf)
+
+			id := ID{Str: "abc", IsName: true}
+			writer.Write(&Message{JSONRPC: "2.0", ID: &id, Method: "hover", Params: json.RawMessage(`{"texto":"ação"}`)})
+			writer.Write(&Message{JSONRPC: "2.0", Method: "exit"})
+
+			if !strings.HasPrefix(buf.String(), tt.prefix) {
+				t.Errorf("Unexpected framing: %q", buf.String())
+			}
+
+			reader := tt.newStream(&buf, nil)
+			first, err := reader.Read()
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !first.IsRequest() || first.ID.Str != "abc" || string(first.Params) != `{"texto":"ação"}` {
+				t.Errorf("Unexpected first message: %+v", first)
+			}
+
+			second, _ := reader.Read()
+			if !second.IsNotification() || second.Method != "exit" {
+				t.Errorf("Unexpected second message: %+v", second)
+			}
+
+			if _, err := reader.Read(); err != io.EOF {
+				t.Errorf("Expected io.EOF, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHeaderStreamInvalid(t *testing.T) {
+	reader := NewHeaderStream(strings.NewReader("Content-Type: x\r\n\r\n{}"), nil)
+	if _, err := reader.Read(); err == nil {
+		t.Errorf("Expected error for message without Content-Length")
+	}
+}
+
+func TestIDJSON(t *testing.T) {
+	for _, raw := range []string{`7`, `"req-7"`} {
+		var id ID
+		if err := json.Unmarshal([]byte(raw), &id); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", raw, err)
+		}
+		out, _ := json.Marshal(id)
+		if string(out) != raw {
+			t.Errorf("Round trip of %s produced %s", raw, out)
+		}
+	}
+}
+
+// pipeConn conecta uma Conn de teste a um Stream controlado pelo teste
+func pipeConn(t *testing.T, handler Handler) (client Stream, done chan error) {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	conn := NewConn(NewLineStream(serverIn, serverOut), handler)
+	done = make(chan error, 1)
+	go func() {
+		done <- conn.Run(context.Background())
+		serverOut.Close()
+	}()
+	t.Cleanup(func() { clientOut.Close() })
+
+	return NewLineStream(clientIn, clientOut), done
+}
+
+func TestConnRequests(t *testing.T) {
+	started := make(chan struct{})
+	client, done := pipeConn(t, func(ctx context.Context, req *Request) (interface{}, error) {
+		switch req.Method {
+		case "soma":
+			var params []int
+			if err := req.UnmarshalParams(&params); err != nil {
+				return nil, err
+			}
+			return params[0] + params[1], nil
+		case "lento":
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case "cancelar":
+			var id ID
+			json.Unmarshal(req.Params, &id)
+			req.Conn.Cancel(id)
+			return nil, nil
+		}
+		return nil, NewError(CodeMethodNotFound, "método não encontrado: %s", req.Method)
+	})
+
+	send := func(id int64, method, params string) {
+		mid := NewNumberID(id)
+		client.Write(&Message{JSONRPC: "2.0", ID: &mid, Method: method, Params: json.RawMessage(params)})
+	}
+
+	send(1, "soma", `[2, 3]`)
+	resp, _ := client.Read()
+	if resp.ID.Num != 1 || string(resp.Result) != "5" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	send(2, "inexistente", `{}`)
+	resp, _ = client.Read()
+	if resp.Error == nil || resp.Error.Code != CodeMethodNotFound {
+		t.Errorf("Expected method not found, got %+v", resp)
+	}
+
+	send(3, "soma", `"texto"`)
+	resp, _ = client.Read()
+	if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Errorf("Expected invalid params, got %+v", resp)
+	}
+
+	// Requisição lenta cancelada por outra mensagem
+	send(4, "lento", `{}`)
+	<-started
+	client.Write(&Message{JSONRPC: "2.0", Method: "cancelar", Params: json.RawMessage(`4`)})
+	resp, _ = client.Read()
+	if resp.ID.Num != 4 || resp.Error == nil || resp.Error.Code != CodeRequestCancelled {
+		t.Errorf("Expected cancelled response for 4, got %+v", resp)
+	}
+
+	client.(*lineStream).w.(io.Closer).Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("Run() did not return after EOF")
+	}
+}
+
+func TestConnCall(t *testing.T) {
+	var conn *Conn
+	ready := make(chan *Conn, 1)
+	client, _ := pipeConn(t, func(ctx context.Context, req *Request) (interface{}, error) {
+		ready <- req.Conn
+		var answer string
+		err := req.Conn.Call(ctx, "window/pergunta", map[string]string{"texto": "oi?"}, &answer)
+		return answer, err
+	})
+
+	id := NewNumberID(1)
+	client.Write(&Message{JSONRPC: "2.0", ID: &id, Method: "perguntar"})
+	conn = <-ready
+
+	// O servidor chama o cliente; o cliente responde
+	call, _ := client.Read()
+	if call.Method != "window/pergunta" || !call.IsRequest() {
+		t.Fatalf("Expected server-to-client request, got %+v", call)
+	}
+	client.Write(&Message{JSONRPC: "2.0", ID: call.ID, Result: json.RawMessage(`"tudo bem"`)})
+
+	resp, _ := client.Read()
+	if string(resp.Result) != `"tudo bem"` {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	// io.Pipe é síncrono: a escrita só termina quando o cliente lê
+	go conn.Notify("aviso", map[string]int{"n": 1})
+	notification, _ := client.Read()
+	if !notification.IsNotification() || notification.Method != "aviso" {
+		t.Errorf("Unexpected notification: %+v", notification)
+	}
+}
@@ -0,0 +1,98 @@
+// Package jsonrpc implementa uma conexão JSON-RPC 2.0 bidirecional, usada pelos
+// servidores LSP (mensagens com cabeçalho Content-Length) e MCP (uma mensagem por linha).
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Códigos de erro padronizados
+const (
+	CodeParseError       = -32700
+	CodeInvalidRequest   = -32600
+	CodeMethodNotFound   = -32601
+	CodeInvalidParams    = -32602
+	CodeInternalError    = -32603
+	CodeRequestCancelled = -32800 // Definido pelo LSP
+)
+
+// ID identifica uma requisição; pode ser número ou string
+type ID struct {
+	Num    int64
+	Str    string
+	IsName bool // true se o ID for uma string
+}
+
+// NewNumberID cria um ID numérico
+func NewNumberID(n int64) ID {
+	return ID{Num: n}
+}
+
+// String formata o ID para mensagens e chaves de mapa
+func (id ID) String() string {
+	if id.IsName {
+		return fmt.Sprintf("%q", id.Str)
+	}
+	return fmt.Sprintf("%d", id.Num)
+}
+
+// MarshalJSON codifica o ID como número ou string
+func (id ID) MarshalJSON() ([]byte, error) {
+	if id.IsName {
+		return json.Marshal(id.Str)
+	}
+	return json.Marshal(id.Num)
+}
+
+// UnmarshalJSON aceita IDs numéricos ou string
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		id.IsName = true
+		return json.Unmarshal(data, &id.Str)
+	}
+	id.IsName = false
+	return json.Unmarshal(data, &id.Num)
+}
+
+// Message é uma mensagem JSON-RPC: requisição (ID e Method), notificação (só Method)
+// ou resposta (ID e Result ou Error)
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *ID             `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// IsRequest informa se a mensagem é uma requisição que espera resposta
+func (m *Message) IsRequest() bool {
+	return m.Method != "" && m.ID != nil
+}
+
+// IsNotification informa se a mensagem é uma notificação
+func (m *Message) IsNotification() bool {
+	return m.Method != "" && m.ID == nil
+}
+
+// IsResponse informa se a mensagem é uma resposta a uma requisição nossa
+func (m *Message) IsResponse() bool {
+	return m.Method == "" && m.ID != nil
+}
+
+// Error é o objeto de erro JSON-RPC
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (código %d)", e.Message, e.Code)
+}
+
+// NewError cria um erro JSON-RPC com o código informado
+func NewError(code int, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
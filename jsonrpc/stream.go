@@ -0,0 +1,124 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Stream lê e escreve mensagens em um transporte
+type Stream interface {
+	Read() (*Message, error)
+	Write(*Message) error
+}
+
+// headerStream usa o enquadramento do LSP: cabeçalhos HTTP-like com Content-Length
+type headerStream struct {
+	r  *bufio.Reader
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewHeaderStream cria um Stream com cabeçalho Content-Length (LSP)
+func NewHeaderStream(r io.Reader, w io.Writer) Stream {
+	return &headerStream{r: bufio.NewReader(r), w: w}
+}
+
+func (s *headerStream) Read() (*Message, error) {
+	length := -1
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("cabeçalho inválido: %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("Content-Length inválido: %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("mensagem sem Content-Length")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.r, body); err != nil {
+		return nil, err
+	}
+	return decode(body)
+}
+
+func (s *headerStream) Write(m *Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = s.w.Write(body)
+	return err
+}
+
+// lineStream envia uma mensagem JSON por linha (MCP sobre stdio)
+type lineStream struct {
+	r  *bufio.Reader
+	w  io.Writer
+	mu sync.Mutex
+}
+
+// NewLineStream cria um Stream com uma mensagem por linha
+func NewLineStream(r io.Reader, w io.Writer) Stream {
+	return &lineStream{r: bufio.NewReader(r), w: w}
+}
+
+func (s *lineStream) Read() (*Message, error) {
+	for {
+		line, err := s.r.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return decode(line)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *lineStream) Write(m *Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(body, '\n'))
+	return err
+}
+
+// decode interpreta o corpo de uma mensagem; erros de JSON viram *Error com CodeParseError
+func decode(body []byte) (*Message, error) {
+	var m Message
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, NewError(CodeParseError, "JSON inválido: %v", err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		level   string
+		wantErr bool
+	}{
+		{name: "Padrão", format: "", level: ""},
+		{name: "JSON debug", format: FormatJSON, level: "debug"},
+		{name: "Texto warn", format: FormatText, level: "WARN"},
+		{name: "Formato inválido", format: "xml", wantErr: true},
+		{name: "Nível inválido", level: "trace", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, tt.format, tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestIDAttribute(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatJSON, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	ctx = WithRequestID(ctx, "abc123")
+	logger.With("route", "/v1/explain").InfoContext(ctx, "requisição", "status", 200)
+	logger.DebugContext(ctx, "abaixo do nível")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("esperava 1 linha, obteve %d: %s", len(lines), buf.String())
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record[RequestIDKey] != "abc123" || record[TraceIDKey] != traceID.String() || record["route"] != "/v1/explain" || record["msg"] != "requisição" {
+		t.Errorf("registro inesperado: %v", record)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if lvl, err := ParseLevel("warning"); err != nil || lvl != slog.LevelWarn {
+		t.Errorf("ParseLevel(warning) = %v, %v", lvl, err)
+	}
+	if id := NewRequestID(); len(id) != 16 || id == NewRequestID() {
+		t.Errorf("NewRequestID() = %q", id)
+	}
+}
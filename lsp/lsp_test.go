@@ -0,0 +1,271 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/jsonrpc"
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+const goDoc = `package main
+
+import "fmt"
+
+func main() {
+	defer fmt.Println("fim")
+
+	fmt.Println("olá, 世界")
+}
+
+var x = 1
+`
+
+func TestOffsetAndRange(t *testing.T) {
+	text := "a := \"🙂x\"\nb"
+
+	tests := []struct {
+		name     string
+		pos      Position
+		expected int
+	}{
+		{name: "Início", pos: Position{0, 0}, expected: 0},
+		{name: "Depois do emoji (2 unidades UTF-16)", pos: Position{0, 8}, expected: strings.Index(text, "x")},
+		{name: "Além do fim da linha", pos: Position{0, 99}, expected: strings.Index(text, "\n")},
+		{name: "Segunda linha", pos: Position{1, 1}, expected: len(text)},
+		{name: "Além do documento", pos: Position{5, 0}, expected: len(text)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := offset(text, tt.pos); got != tt.expected {
+				t.Errorf("offset(%+v) = %d, want %d", tt.pos, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEnclosingBlock(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  int
+		start int
+		end   int
+		ok    bool
+	}{
+		{name: "Corpo da função", line: 7, start: 4, end: 8, ok: true},
+		{name: "Assinatura", line: 4, start: 4, end: 8, ok: true},
+		{name: "Chave de fechamento", line: 8, start: 4, end: 8, ok: true},
+		{name: "Linha isolada", line: 10, start: 10, end: 10, ok: true},
+		{name: "Linha em branco", line: 3, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := enclosingBlock(goDoc, tt.line)
+			if ok != tt.ok {
+				t.Fatalf("enclosingBlock() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && (r.Start.Line != tt.start || r.End.Line != tt.end) {
+				t.Errorf("enclosingBlock() = %d-%d, want %d-%d", r.Start.Line, r.End.Line, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+// testClient conversa com o servidor por pipes, como um editor
+type testClient struct {
+	t      *testing.T
+	stream jsonrpc.Stream
+	nextID int64
+	out    chan *jsonrpc.Message // Mensagens enviadas em ordem por uma única goroutine
+	done   chan error
+	close  func()
+}
+
+func newTestClient(t *testing.T, explain openai.ExplainFunc) *testClient {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	s := New(&openai.Config{Model: "codellama"}, explain, "1.0.0")
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(context.Background(), serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	c := &testClient{t: t, stream: jsonrpc.NewHeaderStream(clientIn, clientOut), out: make(chan *jsonrpc.Message, 16), done: done, close: func() { clientOut.Close() }}
+	go func() {
+		for msg := range c.out {
+			c.stream.Write(msg)
+		}
+	}()
+	t.Cleanup(func() {
+		close(c.out)
+		c.close()
+	})
+	return c
+}
+
+// send enfileira uma requisição (ou notificação, se notify); io.Pipe é síncrono
+func (c *testClient) send(method string, params interface{}, notify bool) int64 {
+	raw, _ := json.Marshal(params)
+	msg := &jsonrpc.Message{JSONRPC: "2.0", Method: method, Params: raw}
+	if !notify {
+		c.nextID++
+		id := jsonrpc.NewNumberID(c.nextID)
+		msg.ID = &id
+	}
+	c.out <- msg
+	return c.nextID
+}
+
+// read lê a próxima mensagem do servidor
+func (c *testClient) read() *jsonrpc.Message {
+	c.t.Helper()
+	msg, err := c.stream.Read()
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	return msg
+}
+
+func (c *testClient) open(progress bool) {
+	c.send("initialize", map[string]interface{}{"capabilities": map[string]interface{}{"window": map[string]bool{"workDoneProgress": progress}}}, false)
+	resp := c.read()
+	var result InitializeResult
+	json.Unmarshal(resp.Result, &result)
+	if !result.Capabilities.HoverProvider || result.Capabilities.ExecuteCommandProvider.Commands[0] != CommandExplain {
+		c.t.Fatalf("Unexpected capabilities: %s", resp.Result)
+	}
+	c.send("initialized", map[string]interface{}{}, true)
+	c.send("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: "file:///main.go", LanguageID: "go", Text: goDoc}}, true)
+}
+
+func TestHoverAndCodeAction(t *testing.T) {
+	var gotLanguage string
+	c := newTestClient(t, func(ctx context.Context, code string, config *openai.Config) (string, error) {
+		gotLanguage = config.Language
+		return "Explicação de " + strings.Split(code, "\n")[0], nil
+	})
+	c.open(false)
+
+	c.send("textDocument/hover", HoverParams{TextDocument: TextDocumentIdentifier{URI: "file:///main.go"}, Position: Position{Line: 5, Character: 2}}, false)
+	var hover Hover
+	json.Unmarshal(c.read().Result, &hover)
+	if hover.Contents.Value != "Explicação de func main() {" || hover.Range.Start.Line != 4 || gotLanguage != "Go" {
+		t.Errorf("Unexpected hover: %+v (language %s)", hover, gotLanguage)
+	}
+
+	tests := []struct {
+		name  string
+		r     Range
+		title string
+	}{
+		{name: "Com seleção", r: Range{Start: Position{7, 1}, End: Position{7, 13}}, title: "Explicar seleção"},
+		{name: "Sem seleção", r: Range{Start: Position{5, 0}, End: Position{5, 0}}, title: "Explicar bloco"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.send("textDocument/codeAction", CodeActionParams{TextDocument: TextDocumentIdentifier{URI: "file:///main.go"}, Range: tt.r}, false)
+			var actions []CodeAction
+			json.Unmarshal(c.read().Result, &actions)
+			if len(actions) != 1 || actions[0].Title != tt.title || actions[0].Command.Command != CommandExplain {
+				t.Errorf("Unexpected actions: %+v", actions)
+			}
+		})
+	}
+
+	// Alteração do documento substitui o texto
+	c.send("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]string{"uri": "file:///main.go"},
+		"contentChanges": []map[string]string{{"text": "def novo():\n    pass\n"}},
+	}, true)
+	c.send("textDocument/hover", HoverParams{TextDocument: TextDocumentIdentifier{URI: "file:///main.go"}, Position: Position{Line: 1}}, false)
+	json.Unmarshal(c.read().Result, &hover)
+	if hover.Contents.Value != "Explicação de def novo():" {
+		t.Errorf("Expected hover over the changed document, got %+v", hover)
+	}
+}
+
+func TestExecuteCommandWithProgress(t *testing.T) {
+	c := newTestClient(t, func(ctx context.Context, code string, config *openai.Config) (string, error) {
+		return "Imprime " + code, nil
+	})
+	c.open(true)
+
+	r := Range{Start: Position{7, 1}, End: Position{7, 24}}
+	c.send("workspace/executeCommand", map[string]interface{}{"command": CommandExplain, "arguments": []interface{}{"file:///main.go", r}}, false)
+
+	// O servidor pede a criação do token de progresso
+	create := c.read()
+	if create.Method != "window/workDoneProgress/create" {
+		t.Fatalf("Expected progress token creation, got %+v", create)
+	}
+	c.out <- &jsonrpc.Message{JSONRPC: "2.0", ID: create.ID, Result: json.RawMessage("null")}
+
+	var methods []string
+	var result string
+	for i := 0; i < 4; i++ {
+		msg := c.read()
+		if msg.IsResponse() {
+			json.Unmarshal(msg.Result, &result)
+			continue
+		}
+		methods = append(methods, msg.Method)
+	}
+
+	if strings.Join(methods, ",") != "$/progress,$/progress,window/showMessage" {
+		t.Errorf("Unexpected notifications: %v", methods)
+	}
+	if result != `Imprime fmt.Println("olá, 世界")` {
+		t.Errorf("Unexpected command result: %q", result)
+	}
+}
+
+func TestCancelRequest(t *testing.T) {
+	c := newTestClient(t, func(ctx context.Context, code string, config *openai.Config) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	c.open(false)
+
+	id := c.send("textDocument/hover", HoverParams{TextDocument: TextDocumentIdentifier{URI: "file:///main.go"}, Position: Position{Line: 5}}, false)
+	time.Sleep(50 * time.Millisecond)
+	c.send("$/cancelRequest", map[string]int64{"id": id}, true)
+
+	resp := c.read()
+	if resp.ID.Num != id || resp.Error == nil || resp.Error.Code != jsonrpc.CodeRequestCancelled {
+		t.Errorf("Expected cancelled response, got %+v", resp)
+	}
+}
+
+func TestShutdownAndExit(t *testing.T) {
+	c := newTestClient(t, nil)
+	c.open(false)
+
+	c.send("shutdown", nil, false)
+	if resp := c.read(); resp.Error != nil {
+		t.Fatalf("shutdown error: %v", resp.Error)
+	}
+
+	c.send("textDocument/hover", HoverParams{}, false)
+	if resp := c.read(); resp.Error == nil || resp.Error.Code != jsonrpc.CodeInvalidRequest {
+		t.Errorf("Expected requests after shutdown to fail, got %+v", resp)
+	}
+
+	c.send("exit", nil, true)
+	select {
+	case err := <-c.done:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("Server did not exit")
+	}
+}
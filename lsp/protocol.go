@@ -0,0 +1,184 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/mvcbotelho/code-explainer/jsonrpc"
+)
+
+// Tipos do protocolo LSP usados pelo servidor (subconjunto da especificação 3.17)
+
+// Position é uma posição no documento; Character conta unidades UTF-16
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range é um intervalo [Start, End) no documento
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// IsEmpty informa se o intervalo não contém texto
+func (r Range) IsEmpty() bool {
+	return r.Start == r.End
+}
+
+// TextDocumentIdentifier identifica um documento aberto
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// TextDocumentItem é um documento aberto pelo editor
+type TextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+// InitializeParams são os parâmetros de initialize
+type InitializeParams struct {
+	ProcessID    *int               `json:"processId"`
+	Capabilities ClientCapabilities `json:"capabilities"`
+}
+
+// ClientCapabilities indica o que o editor suporta
+type ClientCapabilities struct {
+	Window struct {
+		WorkDoneProgress bool `json:"workDoneProgress"`
+	} `json:"window"`
+}
+
+// InitializeResult é a resposta de initialize
+type InitializeResult struct {
+	Capabilities ServerCapabilities `json:"capabilities"`
+	ServerInfo   ServerInfo         `json:"serverInfo"`
+}
+
+// ServerCapabilities anuncia os recursos do servidor
+type ServerCapabilities struct {
+	TextDocumentSync       int                   `json:"textDocumentSync"`
+	HoverProvider          bool                  `json:"hoverProvider"`
+	CodeActionProvider     bool                  `json:"codeActionProvider"`
+	ExecuteCommandProvider ExecuteCommandOptions `json:"executeCommandProvider"`
+}
+
+// ExecuteCommandOptions lista os comandos do servidor
+type ExecuteCommandOptions struct {
+	Commands []string `json:"commands"`
+}
+
+// ServerInfo identifica o servidor
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// DidOpenTextDocumentParams são os parâmetros de textDocument/didOpen
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+// DidChangeTextDocumentParams são os parâmetros de textDocument/didChange (sincronização completa)
+type DidChangeTextDocumentParams struct {
+	TextDocument   TextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// DidCloseTextDocumentParams são os parâmetros de textDocument/didClose
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// WorkDoneProgressParams permite ao editor informar o token de progresso
+type WorkDoneProgressParams struct {
+	WorkDoneToken interface{} `json:"workDoneToken,omitempty"`
+}
+
+// HoverParams são os parâmetros de textDocument/hover
+type HoverParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+	WorkDoneProgressParams
+}
+
+// Hover é a resposta de textDocument/hover
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+// MarkupContent é um texto em Markdown ou texto puro
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// CodeActionParams são os parâmetros de textDocument/codeAction
+type CodeActionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+// CodeAction é uma ação oferecida ao usuário
+type CodeAction struct {
+	Title   string   `json:"title"`
+	Kind    string   `json:"kind,omitempty"`
+	Command *Command `json:"command,omitempty"`
+}
+
+// Command é um comando executado via workspace/executeCommand
+type Command struct {
+	Title     string        `json:"title"`
+	Command   string        `json:"command"`
+	Arguments []interface{} `json:"arguments,omitempty"`
+}
+
+// ExecuteCommandParams são os parâmetros de workspace/executeCommand
+type ExecuteCommandParams struct {
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments"`
+	WorkDoneProgressParams
+}
+
+// CancelParams são os parâmetros de $/cancelRequest
+type CancelParams struct {
+	ID jsonrpc.ID `json:"id"`
+}
+
+// ProgressParams são os parâmetros de $/progress
+type ProgressParams struct {
+	Token interface{} `json:"token"`
+	Value interface{} `json:"value"`
+}
+
+// WorkDoneProgressBegin inicia um indicador de progresso
+type WorkDoneProgressBegin struct {
+	Kind        string `json:"kind"`
+	Title       string `json:"title"`
+	Message     string `json:"message,omitempty"`
+	Cancellable bool   `json:"cancellable"`
+}
+
+// WorkDoneProgressEnd encerra um indicador de progresso
+type WorkDoneProgressEnd struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message,omitempty"`
+}
+
+// ShowMessageParams são os parâmetros de window/showMessage
+type ShowMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
+
+// Tipos de mensagem de window/showMessage
+const (
+	MessageError   = 1
+	MessageWarning = 2
+	MessageInfo    = 3
+)
@@ -0,0 +1,297 @@
+// Package lsp implementa um servidor Language Server Protocol que oferece
+// explicações de código como hover e como ação de código ("Explicar seleção").
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mvcbotelho/code-explainer/jsonrpc"
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// CommandExplain é o comando executado pela ação "Explicar seleção"
+const CommandExplain = "codeExplainer.explain"
+
+// document é um documento aberto no editor
+type document struct {
+	languageID string
+	text       string
+}
+
+// Server atende um editor via LSP
+type Server struct {
+	config  *openai.Config
+	explain openai.ExplainFunc
+	version string
+
+	mu             sync.Mutex
+	docs           map[string]*document
+	clientProgress bool // O editor aceita window/workDoneProgress/create
+	shutdown       bool
+
+	progressID atomic.Int64
+}
+
+// New cria o servidor. explain nil usa openai.ExplainCodeContext.
+func New(config *openai.Config, explain openai.ExplainFunc, version string) *Server {
+	if config == nil {
+		config = openai.DefaultConfig()
+	}
+	if explain == nil {
+		explain = openai.ExplainCodeContext
+	}
+	return &Server{
+		config:  config,
+		explain: explain,
+		version: version,
+		docs:    make(map[string]*document),
+	}
+}
+
+// Run atende o editor lendo de r e escrevendo em w (normalmente stdin e stdout)
+// até a notificação exit ou o fim da entrada
+func (s *Server) Run(ctx context.Context, r io.Reader, w io.Writer) error {
+	conn := jsonrpc.NewConn(jsonrpc.NewHeaderStream(r, w), s.handle)
+	return conn.Run(ctx)
+}
+
+// handle despacha as mensagens recebidas
+func (s *Server) handle(ctx context.Context, req *jsonrpc.Request) (interface{}, error) {
+	s.mu.Lock()
+	shutdown := s.shutdown
+	s.mu.Unlock()
+	if shutdown && req.Method != "exit" && !req.IsNotification() {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidRequest, "servidor em encerramento")
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := req.UnmarshalParams(&params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.clientProgress = params.Capabilities.Window.WorkDoneProgress
+		s.mu.Unlock()
+
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       1, // Documento completo a cada alteração
+				HoverProvider:          true,
+				CodeActionProvider:     true,
+				ExecuteCommandProvider: ExecuteCommandOptions{Commands: []string{CommandExplain}},
+			},
+			ServerInfo: ServerInfo{Name: "code-explainer", Version: s.version},
+		}, nil
+
+	case "initialized", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		return nil, nil
+
+	case "exit":
+		req.Conn.Close()
+		return nil, nil
+
+	case "$/cancelRequest":
+		var params CancelParams
+		if err := req.UnmarshalParams(&params); err == nil {
+			req.Conn.Cancel(params.ID)
+		}
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := req.UnmarshalParams(&params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.docs[params.TextDocument.URI] = &document{languageID: params.TextDocument.LanguageID, text: params.TextDocument.Text}
+		s.mu.Unlock()
+		return nil, nil
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := req.UnmarshalParams(&params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		if doc, ok := s.docs[params.TextDocument.URI]; ok && len(params.ContentChanges) > 0 {
+			doc.text = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+		s.mu.Unlock()
+		return nil, nil
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := req.UnmarshalParams(&params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		delete(s.docs, params.TextDocument.URI)
+		s.mu.Unlock()
+		return nil, nil
+
+	case "textDocument/hover":
+		return s.hover(ctx, req)
+
+	case "textDocument/codeAction":
+		return s.codeActions(req)
+
+	case "workspace/executeCommand":
+		return s.executeCommand(ctx, req)
+	}
+
+	if req.IsNotification() {
+		return nil, nil
+	}
+	return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "método não suportado: %s", req.Method)
+}
+
+// hover explica o bloco de nível superior sob o cursor
+func (s *Server) hover(ctx context.Context, req *jsonrpc.Request) (interface{}, error) {
+	var params HoverParams
+	if err := req.UnmarshalParams(&params); err != nil {
+		return nil, err
+	}
+
+	doc, ok := s.document(params.TextDocument.URI)
+	if !ok {
+		return nil, nil
+	}
+	block, ok := enclosingBlock(doc.text, params.Position.Line)
+	if !ok {
+		return nil, nil
+	}
+
+	explanation, err := s.explainWithProgress(ctx, req.Conn, params.WorkDoneToken, doc, textInRange(doc.text, block))
+	if err != nil {
+		return nil, err
+	}
+
+	return Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: explanation},
+		Range:    &block,
+	}, nil
+}
+
+// codeActions oferece "Explicar seleção" ou, sem seleção, "Explicar bloco"
+func (s *Server) codeActions(req *jsonrpc.Request) (interface{}, error) {
+	var params CodeActionParams
+	if err := req.UnmarshalParams(&params); err != nil {
+		return nil, err
+	}
+
+	doc, ok := s.document(params.TextDocument.URI)
+	if !ok {
+		return []CodeAction{}, nil
+	}
+
+	title, r := "Explicar seleção", params.Range
+	if r.IsEmpty() || strings.TrimSpace(textInRange(doc.text, r)) == "" {
+		block, ok := enclosingBlock(doc.text, r.Start.Line)
+		if !ok {
+			return []CodeAction{}, nil
+		}
+		title, r = "Explicar bloco", block
+	}
+
+	return []CodeAction{{
+		Title: title,
+		Kind:  "refactor",
+		Command: &Command{
+			Title:     title,
+			Command:   CommandExplain,
+			Arguments: []interface{}{params.TextDocument.URI, r},
+		},
+	}}, nil
+}
+
+// executeCommand explica o intervalo indicado pela ação e mostra o resultado ao usuário
+func (s *Server) executeCommand(ctx context.Context, req *jsonrpc.Request) (interface{}, error) {
+	var params ExecuteCommandParams
+	if err := req.UnmarshalParams(&params); err != nil {
+		return nil, err
+	}
+	if params.Command != CommandExplain {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "comando desconhecido: %s", params.Command)
+	}
+
+	var uri string
+	var r Range
+	if len(params.Arguments) != 2 || json.Unmarshal(params.Arguments[0], &uri) != nil || json.Unmarshal(params.Arguments[1], &r) != nil {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "argumentos esperados: uri e range")
+	}
+
+	doc, ok := s.document(uri)
+	if !ok {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "documento não está aberto: %s", uri)
+	}
+	code := textInRange(doc.text, r)
+	if strings.TrimSpace(code) == "" {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "seleção vazia")
+	}
+
+	explanation, err := s.explainWithProgress(ctx, req.Conn, params.WorkDoneToken, doc, code)
+	if err != nil {
+		if ctx.Err() == nil {
+			req.Conn.Notify("window/showMessage", ShowMessageParams{Type: MessageError, Message: "Erro ao explicar código: " + err.Error()})
+		}
+		return nil, err
+	}
+
+	req.Conn.Notify("window/showMessage", ShowMessageParams{Type: MessageInfo, Message: explanation})
+	return explanation, nil
+}
+
+// explainWithProgress explica o código informando o andamento via $/progress.
+// Usa o token enviado pelo editor ou, se o editor permitir, cria um novo.
+func (s *Server) explainWithProgress(ctx context.Context, conn *jsonrpc.Conn, token interface{}, doc document, code string) (string, error) {
+	config := *s.config
+	if config.Language == "" {
+		config.Language = openai.DetectLanguageWithHint(code, doc.languageID)
+	}
+
+	s.mu.Lock()
+	clientProgress := s.clientProgress
+	s.mu.Unlock()
+
+	if token == nil && clientProgress {
+		created := fmt.Sprintf("code-explainer-%d", s.progressID.Add(1))
+		if err := conn.Call(ctx, "window/workDoneProgress/create", map[string]string{"token": created}, nil); err == nil {
+			token = created
+		}
+	}
+
+	if token != nil {
+		conn.Notify("$/progress", ProgressParams{Token: token, Value: WorkDoneProgressBegin{
+			Kind:    "begin",
+			Title:   "Explicando código",
+			Message: fmt.Sprintf("%s · %s", config.Language, config.Model),
+		}})
+		defer conn.Notify("$/progress", ProgressParams{Token: token, Value: WorkDoneProgressEnd{Kind: "end"}})
+	}
+
+	return s.explain(ctx, code, &config)
+}
+
+// document retorna uma cópia do documento aberto
+func (s *Server) document(uri string) (document, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, ok := s.docs[uri]
+	if !ok {
+		return document{}, false
+	}
+	return *doc, true
+}
@@ -0,0 +1,99 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxBlockLines limita o bloco explicado no hover
+const maxBlockLines = 200
+
+// offset converte uma posição LSP (caractere em unidades UTF-16) em índice de byte.
+// Posições além do fim da linha ou do documento são ajustadas para o limite.
+func offset(text string, pos Position) int {
+	idx := 0
+	for line := 0; line < pos.Line; line++ {
+		next := strings.IndexByte(text[idx:], '\n')
+		if next < 0 {
+			return len(text)
+		}
+		idx += next + 1
+	}
+
+	units := 0
+	for i, r := range text[idx:] {
+		if r == '\n' || units >= pos.Character {
+			return idx + i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(text)
+}
+
+// textInRange retorna o texto do intervalo
+func textInRange(text string, r Range) string {
+	start, end := offset(text, r.Start), offset(text, r.End)
+	if end < start {
+		start, end = end, start
+	}
+	return text[start:end]
+}
+
+// enclosingBlock encontra o bloco de nível superior que contém a linha: sobe até
+// uma linha sem indentação e desce até o fechamento correspondente ou o próximo bloco
+func enclosingBlock(text string, line int) (Range, bool) {
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) || strings.TrimSpace(lines[line]) == "" {
+		return Range{}, false
+	}
+
+	start := line
+	for start > 0 && (indented(lines[start]) || isClosing(lines[start])) {
+		start--
+	}
+
+	end := line
+	for !isClosing(lines[line]) && end+1 < len(lines) && end-start < maxBlockLines {
+		next := lines[end+1]
+		if !indented(next) && !isClosing(next) {
+			break
+		}
+		end++
+		if isClosing(next) {
+			break
+		}
+	}
+
+	// Remove linhas em branco no fim do bloco
+	for end > start && strings.TrimSpace(lines[end]) == "" {
+		end--
+	}
+
+	return Range{
+		Start: Position{Line: start},
+		End:   Position{Line: end, Character: utf16Len(lines[end])},
+	}, true
+}
+
+// indented informa se a linha faz parte do corpo de um bloco (indentada ou em branco)
+func indented(line string) bool {
+	return line == "" || strings.TrimSpace(line) == "" || line[0] == ' ' || line[0] == '\t'
+}
+
+// isClosing informa se a linha fecha um bloco sem indentação (}, ), end...)
+func isClosing(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && !indented(line) && (trimmed[0] == '}' || trimmed[0] == ')' || trimmed[0] == ']')
+}
+
+// utf16Len retorna o tamanho da linha em unidades UTF-16
+func utf16Len(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		n += len(utf16.Encode([]rune{r}))
+		s = s[size:]
+	}
+	return n
+}
@@ -0,0 +1,251 @@
+// Package mcp implementa um servidor Model Context Protocol sobre stdio que
+// expõe ferramentas (tools) para agentes.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mvcbotelho/code-explainer/jsonrpc"
+)
+
+// ProtocolVersion é a versão do MCP implementada
+const ProtocolVersion = "2024-11-05"
+
+// Property descreve um argumento de ferramenta no JSON Schema
+type Property struct {
+	Type        string      `json:"type"`
+	Description string      `json:"description,omitempty"`
+	Enum        []string    `json:"enum,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+}
+
+// Schema é o JSON Schema dos argumentos de uma ferramenta
+type Schema struct {
+	Type       string              `json:"type"`
+	Properties map[string]Property `json:"properties"`
+	Required   []string            `json:"required,omitempty"`
+}
+
+// Tool descreve uma ferramenta para o agente
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InputSchema Schema `json:"inputSchema"`
+}
+
+// ToolHandler executa a ferramenta com os argumentos já validados e retorna o texto do resultado
+type ToolHandler func(ctx context.Context, args map[string]interface{}) (string, error)
+
+// Content é um bloco de conteúdo do resultado de uma ferramenta
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// CallToolResult é a resposta de tools/call. Erros da ferramenta são
+// informados com IsError, para que o agente possa lê-los.
+type CallToolResult struct {
+	Content []Content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// Server atende um agente via MCP
+type Server struct {
+	name    string
+	version string
+
+	mu       sync.Mutex
+	tools    map[string]Tool
+	handlers map[string]ToolHandler
+}
+
+// New cria um servidor sem ferramentas
+func New(name, version string) *Server {
+	return &Server{
+		name:     name,
+		version:  version,
+		tools:    make(map[string]Tool),
+		handlers: make(map[string]ToolHandler),
+	}
+}
+
+// AddTool registra uma ferramenta
+func (s *Server) AddTool(tool Tool, handler ToolHandler) {
+	if tool.InputSchema.Type == "" {
+		tool.InputSchema.Type = "object"
+	}
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = map[string]Property{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools[tool.Name] = tool
+	s.handlers[tool.Name] = handler
+}
+
+// Tools retorna as ferramentas registradas, em ordem alfabética
+func (s *Server) Tools() []Tool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tools := make([]Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		tools = append(tools, t)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// Run atende o agente lendo de r e escrevendo em w, uma mensagem JSON por linha
+func (s *Server) Run(ctx context.Context, r io.Reader, w io.Writer) error {
+	conn := jsonrpc.NewConn(jsonrpc.NewLineStream(r, w), s.handle)
+	return conn.Run(ctx)
+}
+
+// handle despacha as mensagens recebidas
+func (s *Server) handle(ctx context.Context, req *jsonrpc.Request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": s.name, "version": s.version},
+		}, nil
+
+	case "ping":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		return map[string]interface{}{"tools": s.Tools()}, nil
+
+	case "tools/call":
+		return s.callTool(ctx, req)
+
+	case "notifications/cancelled":
+		var params struct {
+			RequestID jsonrpc.ID `json:"requestId"`
+		}
+		if err := req.UnmarshalParams(&params); err == nil {
+			req.Conn.Cancel(params.RequestID)
+		}
+		return nil, nil
+	}
+
+	if req.IsNotification() {
+		return nil, nil
+	}
+	return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "método não suportado: %s", req.Method)
+}
+
+// callTool valida os argumentos e executa a ferramenta
+func (s *Server) callTool(ctx context.Context, req *jsonrpc.Request) (interface{}, error) {
+	var params struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	}
+	if err := req.UnmarshalParams(&params); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	tool, ok := s.tools[params.Name]
+	handler := s.handlers[params.Name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "ferramenta desconhecida: %s", params.Name)
+	}
+
+	if params.Arguments == nil {
+		params.Arguments = map[string]interface{}{}
+	}
+	if err := Validate(tool.InputSchema, params.Arguments); err != nil {
+		return errorResult(err), nil
+	}
+
+	text, err := handler(ctx, params.Arguments)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return errorResult(err), nil
+	}
+	return CallToolResult{Content: []Content{{Type: "text", Text: text}}}, nil
+}
+
+// errorResult monta o resultado de uma ferramenta que falhou
+func errorResult(err error) CallToolResult {
+	return CallToolResult{Content: []Content{{Type: "text", Text: err.Error()}}, IsError: true}
+}
+
+// Validate verifica os argumentos contra o schema: campos obrigatórios, tipos, enums e campos desconhecidos
+func Validate(schema Schema, args map[string]interface{}) error {
+	for _, name := range schema.Required {
+		if _, ok := args[name]; !ok {
+			return fmt.Errorf("argumento obrigatório ausente: %s", name)
+		}
+	}
+
+	for name, value := range args {
+		prop, ok := schema.Properties[name]
+		if !ok {
+			return fmt.Errorf("argumento desconhecido: %s", name)
+		}
+		if !hasType(value, prop.Type) {
+			return fmt.Errorf("argumento %s deve ser do tipo %s", name, prop.Type)
+		}
+		if len(prop.Enum) > 0 {
+			str, _ := value.(string)
+			if !contains(prop.Enum, str) {
+				return fmt.Errorf("valor inválido para %s: %v (use %s)", name, value, strings.Join(prop.Enum, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// hasType verifica o tipo JSON decodificado
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	}
+	return true
+}
+
+// contains informa se a lista contém o valor
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// String retorna o argumento como string, ou "" se ausente
+func String(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// Int retorna o argumento inteiro, ou 0 se ausente
+func Int(args map[string]interface{}, name string) int {
+	n, _ := args[name].(float64)
+	return int(n)
+}
@@ -0,0 +1,206 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var explainSchema = Schema{
+	Properties: map[string]Property{
+		"code":    {Type: "string"},
+		"level":   {Type: "string", Enum: []string{"basico", "intermediario", "avancado"}},
+		"timeout": {Type: "integer"},
+	},
+	Required: []string{"code"},
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{name: "Válido", args: map[string]interface{}{"code": "x := 1", "level": "basico", "timeout": float64(10)}},
+		{name: "Obrigatório ausente", args: map[string]interface{}{}, wantErr: "obrigatório ausente: code"},
+		{name: "Tipo errado", args: map[string]interface{}{"code": float64(1)}, wantErr: "code deve ser do tipo string"},
+		{name: "Inteiro fracionário", args: map[string]interface{}{"code": "x", "timeout": 1.5}, wantErr: "timeout deve ser do tipo integer"},
+		{name: "Fora do enum", args: map[string]interface{}{"code": "x", "level": "expert"}, wantErr: "valor inválido para level"},
+		{name: "Desconhecido", args: map[string]interface{}{"code": "x", "api_url": "http://x"}, wantErr: "argumento desconhecido: api_url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(explainSchema, tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() erro inesperado: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() erro = %v, want contendo %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// testClient conversa com o servidor por pipes, como um agente
+type testClient struct {
+	t   *testing.T
+	in  *io.PipeWriter
+	out *bufio.Scanner
+}
+
+func startServer(t *testing.T, s *Server) *testClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx, inR, outW)
+		outW.Close()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		cancel()
+		<-done
+	})
+
+	return &testClient{t: t, in: inW, out: bufio.NewScanner(outR)}
+}
+
+func (c *testClient) send(msg string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, msg+"\n"); err != nil {
+		c.t.Fatalf("erro ao enviar: %v", err)
+	}
+}
+
+func (c *testClient) receive() map[string]interface{} {
+	c.t.Helper()
+	if !c.out.Scan() {
+		c.t.Fatalf("servidor não respondeu: %v", c.out.Err())
+	}
+	var msg map[string]interface{}
+	if err := json.Unmarshal(c.out.Bytes(), &msg); err != nil {
+		c.t.Fatalf("resposta inválida %q: %v", c.out.Text(), err)
+	}
+	return msg
+}
+
+func newTestServer() *Server {
+	s := New("code-explainer", "1.0.0")
+	s.AddTool(Tool{Name: "explain_code", Description: "Explica código", InputSchema: explainSchema},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			code := String(args, "code")
+			switch code {
+			case "falha":
+				return "", errors.New("modelo indisponível")
+			case "lento":
+				<-ctx.Done()
+				return "", ctx.Err()
+			}
+			return "explicação: " + code, nil
+		})
+	s.AddTool(Tool{Name: "list_languages", Description: "Lista linguagens"},
+		func(ctx context.Context, args map[string]interface{}) (string, error) {
+			return "Go\nPython", nil
+		})
+	return s
+}
+
+func TestServer(t *testing.T) {
+	c := startServer(t, newTestServer())
+
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"teste","version":"0"}}}`)
+	resp := c.receive()
+	result := resp["result"].(map[string]interface{})
+	if result["protocolVersion"] != ProtocolVersion {
+		t.Errorf("protocolVersion = %v, want %s", result["protocolVersion"], ProtocolVersion)
+	}
+	if _, ok := result["capabilities"].(map[string]interface{})["tools"]; !ok {
+		t.Errorf("capabilities sem tools: %v", result["capabilities"])
+	}
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	c.send(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	tools := c.receive()["result"].(map[string]interface{})["tools"].([]interface{})
+	if len(tools) != 2 {
+		t.Fatalf("tools/list retornou %d ferramentas, want 2", len(tools))
+	}
+	first := tools[0].(map[string]interface{})
+	if first["name"] != "explain_code" {
+		t.Errorf("primeira ferramenta = %v, want explain_code", first["name"])
+	}
+	if schema := tools[1].(map[string]interface{})["inputSchema"].(map[string]interface{}); schema["type"] != "object" {
+		t.Errorf("inputSchema.type = %v, want object", schema["type"])
+	}
+
+	tests := []struct {
+		name    string
+		params  string
+		text    string
+		isError bool
+	}{
+		{name: "Sucesso", params: `{"name":"explain_code","arguments":{"code":"x := 1"}}`, text: "explicação: x := 1"},
+		{name: "Sem argumentos", params: `{"name":"list_languages"}`, text: "Go\nPython"},
+		{name: "Erro da ferramenta", params: `{"name":"explain_code","arguments":{"code":"falha"}}`, text: "modelo indisponível", isError: true},
+		{name: "Argumento inválido", params: `{"name":"explain_code","arguments":{}}`, text: "argumento obrigatório ausente: code", isError: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, _ := json.Marshal(10 + i)
+			c.send(`{"jsonrpc":"2.0","id":` + string(id) + `,"method":"tools/call","params":` + tt.params + `}`)
+			result := c.receive()["result"].(map[string]interface{})
+
+			content := result["content"].([]interface{})[0].(map[string]interface{})
+			if content["type"] != "text" || content["text"] != tt.text {
+				t.Errorf("content = %v, want texto %q", content, tt.text)
+			}
+			if isError, _ := result["isError"].(bool); isError != tt.isError {
+				t.Errorf("isError = %v, want %v", isError, tt.isError)
+			}
+		})
+	}
+
+	c.send(`{"jsonrpc":"2.0","id":20,"method":"tools/call","params":{"name":"inexistente"}}`)
+	if rpcErr, ok := c.receive()["error"].(map[string]interface{}); !ok || rpcErr["code"] != float64(-32602) {
+		t.Errorf("ferramenta desconhecida: error = %v, want código -32602", rpcErr)
+	}
+
+	c.send(`{"jsonrpc":"2.0","id":21,"method":"resources/list"}`)
+	if rpcErr, ok := c.receive()["error"].(map[string]interface{}); !ok || rpcErr["code"] != float64(-32601) {
+		t.Errorf("método desconhecido: error = %v, want código -32601", rpcErr)
+	}
+
+	c.send(`{"jsonrpc":"2.0","id":22,"method":"ping"}`)
+	if _, ok := c.receive()["result"]; !ok {
+		t.Error("ping sem resultado")
+	}
+}
+
+func TestServerCancel(t *testing.T) {
+	c := startServer(t, newTestServer())
+
+	c.send(`{"jsonrpc":"2.0","id":"lento","method":"tools/call","params":{"name":"explain_code","arguments":{"code":"lento"}}}`)
+	time.Sleep(20 * time.Millisecond)
+	c.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"lento","reason":"usuário cancelou"}}`)
+
+	resp := c.receive()
+	if resp["id"] != "lento" {
+		t.Fatalf("resposta com id %v, want lento", resp["id"])
+	}
+	if rpcErr, ok := resp["error"].(map[string]interface{}); !ok || rpcErr["code"] != float64(-32800) {
+		t.Errorf("error = %v, want código -32800", resp["error"])
+	}
+}
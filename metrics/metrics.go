@@ -0,0 +1,266 @@
+// Package metrics implementa contadores, histogramas e medidores expostos no
+// formato de texto do Prometheus, sem dependências externas.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType é o tipo de conteúdo do formato de exposição em texto
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets são os limites (em segundos) adequados à latência de modelos de linguagem
+var DefaultBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// collector é uma métrica registrada
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry guarda as métricas na ordem em que foram registradas
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry cria um Registry vazio
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Counter registra um contador com os rótulos informados
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{name: name, help: help, labels: labels}, values: make(map[string]*counterValue)}
+	r.register(c)
+	return c
+}
+
+// Histogram registra um histograma; buckets nil usa DefaultBuckets
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &Histogram{desc: desc{name: name, help: help, labels: labels}, buckets: buckets, values: make(map[string]*histogramValue)}
+	r.register(h)
+	return h
+}
+
+// GaugeFunc registra um medidor cujo valor é calculado por fn a cada leitura
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(&gaugeFunc{desc: desc{name: name, help: help}, fn: fn})
+}
+
+// Write escreve todas as métricas no formato de texto do Prometheus
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler atende as métricas via HTTP (normalmente em /metrics)
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.Write(w)
+	})
+}
+
+// desc descreve o nome, a ajuda e os rótulos de uma métrica
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+// key identifica uma série pelos valores dos rótulos
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s espera %d rótulo(s), recebeu %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+func (d desc) header(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, d.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, kind)
+}
+
+// labelPairs formata os rótulos como {a="1",b="2"}, com um par extra opcional (ex.: le)
+func (d desc) labelPairs(values []string, extra ...string) string {
+	if len(d.labels) == 0 && len(extra) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(d.labels)+1)
+	for i, name := range d.labels {
+		pairs = append(pairs, name+`="`+escape(values[i])+`"`)
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, extra[0]+`="`+escape(extra[1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys retorna as chaves do mapa em ordem, para uma saída estável
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Counter é um contador monotônico com rótulos
+type Counter struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labels []string
+	value  float64
+}
+
+// Inc soma 1 à série dos rótulos informados
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add soma v (que deve ser positivo) à série dos rótulos informados
+func (c *Counter) Add(v float64, labels ...string) {
+	key := c.key(labels)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cv := c.values[key]
+	if cv == nil {
+		cv = &counterValue{labels: append([]string(nil), labels...)}
+		c.values[key] = cv
+	}
+	cv.value += v
+}
+
+// Value retorna o valor atual da série dos rótulos informados
+func (c *Counter) Value(labels ...string) float64 {
+	key := c.key(labels)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cv := c.values[key]; cv != nil {
+		return cv.value
+	}
+	return 0
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.header(w, "counter")
+	for _, k := range sortedKeys(c.values) {
+		cv := c.values[k]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(cv.labels), formatFloat(cv.value))
+	}
+}
+
+// Histogram distribui observações em buckets cumulativos
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labels []string
+	counts []uint64 // Um por bucket, não cumulativo
+	count  uint64
+	sum    float64
+}
+
+// Observe registra o valor v na série dos rótulos informados
+func (h *Histogram) Observe(v float64, labels ...string) {
+	key := h.key(labels)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hv := h.values[key]
+	if hv == nil {
+		hv = &histogramValue{labels: append([]string(nil), labels...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hv
+	}
+	for i, le := range h.buckets {
+		if v <= le {
+			hv.counts[i]++
+			break
+		}
+	}
+	hv.count++
+	hv.sum += v
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.header(w, "histogram")
+	for _, k := range sortedKeys(h.values) {
+		hv := h.values[k]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += hv.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(hv.labels, "le", formatFloat(le)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(hv.labels, "le", "+Inf"), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(hv.labels), formatFloat(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(hv.labels), hv.count)
+	}
+}
+
+// gaugeFunc é um medidor sem rótulos calculado no momento da leitura
+type gaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	g.header(w, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.fn()))
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	requests := r.Counter("app_requests_total", "Requisições atendidas.", "route", "code")
+	latency := r.Histogram("app_latency_seconds", "Latência.", []float64{0.5, 1}, "model")
+	r.GaugeFunc("app_ratio", "Proporção.", func() float64 { return 0.25 })
+
+	requests.Inc("/v1/explain", "200")
+	requests.Inc("/v1/explain", "200")
+	requests.Add(3, "/v1/detect", "400")
+	latency.Observe(0.2, "llama3")
+	latency.Observe(0.7, "llama3")
+	latency.Observe(4, "llama3")
+
+	var out strings.Builder
+	if err := r.Write(&out); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	expected := `# HELP app_requests_total Requisições atendidas.
+# TYPE app_requests_total counter
+app_requests_total{route="/v1/detect",code="400"} 3
+app_requests_total{route="/v1/explain",code="200"} 2
+# HELP app_latency_seconds Latência.
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{model="llama3",le="0.5"} 1
+app_latency_seconds_bucket{model="llama3",le="1"} 2
+app_latency_seconds_bucket{model="llama3",le="+Inf"} 3
+app_latency_seconds_sum{model="llama3"} 4.9
+app_latency_seconds_count{model="llama3"} 3
+# HELP app_ratio Proporção.
+# TYPE app_ratio gauge
+app_ratio 0.25
+`
+	if out.String() != expected {
+		t.Errorf("Write() =\n%s\nwant\n%s", out.String(), expected)
+	}
+	if got := requests.Value("/v1/explain", "200"); got != 2 {
+		t.Errorf("Value() = %v, want 2", got)
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("c_total", "Contador.", "model").Inc("a\"b\\c\nd")
+
+	var out strings.Builder
+	r.Write(&out)
+	if !strings.Contains(out.String(), `c_total{model="a\"b\\c\nd"} 1`) {
+		t.Errorf("rótulo não escapado:\n%s", out.String())
+	}
+}
+
+func TestLabelMismatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("esperava pânico com número errado de rótulos")
+		}
+	}()
+	NewRegistry().Counter("c_total", "Contador.", "model").Inc()
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("c_total", "Contador.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Content-Type = %q", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "c_total 1\n") {
+		t.Errorf("corpo inesperado:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,64 @@
+package openai
+
+import (
+	"context"
+	"strings"
+)
+
+// Papéis das mensagens de uma conversa
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message é uma mensagem da conversa no formato de /api/chat
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest representa a requisição para /api/chat
+type ChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+// ChatResponse representa a resposta de /api/chat
+type ChatResponse struct {
+	Message Message `json:"message"`
+	Done    bool    `json:"done"`
+	Metrics
+}
+
+// ChatURL deriva a URL de /api/chat a partir da URL configurada (normalmente /api/generate)
+func ChatURL(apiURL string) string {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	if strings.HasSuffix(apiURL, "/api/chat") {
+		return apiURL
+	}
+	if strings.HasSuffix(apiURL, "/api/generate") {
+		return strings.TrimSuffix(apiURL, "/generate") + "/chat"
+	}
+	return apiURL + "/api/chat"
+}
+
+// Chat envia o histórico da conversa para /api/chat e retorna a resposta do modelo
+func Chat(ctx context.Context, messages []Message, config *Config) (string, error) {
+	config = effectiveConfig(config)
+
+	body := ChatRequest{
+		Model:    config.Model,
+		Messages: messages,
+		Stream:   false,
+	}
+
+	var r ChatResponse
+	if err := postJSON(ctx, ChatURL(config.APIURL), body, &r, config); err != nil {
+		return "", err
+	}
+	reportUsage(config, r.Metrics)
+
+	return r.Message.Content, nil
+}
@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChatURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiURL   string
+		expected string
+	}{
+		{name: "Generate", apiURL: "http://localhost:11434/api/generate", expected: "http://localhost:11434/api/chat"},
+		{name: "Já é chat", apiURL: "http://localhost:11434/api/chat", expected: "http://localhost:11434/api/chat"},
+		{name: "Somente host", apiURL: "http://ollama:11434/", expected: "http://ollama:11434/api/chat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChatURL(tt.apiURL); got != tt.expected {
+				t.Errorf("ChatURL() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("Expected path /api/chat, got %s", r.URL.Path)
+		}
+
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if len(req.Messages) != 3 || req.Messages[0].Role != RoleSystem || req.Messages[2].Content != "Por que o defer?" {
+			t.Errorf("Unexpected messages: %+v", req.Messages)
+		}
+
+		json.NewEncoder(w).Encode(ChatResponse{
+			Message: Message{Role: RoleAssistant, Content: "Para fechar o arquivo."},
+			Done:    true,
+		})
+	}))
+	defer server.Close()
+
+	config := &Config{APIURL: server.URL + "/api/generate", Model: "codellama", Timeout: 5 * time.Second}
+	messages := []Message{
+		{Role: RoleSystem, Content: "Você explica código."},
+		{Role: RoleUser, Content: "defer f.Close()"},
+		{Role: RoleUser, Content: "Por que o defer?"},
+	}
+
+	result, err := Chat(context.Background(), messages, config)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if result != "Para fechar o arquivo." {
+		t.Errorf("Chat() = %q", result)
+	}
+}
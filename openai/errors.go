@@ -0,0 +1,108 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Erros de API classificados. Use errors.Is para identificá-los; o *APIError
+// ou o erro de conexão original continuam acessíveis via errors.As.
+var (
+	ErrModelNotFound     = errors.New("modelo não encontrado")
+	ErrServerUnavailable = errors.New("servidor indisponível")
+	ErrTimeout           = errors.New("tempo limite excedido")
+	ErrContextTooLong    = errors.New("entrada excede o contexto do modelo")
+	ErrUnauthorized      = errors.New("acesso não autorizado")
+)
+
+// APIError representa um erro específico da API
+type APIError struct {
+	StatusCode int
+	Message    string
+	Details    string // Corpo da resposta
+	Kind       error  // Um dos erros classificados (ErrModelNotFound etc.), ou nil
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+	if detail := e.Detail(); detail != "" {
+		msg += ": " + detail
+	}
+	return msg
+}
+
+// Unwrap permite usar errors.Is(err, ErrModelNotFound) e similares
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// Detail extrai a mensagem de erro do corpo da resposta, nos formatos do Ollama
+// ({"error": "..."}) e da OpenAI ({"error": {"message": "..."}}), ou o corpo em texto
+func (e *APIError) Detail() string {
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(e.Details), &body); err == nil && len(body.Error) > 0 {
+		var msg string
+		if json.Unmarshal(body.Error, &msg) == nil {
+			return msg
+		}
+		var obj struct {
+			Message string `json:"message"`
+			Code    string `json:"code"`
+		}
+		if json.Unmarshal(body.Error, &obj) == nil && obj.Message != "" {
+			return obj.Message
+		}
+	}
+
+	detail := strings.TrimSpace(e.Details)
+	if len(detail) > 200 {
+		detail = detail[:200] + "…"
+	}
+	return detail
+}
+
+// newAPIError cria o erro a partir da resposta e o classifica
+func newAPIError(statusCode int, status, body string) *APIError {
+	e := &APIError{StatusCode: statusCode, Message: status, Details: body}
+	e.Kind = classify(statusCode, strings.ToLower(e.Detail()+" "+body))
+	return e
+}
+
+// classify identifica o tipo de erro pelo status HTTP e pela mensagem
+func classify(statusCode int, msg string) error {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden,
+		strings.Contains(msg, "invalid_api_key"), strings.Contains(msg, "unauthorized"):
+		return ErrUnauthorized
+	case strings.Contains(msg, "context length"), strings.Contains(msg, "context window"),
+		strings.Contains(msg, "maximum context"), strings.Contains(msg, "context_length_exceeded"),
+		strings.Contains(msg, "too many tokens"), statusCode == http.StatusRequestEntityTooLarge:
+		return ErrContextTooLong
+	case strings.Contains(msg, "model_not_found"),
+		strings.Contains(msg, "model") && (strings.Contains(msg, "not found") || strings.Contains(msg, "does not exist")):
+		return ErrModelNotFound
+	case statusCode == http.StatusGatewayTimeout:
+		return ErrTimeout
+	case statusCode == http.StatusBadGateway || statusCode == http.StatusServiceUnavailable:
+		return ErrServerUnavailable
+	}
+	return nil
+}
+
+// connectionError classifica uma falha de transporte: timeout ou servidor inacessível.
+// O erro original continua na cadeia (ex.: errors.Is(err, context.DeadlineExceeded)).
+func connectionError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.Is(err, context.Canceled):
+		return err
+	}
+	return fmt.Errorf("erro de conexão com a API (%w): %w", ErrServerUnavailable, err)
+}
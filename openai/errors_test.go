@@ -0,0 +1,122 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIErrorClassification(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+		message  string
+	}{
+		{
+			name:     "Modelo inexistente (Ollama)",
+			status:   http.StatusNotFound,
+			body:     `{"error":"model 'codellama:70b' not found, try pulling it first"}`,
+			expected: ErrModelNotFound,
+			message:  "model 'codellama:70b' not found",
+		},
+		{
+			name:     "Modelo inexistente (OpenAI)",
+			status:   http.StatusNotFound,
+			body:     `{"error":{"message":"The model gpt-9 does not exist","type":"invalid_request_error","code":"model_not_found"}}`,
+			expected: ErrModelNotFound,
+			message:  "The model gpt-9 does not exist",
+		},
+		{
+			name:     "Não autorizado",
+			status:   http.StatusUnauthorized,
+			body:     `{"error":{"message":"Incorrect API key provided","code":"invalid_api_key"}}`,
+			expected: ErrUnauthorized,
+		},
+		{
+			name:     "Contexto excedido (OpenAI)",
+			status:   http.StatusBadRequest,
+			body:     `{"error":{"message":"This model's maximum context length is 4096 tokens","code":"context_length_exceeded"}}`,
+			expected: ErrContextTooLong,
+		},
+		{
+			name:     "Contexto excedido (Ollama)",
+			status:   http.StatusBadRequest,
+			body:     `{"error":"input length exceeds the context length"}`,
+			expected: ErrContextTooLong,
+		},
+		{name: "Gateway indisponível", status: http.StatusServiceUnavailable, body: "", expected: ErrServerUnavailable},
+		{name: "Gateway timeout", status: http.StatusGatewayTimeout, body: "upstream timeout", expected: ErrTimeout},
+		{name: "Erro genérico", status: http.StatusInternalServerError, body: "Internal Server Error", message: "Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := Generate(context.Background(), "x", &Config{APIURL: server.URL, Model: "codellama", Timeout: 5 * time.Second})
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("Generate() erro = %v, want *APIError com status %d", err, tt.status)
+			}
+			if tt.expected != nil && !errors.Is(err, tt.expected) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.expected)
+			}
+			if tt.expected == nil && apiErr.Kind != nil {
+				t.Errorf("Kind = %v, want nil", apiErr.Kind)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Error() = %q, want contendo %q", err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestConnectionErrors(t *testing.T) {
+	t.Run("Servidor indisponível", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		_, err := Generate(context.Background(), "x", &Config{APIURL: server.URL, Timeout: time.Second})
+		if !errors.Is(err, ErrServerUnavailable) {
+			t.Errorf("Generate() erro = %v, want ErrServerUnavailable", err)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		_, err := Generate(context.Background(), "x", &Config{APIURL: server.URL, Timeout: 50 * time.Millisecond})
+		if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Generate() erro = %v, want ErrTimeout e context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("Cancelamento", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := Generate(ctx, "x", &Config{APIURL: "http://127.0.0.1:1", Timeout: time.Second})
+		if !errors.Is(err, context.Canceled) || errors.Is(err, ErrServerUnavailable) {
+			t.Errorf("Generate() erro = %v, want apenas context.Canceled", err)
+		}
+	})
+}
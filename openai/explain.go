@@ -2,18 +2,87 @@ package openai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/mvcbotelho/code-explainer/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 )
 
+// httpClient é compartilhado entre todas as chamadas para reaproveitar conexões.
+// O timeout de cada requisição é aplicado via contexto (ver Config.Timeout).
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	CheckRedirect: checkRedirect,
+}
+
+// guardKey guarda no contexto da requisição o Config.Guard, consultado de novo a cada
+// redirecionamento
+type guardKey struct{}
+
+// guardError marca a recusa do Guard durante um redirecionamento, para que send a
+// devolva sem tratá-la como erro de conexão
+type guardError struct{ err error }
+
+func (e guardError) Error() string { return e.err.Error() }
+func (e guardError) Unwrap() error { return e.err }
+
+// WithGuard associa guard ao contexto, para que o cliente compartilhado o consulte
+// também nos redirecionamentos de requisições feitas fora deste pacote
+func WithGuard(ctx context.Context, guard func(url string) error) context.Context {
+	if guard == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, guardKey{}, guard)
+}
+
+// checkRedirect aplica o Guard da requisição original ao destino de cada
+// redirecionamento, mantendo o limite padrão de 10 saltos
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("redirecionamentos demais")
+	}
+	if guard, ok := req.Context().Value(guardKey{}).(func(string) error); ok {
+		if err := guard(req.URL.String()); err != nil {
+			return guardError{err}
+		}
+	}
+	return nil
+}
+
+// HTTPClient retorna o cliente HTTP compartilhado usado nas chamadas à API
+func HTTPClient() *http.Client {
+	return httpClient
+}
+
 // Config contém as configurações para a API
 type Config struct {
-	APIURL  string
-	Model   string
-	Timeout time.Duration
+	APIURL   string
+	Model    string
+	Timeout  time.Duration
+	Language string // Linguagem forçada; vazia para detecção automática
+	Level    string // Nível de detalhamento do prompt (ver GetPromptLevels)
+
+	// Guard, se definido, é consultado antes de cada requisição e pode recusar a URL
+	// (ver o pacote policy)
+	Guard func(url string) error
+
+	// OnUsage, se definido, recebe os tokens e tempos informados pelo servidor em
+	// cada resposta concluída
+	OnUsage UsageFunc
 }
 
 // DefaultConfig retorna uma configuração padrão
@@ -36,34 +105,51 @@ type Request struct {
 type Response struct {
 	Response string `json:"response"`
 	Done     bool   `json:"done"`
-}
-
-// APIError representa um erro específico da API
-type APIError struct {
-	StatusCode int
-	Message    string
-	Details    string
-}
-
-func (e *APIError) Error() string {
-	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+	Metrics
 }
 
 // ExplainCode envia código para análise via API com configuração customizável
 func ExplainCode(code string, config *Config) (string, error) {
+	return ExplainCodeContext(context.Background(), code, config)
+}
+
+// ExplainCodeContext é como ExplainCode, mas respeita o cancelamento de ctx.
+// O timeout de config é aplicado a cada requisição individualmente.
+func ExplainCodeContext(ctx context.Context, code string, config *Config) (string, error) {
 	if config == nil {
 		config = DefaultConfig()
 	}
 
-	// Usa variável de ambiente se disponível
-	if model := os.Getenv("MODEL_NAME"); model != "" {
-		config.Model = model
-	}
+	return Generate(ctx, buildPrompt(ctx, code, config), config)
+}
+
+// DetectLanguageContext é como DetectLanguage, registrando a detecção em um span filho de ctx
+func DetectLanguageContext(ctx context.Context, code string) string {
+	_, span := tracing.Start(ctx, "detect_language", attribute.Int("code.chars", len(code)))
+	defer span.End()
 
 	lang := DetectLanguage(code)
-	prompt := fmt.Sprintf(`Explique o que o seguinte código em %s faz:
+	span.SetAttributes(attribute.String("code.language", lang))
+	return lang
+}
+
+// buildPrompt detecta a linguagem, se não for forçada, e monta o prompt, registrando um span para cada etapa
+func buildPrompt(ctx context.Context, code string, config *Config) string {
+	lang := config.Language
+	if lang == "" {
+		lang = DetectLanguageContext(ctx, code)
+	}
 
-%s`, lang, code)
+	_, span := tracing.Start(ctx, "build_prompt", attribute.String("code.language", lang), attribute.String("prompt.level", config.Level))
+	prompt := BuildPrompt(code, lang, config.Level)
+	span.SetAttributes(attribute.Int("prompt.chars", len(prompt)))
+	span.End()
+	return prompt
+}
+
+// Generate envia um prompt já montado para a API e retorna a resposta do modelo
+func Generate(ctx context.Context, prompt string, config *Config) (string, error) {
+	config = effectiveConfig(config)
 
 	body := Request{
 		Model:  config.Model,
@@ -71,40 +157,152 @@ func ExplainCode(code string, config *Config) (string, error) {
 		Stream: false,
 	}
 
-	buf := new(bytes.Buffer)
-	if err := json.NewEncoder(buf).Encode(body); err != nil {
-		return "", fmt.Errorf("erro ao codificar requisição: %w", err)
+	var r Response
+	if err := postJSON(ctx, config.APIURL, body, &r, config); err != nil {
+		return "", err
 	}
+	reportUsage(config, r.Metrics)
+
+	return r.Response, nil
+}
 
-	// Cria cliente HTTP com timeout
-	client := &http.Client{
-		Timeout: config.Timeout,
+// effectiveConfig retorna uma cópia da configuração com as variáveis de ambiente aplicadas
+func effectiveConfig(config *Config) *Config {
+	if config == nil {
+		config = DefaultConfig()
+	} else {
+		// Copia para não alterar a configuração do chamador (pode ser compartilhada entre goroutines)
+		c := *config
+		config = &c
 	}
 
-	resp, err := client.Post(config.APIURL, "application/json", buf)
+	config.Model = EffectiveModel(config)
+	return config
+}
+
+// ModelOverride retorna o modelo da variável MODEL_NAME, que tem precedência sobre
+// Config.Model em todas as chamadas à API; vazio se ela não estiver definida
+func ModelOverride() string {
+	return os.Getenv("MODEL_NAME")
+}
+
+// EffectiveModel retorna o modelo que será de fato usado com config
+func EffectiveModel(config *Config) string {
+	if model := ModelOverride(); model != "" {
+		return model
+	}
+	return config.Model
+}
+
+// postJSON envia body para url e decodifica a resposta em out, aplicando o timeout de config
+func postJSON(ctx context.Context, url string, body, out interface{}, config *Config) error {
+	return doJSON(ctx, url, body, out, config)
+}
+
+// getJSON faz um GET em url e decodifica a resposta em out, aplicando o timeout de config
+func getJSON(ctx context.Context, url string, out interface{}, config *Config) error {
+	return doJSON(ctx, url, nil, out, config)
+}
+
+// doJSON envia a requisição (GET se body for nil) e decodifica a resposta em out
+func doJSON(ctx context.Context, url string, body, out interface{}, config *Config) error {
+	resp, cancel, err := send(ctx, url, body, config)
 	if err != nil {
-		return "", fmt.Errorf("erro de conexão com a API: %w", err)
+		return err
 	}
+	defer cancel()
 	defer resp.Body.Close()
 
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		if ctxErr := resp.Request.Context().Err(); ctxErr != nil {
+			return connectionError(ctxErr)
+		}
+		return fmt.Errorf("erro ao decodificar resposta da API: %w", err)
+	}
+
+	return nil
+}
+
+// send faz o POST de body em url (ou um GET, se body for nil) e retorna a resposta com
+// status 200. O chamador deve fechar o corpo e chamar cancel, que libera o timeout de
+// config, ao terminar de lê-lo.
+func send(ctx context.Context, url string, body interface{}, config *Config) (*http.Response, context.CancelFunc, error) {
+	if config.Guard != nil {
+		if err := config.Guard(url); err != nil {
+			return nil, nil, err
+		}
+		ctx = WithGuard(ctx, config.Guard)
+	}
+
+	method := http.MethodGet
+	var buf *bytes.Buffer
+	if body != nil {
+		method = http.MethodPost
+		buf = new(bytes.Buffer)
+		if err := json.NewEncoder(buf).Encode(body); err != nil {
+			return nil, nil, fmt.Errorf("erro ao codificar requisição: %w", err)
+		}
+	}
+
+	timeoutCancel := context.CancelFunc(func() {})
+	if config.Timeout > 0 {
+		ctx, timeoutCancel = context.WithTimeout(ctx, config.Timeout)
+	}
+
+	var reader io.Reader
+	if buf != nil {
+		reader = buf
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		timeoutCancel()
+		return nil, nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// O span do cliente dura até o corpo ser lido (cancel), incluindo o streaming
+	ctx, span := tracing.Start(ctx, method+" "+req.URL.Path,
+		attribute.String("http.request.method", method),
+		attribute.String("server.address", req.URL.Host),
+		attribute.String("url.path", req.URL.Path),
+		attribute.String("gen_ai.request.model", config.Model),
+	)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var blocked guardError
+		if errors.As(err, &blocked) {
+			err = blocked.err
+		} else {
+			err = connectionError(err)
+		}
+		tracing.End(span, err)
+		timeoutCancel()
+		return nil, nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
 	if resp.StatusCode != http.StatusOK {
+		defer timeoutCancel()
+		defer resp.Body.Close()
+
 		// Tenta ler o corpo da resposta para mais detalhes
 		var errorBody bytes.Buffer
 		errorBody.ReadFrom(resp.Body)
 
-		return "", &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    resp.Status,
-			Details:    errorBody.String(),
-		}
+		err := newAPIError(resp.StatusCode, resp.Status, errorBody.String())
+		tracing.End(span, err)
+		return nil, nil, err
 	}
 
-	var r Response
-	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-		return "", fmt.Errorf("erro ao decodificar resposta da API: %w", err)
+	cancel := func() {
+		span.End()
+		timeoutCancel()
 	}
-
-	return r.Response, nil
+	return resp, cancel, nil
 }
 
 // ExplainCodeWithDefaultURL é uma função de conveniência que usa a URL padrão
@@ -130,6 +328,10 @@ func ValidateConfig(config *Config) error {
 		return fmt.Errorf("timeout deve ser maior que zero")
 	}
 
+	if err := ValidateLevel(config.Level); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -2,11 +2,14 @@ package openai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -432,3 +435,94 @@ func TestExplainCodeWithDifferentLanguages(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildPrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    string
+		contains string
+	}{
+		{name: "Nível padrão", level: "", contains: "Explique o que o seguinte código em Go faz:"},
+		{name: "Nível intermediário", level: LevelIntermediate, contains: "Explique o que o seguinte código em Go faz:"},
+		{name: "Nível básico", level: LevelBasic, contains: "começando a programar"},
+		{name: "Nível avançado", level: LevelAdvanced, contains: "complexidade"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := BuildPrompt("func main() {}", "Go", tt.level)
+			if !strings.Contains(prompt, tt.contains) {
+				t.Errorf("Expected prompt to contain %q, got %q", tt.contains, prompt)
+			}
+			if !strings.HasSuffix(prompt, "func main() {}") {
+				t.Errorf("Expected prompt to end with the code, got %q", prompt)
+			}
+		})
+	}
+
+	if ValidateLevel("expert") == nil {
+		t.Errorf("Expected error for unknown level")
+	}
+}
+
+func TestConfigGuardRedirect(t *testing.T) {
+	called := false
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Write([]byte(`{"response":"ok","done":true}`))
+	}))
+	defer denied.Close()
+
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, denied.URL+"/api/generate", http.StatusFound)
+	}))
+	defer allowed.Close()
+
+	blocked := errors.New("bloqueado")
+	var checked []string
+	config := &Config{APIURL: allowed.URL + "/api/generate", Timeout: 5 * time.Second, Guard: func(url string) error {
+		checked = append(checked, url)
+		if strings.HasPrefix(url, denied.URL) {
+			return blocked
+		}
+		return nil
+	}}
+
+	_, err := Generate(context.Background(), "x", config)
+	if !errors.Is(err, blocked) {
+		t.Errorf("Generate() erro = %v, want o erro do Guard", err)
+	}
+	if errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("Generate() erro = %v, a recusa não deveria ser um erro de conexão", err)
+	}
+	if called {
+		t.Error("o redirecionamento foi seguido apesar do Guard")
+	}
+	if len(checked) != 2 || checked[1] != denied.URL+"/api/generate" {
+		t.Errorf("Guard consultado com %v, want a URL original e o destino do redirecionamento", checked)
+	}
+}
+
+func TestConfigGuard(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Write([]byte(`{"response":"ok","done":true}`))
+	}))
+	defer server.Close()
+
+	blocked := errors.New("bloqueado")
+	config := &Config{APIURL: server.URL + "/api/generate", Timeout: 5 * time.Second, Guard: func(url string) error {
+		if url != server.URL+"/api/generate" {
+			t.Errorf("Guard recebeu %q", url)
+		}
+		return blocked
+	}}
+
+	if _, err := Generate(context.Background(), "x", config); !errors.Is(err, blocked) {
+		t.Errorf("Generate() erro = %v, want o erro do Guard", err)
+	}
+	if called {
+		t.Error("a requisição foi enviada apesar do Guard")
+	}
+}
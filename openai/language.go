@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -111,10 +112,13 @@ var languagePatterns = []LanguagePattern{
 	},
 }
 
+// UnknownLanguage é retornado por DetectLanguage quando nenhum padrão corresponde
+const UnknownLanguage = "linguagem desconhecida"
+
 // DetectLanguage tenta identificar a linguagem do código com base em padrões de expressões regulares
 func DetectLanguage(code string) string {
 	if code == "" {
-		return "linguagem desconhecida"
+		return UnknownLanguage
 	}
 
 	// Normaliza o código para análise
@@ -132,7 +136,7 @@ func DetectLanguage(code string) string {
 		}
 	}
 
-	return "linguagem desconhecida"
+	return UnknownLanguage
 }
 
 // removeComments remove comentários comuns para melhorar a detecção
@@ -200,3 +204,51 @@ func AddLanguagePattern(language string, patterns []string, priority int) error
 	languagePatterns = append(languagePatterns, newPattern)
 	return nil
 }
+
+// languageExtensions mapeia extensões de arquivo para as linguagens suportadas
+var languageExtensions = map[string]string{
+	".go":   "Go",
+	".py":   "Python",
+	".js":   "JavaScript",
+	".mjs":  "JavaScript",
+	".cjs":  "JavaScript",
+	".jsx":  "JavaScript",
+	".c":    "C",
+	".h":    "C",
+	".java": "Java",
+	".php":  "PHP",
+	".rs":   "Rust",
+	".cs":   "C#",
+}
+
+// LanguageFromFilename retorna a linguagem associada à extensão do arquivo,
+// ou string vazia se a extensão não for reconhecida
+func LanguageFromFilename(name string) string {
+	return languageExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// languageIDs mapeia os identificadores de linguagem de editores (languageId do LSP)
+// para as linguagens suportadas
+var languageIDs = map[string]string{
+	"go":              "Go",
+	"python":          "Python",
+	"javascript":      "JavaScript",
+	"javascriptreact": "JavaScript",
+	"typescript":      "JavaScript",
+	"typescriptreact": "JavaScript",
+	"c":               "C",
+	"cpp":             "C",
+	"java":            "Java",
+	"php":             "PHP",
+	"rust":            "Rust",
+	"csharp":          "C#",
+}
+
+// DetectLanguageWithHint usa o identificador de linguagem do editor como dica;
+// se ele não for reconhecido, detecta a linguagem pelo código
+func DetectLanguageWithHint(code, languageID string) string {
+	if lang, ok := languageIDs[strings.ToLower(languageID)]; ok {
+		return lang
+	}
+	return DetectLanguage(code)
+}
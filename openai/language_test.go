@@ -233,3 +233,51 @@ func TestDetectLanguagePriority(t *testing.T) {
 		t.Errorf("DetectLanguage() deveria priorizar Go, got %v", result)
 	}
 }
+
+func TestLanguageFromFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		expected string
+	}{
+		{name: "Go", filename: "main.go", expected: "Go"},
+		{name: "Python com caminho", filename: "scripts/build.py", expected: "Python"},
+		{name: "Extensão maiúscula", filename: "App.JAVA", expected: "Java"},
+		{name: "Header C", filename: "include/util.h", expected: "C"},
+		{name: "C#", filename: "Program.cs", expected: "C#"},
+		{name: "Extensão desconhecida", filename: "README.md", expected: ""},
+		{name: "Sem extensão", filename: "Makefile", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := LanguageFromFilename(tt.filename)
+			if result != tt.expected {
+				t.Errorf("LanguageFromFilename(%q) = %v, want %v", tt.filename, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectLanguageWithHint(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		languageID string
+		expected   string
+	}{
+		{name: "Dica reconhecida", code: "x = 1", languageID: "python", expected: "Python"},
+		{name: "TypeScript como JavaScript", code: "let x: number = 1", languageID: "typescript", expected: "JavaScript"},
+		{name: "Dica desconhecida usa o código", code: "package main\n\nfunc main() {}", languageID: "plaintext", expected: "Go"},
+		{name: "Sem dica", code: "package main\n\nfunc main() {}", languageID: "", expected: "Go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DetectLanguageWithHint(tt.code, tt.languageID)
+			if result != tt.expected {
+				t.Errorf("DetectLanguageWithHint(%q) = %v, want %v", tt.languageID, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Model descreve um modelo disponível no servidor
+type Model struct {
+	Name          string
+	Size          int64 // Em bytes; 0 quando o servidor não informa
+	Family        string
+	ParameterSize string
+	Quantization  string
+	ModifiedAt    time.Time
+	Recommended   bool // Modelo especializado em código
+}
+
+// tagsResponse representa a resposta de /api/tags do Ollama
+type tagsResponse struct {
+	Models []struct {
+		Name       string    `json:"name"`
+		Size       int64     `json:"size"`
+		ModifiedAt time.Time `json:"modified_at"`
+		Details    struct {
+			Family            string `json:"family"`
+			ParameterSize     string `json:"parameter_size"`
+			QuantizationLevel string `json:"quantization_level"`
+		} `json:"details"`
+	} `json:"models"`
+}
+
+// modelsResponse representa a resposta de /v1/models de servidores compatíveis com OpenAI
+type modelsResponse struct {
+	Data []struct {
+		ID      string `json:"id"`
+		Created int64  `json:"created"`
+		OwnedBy string `json:"owned_by"`
+	} `json:"data"`
+}
+
+// BaseURL remove da URL configurada o caminho do endpoint (/api/generate, /v1/chat/completions etc.)
+func BaseURL(apiURL string) string {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	for _, suffix := range []string{"/api/generate", "/api/chat", "/v1/chat/completions", "/v1/completions", "/v1"} {
+		if strings.HasSuffix(apiURL, suffix) {
+			return strings.TrimSuffix(apiURL, suffix)
+		}
+	}
+	return apiURL
+}
+
+// ListModels consulta os modelos instalados via /api/tags do Ollama e, se o endpoint não
+// existir, via /v1/models de servidores compatíveis com OpenAI. O resultado é ordenado
+// com os modelos de código primeiro.
+func ListModels(ctx context.Context, config *Config) ([]Model, error) {
+	config = effectiveConfig(config)
+	base := BaseURL(config.APIURL)
+
+	models, err := listOllamaModels(ctx, base, config)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		models, err = listOpenAIModels(ctx, base, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(models, func(i, j int) bool {
+		if models[i].Recommended != models[j].Recommended {
+			return models[i].Recommended
+		}
+		return models[i].Name < models[j].Name
+	})
+	return models, nil
+}
+
+// listOllamaModels consulta /api/tags
+func listOllamaModels(ctx context.Context, base string, config *Config) ([]Model, error) {
+	var r tagsResponse
+	if err := getJSON(ctx, base+"/api/tags", &r, config); err != nil {
+		return nil, err
+	}
+
+	models := make([]Model, 0, len(r.Models))
+	for _, m := range r.Models {
+		models = append(models, Model{
+			Name:          m.Name,
+			Size:          m.Size,
+			Family:        m.Details.Family,
+			ParameterSize: m.Details.ParameterSize,
+			Quantization:  m.Details.QuantizationLevel,
+			ModifiedAt:    m.ModifiedAt,
+			Recommended:   IsCodeModel(m.Name),
+		})
+	}
+	return models, nil
+}
+
+// listOpenAIModels consulta /v1/models
+func listOpenAIModels(ctx context.Context, base string, config *Config) ([]Model, error) {
+	var r modelsResponse
+	if err := getJSON(ctx, base+"/v1/models", &r, config); err != nil {
+		return nil, err
+	}
+
+	models := make([]Model, 0, len(r.Data))
+	for _, m := range r.Data {
+		model := Model{Name: m.ID, Family: m.OwnedBy, Recommended: IsCodeModel(m.ID)}
+		if m.Created > 0 {
+			model.ModifiedAt = time.Unix(m.Created, 0).UTC()
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+// IsCodeModel informa se o nome indica um modelo especializado em código
+// (codellama, deepseek-coder, qwen2.5-coder, starcoder2, codegemma, codestral...)
+func IsCodeModel(name string) bool {
+	return strings.Contains(strings.ToLower(name), "code")
+}
+
+// FormatSize formata um tamanho em bytes de forma legível (ex.: 3.8 GB)
+func FormatSize(size int64) string {
+	if size <= 0 {
+		return "N/A"
+	}
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
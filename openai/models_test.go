@@ -0,0 +1,118 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiURL   string
+		expected string
+	}{
+		{name: "Generate", apiURL: "http://localhost:11434/api/generate", expected: "http://localhost:11434"},
+		{name: "Chat", apiURL: "http://localhost:11434/api/chat/", expected: "http://localhost:11434"},
+		{name: "OpenAI", apiURL: "http://localhost:8000/v1/chat/completions", expected: "http://localhost:8000"},
+		{name: "Somente host", apiURL: "http://ollama:11434", expected: "http://ollama:11434"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BaseURL(tt.apiURL); got != tt.expected {
+				t.Errorf("BaseURL() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestListModels(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		expected  []Model
+		expectErr bool
+	}{
+		{
+			name: "Ollama",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/api/tags" {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write([]byte(`{"models":[
+					{"name":"llama3:8b","size":4661224676,"modified_at":"2024-05-01T10:00:00Z","details":{"family":"llama","parameter_size":"8.0B","quantization_level":"Q4_0"}},
+					{"name":"codellama:7b","size":3825819519,"modified_at":"2024-04-01T10:00:00Z","details":{"family":"llama","parameter_size":"7B","quantization_level":"Q4_0"}}
+				]}`))
+			},
+			expected: []Model{
+				{Name: "codellama:7b", Size: 3825819519, Family: "llama", ParameterSize: "7B", Quantization: "Q4_0", ModifiedAt: time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC), Recommended: true},
+				{Name: "llama3:8b", Size: 4661224676, Family: "llama", ParameterSize: "8.0B", Quantization: "Q4_0", ModifiedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "Compatível com OpenAI",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/models" {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write([]byte(`{"object":"list","data":[{"id":"qwen2.5-coder","object":"model","created":1714557600,"owned_by":"vllm"}]}`))
+			},
+			expected: []Model{
+				{Name: "qwen2.5-coder", Family: "vllm", ModifiedAt: time.Unix(1714557600, 0).UTC(), Recommended: true},
+			},
+		},
+		{
+			name: "Erro do servidor",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "falha", http.StatusInternalServerError)
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			config := &Config{APIURL: server.URL + "/api/generate", Timeout: 5 * time.Second}
+			models, err := ListModels(context.Background(), config)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ListModels() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if len(models) != len(tt.expected) {
+				t.Fatalf("ListModels() retornou %d modelos, want %d", len(models), len(tt.expected))
+			}
+			for i := range models {
+				if models[i] != tt.expected[i] {
+					t.Errorf("ListModels()[%d] = %+v, want %+v", i, models[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{size: 0, expected: "N/A"},
+		{size: 512, expected: "512 B"},
+		{size: 3825819519, expected: "3.8 GB"},
+		{size: 1500000, expected: "1.5 MB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := FormatSize(tt.size); got != tt.expected {
+				t.Errorf("FormatSize(%d) = %v, want %v", tt.size, got, tt.expected)
+			}
+		})
+	}
+}
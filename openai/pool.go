@@ -0,0 +1,129 @@
+package openai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ExplainFunc é a assinatura das funções que explicam um trecho de código.
+// ExplainCodeContext é a implementação padrão.
+type ExplainFunc func(ctx context.Context, code string, config *Config) (string, error)
+
+// Job representa um trecho de código a ser explicado pelo pool
+type Job struct {
+	ID     string // Identificador livre (ex.: caminho do arquivo)
+	Code   string
+	Config *Config
+}
+
+// Result representa o resultado de um Job
+type Result struct {
+	Index       int // Posição do Job na lista original
+	ID          string
+	Explanation string
+	Err         error
+	Duration    time.Duration
+}
+
+// ExplainAll explica todos os jobs usando no máximo concurrency requisições
+// simultâneas. Os resultados são devolvidos na mesma ordem dos jobs, e a
+// falha de um job não interrompe os demais. Se fn for nil, ExplainCodeContext
+// é usada.
+func ExplainAll(ctx context.Context, jobs []Job, concurrency int, fn ExplainFunc) []Result {
+	results := make([]Result, 0, len(jobs))
+	ExplainEach(ctx, jobs, concurrency, fn, func(r Result) {
+		results = append(results, r)
+	})
+	return results
+}
+
+// ExplainEach funciona como ExplainAll, mas entrega cada resultado a onResult
+// assim que ele e todos os anteriores estiverem prontos, preservando a ordem
+// dos jobs. onResult é sempre chamada pela goroutine do chamador.
+func ExplainEach(ctx context.Context, jobs []Job, concurrency int, fn ExplainFunc, onResult func(Result)) {
+	if len(jobs) == 0 {
+		return
+	}
+	if fn == nil {
+		fn = ExplainCodeContext
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(jobs) {
+		concurrency = len(jobs)
+	}
+
+	indexes := make(chan int)
+	done := make(chan Result)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				done <- runJob(ctx, i, jobs[i], fn)
+			}
+		}()
+	}
+
+	// Distribui os jobs; os que não chegarem a iniciar recebem o erro do contexto
+	go func() {
+		defer close(indexes)
+		for i := range jobs {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				for j := i; j < len(jobs); j++ {
+					done <- Result{Index: j, ID: jobs[j].ID, Err: ctx.Err()}
+				}
+				return
+			}
+		}
+	}()
+
+	// Coleta os resultados e os entrega em ordem
+	pending := make(map[int]Result)
+	next := 0
+	for next < len(jobs) {
+		r := <-done
+		pending[r.Index] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			onResult(r)
+			next++
+		}
+	}
+	wg.Wait()
+}
+
+// runJob executa um único job medindo sua duração
+func runJob(ctx context.Context, index int, job Job, fn ExplainFunc) Result {
+	start := time.Now()
+	explanation, err := fn(ctx, job.Code, job.Config)
+
+	return Result{
+		Index:       index,
+		ID:          job.ID,
+		Explanation: explanation,
+		Err:         err,
+		Duration:    time.Since(start),
+	}
+}
+
+// CountFailures retorna quantos resultados terminaram com erro
+func CountFailures(results []Result) int {
+	failures := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failures++
+		}
+	}
+	return failures
+}
@@ -0,0 +1,146 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExplainAllOrderedResults(t *testing.T) {
+	jobs := make([]Job, 10)
+	for i := range jobs {
+		jobs[i] = Job{ID: fmt.Sprintf("job-%d", i), Code: fmt.Sprintf("code-%d", i)}
+	}
+
+	// Jobs iniciais demoram mais para forçar conclusão fora de ordem
+	fn := func(ctx context.Context, code string, config *Config) (string, error) {
+		var n int
+		fmt.Sscanf(code, "code-%d", &n)
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		return "explicação " + code, nil
+	}
+
+	results := ExplainAll(context.Background(), jobs, 4, fn)
+
+	if len(results) != len(jobs) {
+		t.Fatalf("Expected %d results, got %d", len(jobs), len(results))
+	}
+
+	for i, r := range results {
+		if r.Index != i {
+			t.Errorf("Expected index %d, got %d", i, r.Index)
+		}
+		if r.ID != jobs[i].ID {
+			t.Errorf("Expected ID %s, got %s", jobs[i].ID, r.ID)
+		}
+		if r.Explanation != "explicação "+jobs[i].Code {
+			t.Errorf("Unexpected explanation for %s: %s", r.ID, r.Explanation)
+		}
+	}
+}
+
+func TestExplainAllConcurrencyLimit(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	fn := func(ctx context.Context, code string, config *Config) (string, error) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return "ok", nil
+	}
+
+	jobs := make([]Job, 20)
+	ExplainAll(context.Background(), jobs, 3, fn)
+
+	if maxInFlight > 3 {
+		t.Errorf("Expected at most 3 concurrent requests, got %d", maxInFlight)
+	}
+	if maxInFlight < 2 {
+		t.Errorf("Expected requests to run in parallel, max in flight was %d", maxInFlight)
+	}
+}
+
+func TestExplainAllPartialFailure(t *testing.T) {
+	fn := func(ctx context.Context, code string, config *Config) (string, error) {
+		if code == "falha" {
+			return "", errors.New("erro simulado")
+		}
+		return "ok", nil
+	}
+
+	jobs := []Job{{Code: "a"}, {Code: "falha"}, {Code: "b"}}
+	results := ExplainAll(context.Background(), jobs, 2, fn)
+
+	if CountFailures(results) != 1 {
+		t.Errorf("Expected 1 failure, got %d", CountFailures(results))
+	}
+	if results[1].Err == nil {
+		t.Errorf("Expected error for job 1")
+	}
+	if results[0].Explanation != "ok" || results[2].Explanation != "ok" {
+		t.Errorf("Expected successful jobs to keep their results")
+	}
+}
+
+func TestExplainAllCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fn := func(ctx context.Context, code string, config *Config) (string, error) {
+		return "", ctx.Err()
+	}
+
+	results := ExplainAll(ctx, make([]Job, 5), 2, fn)
+
+	if CountFailures(results) != len(results) {
+		t.Errorf("Expected all jobs to fail with cancelled context, got %d failures", CountFailures(results))
+	}
+}
+
+func TestExplainAllPerRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if req.Model == "lento" {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		json.NewEncoder(w).Encode(Response{Response: "ok", Done: true})
+	}))
+	defer server.Close()
+
+	fast := &Config{APIURL: server.URL, Model: "rapido", Timeout: 5 * time.Second}
+	slow := &Config{APIURL: server.URL, Model: "lento", Timeout: 50 * time.Millisecond}
+
+	jobs := []Job{
+		{ID: "rapido", Code: "print('a')", Config: fast},
+		{ID: "lento", Code: "print('b')", Config: slow},
+	}
+
+	results := ExplainAll(context.Background(), jobs, 2, nil)
+
+	if results[0].Err != nil {
+		t.Errorf("Expected fast job to succeed, got %v", results[0].Err)
+	}
+	if !errors.Is(results[1].Err, context.DeadlineExceeded) {
+		t.Errorf("Expected slow job to time out, got %v", results[1].Err)
+	}
+}
@@ -0,0 +1,55 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Níveis de detalhamento do prompt
+const (
+	LevelBasic        = "basico"
+	LevelIntermediate = "intermediario"
+	LevelAdvanced     = "avancado"
+)
+
+// levelInstructions contém as instruções adicionais de cada nível.
+// O nível intermediário mantém o prompt original, sem instruções extras.
+var levelInstructions = map[string]string{
+	LevelBasic:        "Explique de forma simples, para alguém que está começando a programar, evitando jargões.",
+	LevelIntermediate: "",
+	LevelAdvanced:     "Seja técnico e detalhado: comente complexidade, decisões de design, casos de borda e possíveis problemas.",
+}
+
+// GetPromptLevels retorna os níveis de prompt suportados
+func GetPromptLevels() []string {
+	return []string{LevelBasic, LevelIntermediate, LevelAdvanced}
+}
+
+// ValidateLevel verifica se o nível de prompt é suportado. Nível vazio equivale ao intermediário.
+func ValidateLevel(level string) error {
+	if level == "" {
+		return nil
+	}
+	if _, ok := levelInstructions[level]; !ok {
+		return fmt.Errorf("nível de prompt inválido: %s (use %s)", level, strings.Join(GetPromptLevels(), ", "))
+	}
+	return nil
+}
+
+// LevelInstructions retorna as instruções adicionais do nível de prompt (vazio para o padrão)
+func LevelInstructions(level string) string {
+	return levelInstructions[level]
+}
+
+// BuildPrompt monta o prompt de explicação para o código na linguagem e nível informados
+func BuildPrompt(code, lang, level string) string {
+	prompt := fmt.Sprintf(`Explique o que o seguinte código em %s faz:
+
+%s`, lang, code)
+
+	if instructions := LevelInstructions(level); instructions != "" {
+		prompt = instructions + "\n\n" + prompt
+	}
+
+	return prompt
+}
@@ -0,0 +1,95 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PullProgress é uma atualização de progresso de /api/pull
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// PullFunc recebe cada atualização de progresso do download
+type PullFunc func(p PullProgress)
+
+// pullRequest representa a requisição para /api/pull
+type pullRequest struct {
+	Model  string `json:"model"`
+	Stream bool   `json:"stream"`
+}
+
+// warmRequest carrega o modelo em memória: /api/generate sem prompt apenas carrega o modelo
+type warmRequest struct {
+	Model     string `json:"model"`
+	KeepAlive string `json:"keep_alive,omitempty"`
+	Stream    bool   `json:"stream"`
+}
+
+// Pull baixa o modelo via /api/pull do Ollama, chamando onProgress a cada atualização.
+// O download pode levar minutos; use config.Timeout = 0 para não limitá-lo.
+func Pull(ctx context.Context, model string, config *Config, onProgress PullFunc) error {
+	config = effectiveConfig(config)
+
+	resp, cancel, err := send(ctx, BaseURL(config.APIURL)+"/api/pull", pullRequest{Model: model, Stream: true}, config)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	// Um objeto JSON por linha até "status": "success"; falhas chegam no campo "error"
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var p PullProgress
+		if err := json.Unmarshal(line, &p); err != nil {
+			return fmt.Errorf("erro ao decodificar resposta da API: %w", err)
+		}
+		if p.Error != "" {
+			// Classificado como as respostas HTTP, para que um modelo inexistente tenha o código de
+			// saída próprio. O chamador acrescenta o contexto ("erro ao baixar modelo ...").
+			if kind := classify(0, strings.ToLower(p.Error)); kind != nil {
+				return fmt.Errorf("%w: %s", kind, p.Error)
+			}
+			return fmt.Errorf("erro informado pelo servidor: %s", p.Error)
+		}
+		if onProgress != nil {
+			onProgress(p)
+		}
+		if p.Status == "success" {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if ctxErr := resp.Request.Context().Err(); ctxErr != nil {
+			return connectionError(ctxErr)
+		}
+		return fmt.Errorf("erro ao ler resposta da API: %w", err)
+	}
+
+	return fmt.Errorf("resposta da API terminou antes da conclusão do download")
+}
+
+// Warm carrega o modelo em memória e o mantém carregado por keepAlive (ex.: "30m"),
+// evitando que a primeira requisição de um lote estoure o timeout.
+// keepAlive vazio usa o padrão do servidor.
+func Warm(ctx context.Context, model, keepAlive string, config *Config) error {
+	config = effectiveConfig(config)
+
+	var r Response
+	return postJSON(ctx, BaseURL(config.APIURL)+"/api/generate", warmRequest{Model: model, KeepAlive: keepAlive}, &r, config)
+}
@@ -0,0 +1,114 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPull(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		expected  []string
+		expectErr string
+		kind      error
+	}{
+		{
+			name: "Sucesso",
+			body: `{"status":"pulling manifest"}
+{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":40}
+{"status":"pulling abc","digest":"sha256:abc","total":100,"completed":100}
+{"status":"success"}
+`,
+			expected: []string{"pulling manifest", "pulling abc 40/100", "pulling abc 100/100", "success"},
+		},
+		{
+			name:      "Modelo inexistente",
+			body:      `{"status":"pulling manifest"}` + "\n" + `{"error":"pull model manifest: file does not exist"}` + "\n",
+			expected:  []string{"pulling manifest"},
+			expectErr: "file does not exist",
+			kind:      ErrModelNotFound,
+		},
+		{
+			name:      "Erro sem classificação",
+			body:      `{"error":"disk full"}` + "\n",
+			expectErr: "disk full",
+		},
+		{
+			name:      "Interrompido",
+			body:      `{"status":"pulling manifest"}` + "\n",
+			expected:  []string{"pulling manifest"},
+			expectErr: "antes da conclusão",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/pull" {
+					t.Errorf("Expected path /api/pull, got %s", r.URL.Path)
+				}
+				var req pullRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				if req.Model != "codellama:7b" || !req.Stream {
+					t.Errorf("Unexpected request: %+v", req)
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			var got []string
+			config := &Config{APIURL: server.URL + "/api/generate"}
+			err := Pull(context.Background(), "codellama:7b", config, func(p PullProgress) {
+				if p.Total > 0 {
+					got = append(got, fmt.Sprintf("%s %d/%d", p.Status, p.Completed, p.Total))
+					return
+				}
+				got = append(got, p.Status)
+			})
+
+			if tt.expectErr == "" && err != nil {
+				t.Fatalf("Pull() erro inesperado: %v", err)
+			}
+			if tt.expectErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expectErr)) {
+				t.Fatalf("Pull() erro = %v, want contendo %q", err, tt.expectErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "erro ao baixar modelo") {
+				t.Errorf("Pull() erro = %v; o prefixo é acrescentado pelo chamador", err)
+			}
+			if tt.kind != nil && !errors.Is(err, tt.kind) {
+				t.Errorf("Pull() erro = %v, want errors.Is(%v)", err, tt.kind)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("progresso = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWarm(t *testing.T) {
+	var received warmRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("Expected path /api/generate, got %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"model":"codellama","response":"","done":true,"done_reason":"load"}`))
+	}))
+	defer server.Close()
+
+	config := &Config{APIURL: server.URL + "/api/chat", Timeout: 5 * time.Second}
+	if err := Warm(context.Background(), "codellama", "30m", config); err != nil {
+		t.Fatalf("Warm() erro inesperado: %v", err)
+	}
+	if received.Model != "codellama" || received.KeepAlive != "30m" || received.Stream {
+		t.Errorf("requisição = %+v", received)
+	}
+}
@@ -0,0 +1,85 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TokenFunc recebe cada trecho da resposta à medida que o modelo o gera.
+// Retornar um erro interrompe o streaming.
+type TokenFunc func(token string) error
+
+// StreamFunc explica o código enviando a resposta em trechos; tem a mesma
+// forma de ExplainFunc, com o callback de tokens adicional
+type StreamFunc func(ctx context.Context, code string, config *Config, onToken TokenFunc) (string, error)
+
+// ExplainCodeStream é como ExplainCodeContext, mas entrega a resposta em trechos via onToken
+func ExplainCodeStream(ctx context.Context, code string, config *Config, onToken TokenFunc) (string, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return GenerateStream(ctx, buildPrompt(ctx, code, config), config, onToken)
+}
+
+// GenerateStream envia o prompt com stream habilitado e chama onToken para cada
+// trecho recebido. Retorna a resposta completa. Cancelar ctx interrompe a requisição.
+func GenerateStream(ctx context.Context, prompt string, config *Config, onToken TokenFunc) (string, error) {
+	config = effectiveConfig(config)
+
+	body := Request{
+		Model:  config.Model,
+		Prompt: prompt,
+		Stream: true,
+	}
+
+	resp, cancel, err := send(ctx, config.APIURL, body, config)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	// O Ollama envia um objeto JSON por linha até "done": true
+	var full strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk Response
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return full.String(), fmt.Errorf("erro ao decodificar resposta da API: %w", err)
+		}
+
+		if chunk.Response != "" {
+			full.WriteString(chunk.Response)
+			if onToken != nil {
+				if err := onToken(chunk.Response); err != nil {
+					return full.String(), err
+				}
+			}
+		}
+		if chunk.Done {
+			reportUsage(config, chunk.Metrics)
+			return full.String(), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		// Prefere o erro do contexto (timeout ou cancelamento) ao erro de leitura
+		if ctxErr := resp.Request.Context().Err(); ctxErr != nil {
+			return full.String(), connectionError(ctxErr)
+		}
+		return full.String(), fmt.Errorf("erro ao ler resposta da API: %w", err)
+	}
+
+	return full.String(), fmt.Errorf("resposta da API terminou antes de done")
+}
@@ -0,0 +1,121 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Errorf("Expected stream to be enabled")
+		}
+
+		enc := json.NewEncoder(w)
+		for _, token := range []string{"Este ", "código ", "imprime."} {
+			enc.Encode(Response{Response: token})
+			w.(http.Flusher).Flush()
+		}
+		enc.Encode(Response{Done: true, Metrics: Metrics{PromptEvalCount: 12, EvalCount: 3, EvalDuration: int64(time.Second)}})
+	}))
+	defer server.Close()
+
+	var tokens []string
+	var usage Usage
+	config := &Config{APIURL: server.URL, Model: "codellama", Timeout: 5 * time.Second, OnUsage: func(u Usage) { usage = u }}
+	result, err := ExplainCodeStream(context.Background(), `print("oi")`, config, func(token string) error {
+		tokens = append(tokens, token)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExplainCodeStream() error = %v", err)
+	}
+	if result != "Este código imprime." || len(tokens) != 3 {
+		t.Errorf("Unexpected result %q with tokens %v", result, tokens)
+	}
+	if usage.PromptTokens != 12 || usage.CompletionTokens != 3 || usage.TokensPerSecond != 3 {
+		t.Errorf("OnUsage recebeu %+v", usage)
+	}
+}
+
+func TestGenerateStreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		onToken TokenFunc
+	}{
+		{
+			name: "Status HTTP de erro",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "model not found", http.StatusNotFound)
+			},
+		},
+		{
+			name: "Fim sem done",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(Response{Response: "parcial"})
+			},
+		},
+		{
+			name: "Linha inválida",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("{quebrado\n"))
+			},
+		},
+		{
+			name: "Callback interrompe",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(Response{Response: "a"})
+				json.NewEncoder(w).Encode(Response{Done: true})
+			},
+			onToken: func(string) error { return errors.New("cliente desconectou") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			config := &Config{APIURL: server.URL, Model: "codellama", Timeout: 5 * time.Second}
+			if _, err := GenerateStream(context.Background(), "prompt", config, tt.onToken); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestGenerateStreamCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Response{Response: "início "})
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	config := &Config{APIURL: server.URL, Model: "codellama", Timeout: 5 * time.Second}
+
+	start := time.Now()
+	result, err := GenerateStream(ctx, "prompt", config, func(token string) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if !strings.HasPrefix(result, "início") {
+		t.Errorf("Expected partial result, got %q", result)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("Cancellation took too long")
+	}
+}
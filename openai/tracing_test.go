@@ -0,0 +1,57 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestExplainTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`{"response":"ok","done":true}`))
+	}))
+	defer server.Close()
+
+	config := &Config{APIURL: server.URL + "/api/generate", Model: "codellama", Timeout: 5 * time.Second}
+	if _, err := ExplainCodeContext(context.Background(), "package main\n\nfunc main() {}", config); err != nil {
+		t.Fatalf("ExplainCodeContext() erro = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	names := map[string]bool{}
+	for _, s := range spans {
+		names[s.Name] = true
+	}
+	for _, want := range []string{"detect_language", "build_prompt", "POST /api/generate"} {
+		if !names[want] {
+			t.Errorf("span %q não registrado; spans = %v", want, names)
+		}
+	}
+
+	if traceparent == "" {
+		t.Fatal("requisição sem cabeçalho traceparent")
+	}
+	for _, s := range spans {
+		if s.Name == "POST /api/generate" {
+			if want := s.SpanContext.TraceID().String(); traceparent[3:35] != want {
+				t.Errorf("traceparent = %s, want trace ID %s", traceparent, want)
+			}
+		}
+	}
+}
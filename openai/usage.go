@@ -0,0 +1,87 @@
+package openai
+
+import "time"
+
+// Usage reúne as métricas de uma geração informadas pelo servidor
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalMs          int64   `json:"total_ms,omitempty"`       // Tempo total no servidor
+	LoadMs           int64   `json:"load_ms,omitempty"`        // Carregamento do modelo
+	PromptEvalMs     int64   `json:"prompt_eval_ms,omitempty"` // Processamento do prompt
+	EvalMs           int64   `json:"eval_ms,omitempty"`        // Geração da resposta
+	TokensPerSecond  float64 `json:"tokens_per_second,omitempty"`
+}
+
+// UsageFunc recebe as métricas de uma resposta concluída
+type UsageFunc func(Usage)
+
+// Metrics são os campos de desempenho da última resposta: os do Ollama (durações em
+// nanossegundos) e o objeto usage dos servidores compatíveis com a OpenAI
+type Metrics struct {
+	TotalDuration      int64        `json:"total_duration,omitempty"`
+	LoadDuration       int64        `json:"load_duration,omitempty"`
+	PromptEvalCount    int          `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64        `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int          `json:"eval_count,omitempty"`
+	EvalDuration       int64        `json:"eval_duration,omitempty"`
+	OpenAIUsage        *OpenAIUsage `json:"usage,omitempty"`
+}
+
+// OpenAIUsage é o objeto usage das respostas no formato da OpenAI
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Usage converte as métricas recebidas; ok é false se o servidor não enviou nenhuma
+func (m Metrics) Usage() (u Usage, ok bool) {
+	switch {
+	case m.EvalCount > 0 || m.PromptEvalCount > 0 || m.TotalDuration > 0:
+		u = Usage{
+			PromptTokens:     m.PromptEvalCount,
+			CompletionTokens: m.EvalCount,
+			TotalMs:          time.Duration(m.TotalDuration).Milliseconds(),
+			LoadMs:           time.Duration(m.LoadDuration).Milliseconds(),
+			PromptEvalMs:     time.Duration(m.PromptEvalDuration).Milliseconds(),
+			EvalMs:           time.Duration(m.EvalDuration).Milliseconds(),
+		}
+		if m.EvalDuration > 0 {
+			u.TokensPerSecond = float64(m.EvalCount) / time.Duration(m.EvalDuration).Seconds()
+		}
+	case m.OpenAIUsage != nil:
+		u = Usage{
+			PromptTokens:     m.OpenAIUsage.PromptTokens,
+			CompletionTokens: m.OpenAIUsage.CompletionTokens,
+		}
+	default:
+		return Usage{}, false
+	}
+	return u, true
+}
+
+// TotalTokens retorna a soma dos tokens de entrada e gerados
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// CaptureUsage retorna um UsageFunc que guarda as métricas em dst e as repassa a next
+func CaptureUsage(next UsageFunc, dst **Usage) UsageFunc {
+	return func(u Usage) {
+		*dst = &u
+		if next != nil {
+			next(u)
+		}
+	}
+}
+
+// reportUsage entrega as métricas de m a config.OnUsage, se houver
+func reportUsage(config *Config, m Metrics) {
+	if config.OnUsage == nil {
+		return
+	}
+	if u, ok := m.Usage(); ok {
+		config.OnUsage(u)
+	}
+}
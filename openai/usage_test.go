@@ -0,0 +1,70 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetricsUsage(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  Metrics
+		expected Usage
+		ok       bool
+	}{
+		{name: "Sem métricas", metrics: Metrics{}, ok: false},
+		{
+			name: "Ollama",
+			metrics: Metrics{
+				TotalDuration:      int64(3 * time.Second),
+				LoadDuration:       int64(500 * time.Millisecond),
+				PromptEvalCount:    40,
+				PromptEvalDuration: int64(250 * time.Millisecond),
+				EvalCount:          100,
+				EvalDuration:       int64(2 * time.Second),
+			},
+			expected: Usage{PromptTokens: 40, CompletionTokens: 100, TotalMs: 3000, LoadMs: 500, PromptEvalMs: 250, EvalMs: 2000, TokensPerSecond: 50},
+			ok:       true,
+		},
+		{
+			name:     "OpenAI",
+			metrics:  Metrics{OpenAIUsage: &OpenAIUsage{PromptTokens: 30, CompletionTokens: 70, TotalTokens: 100}},
+			expected: Usage{PromptTokens: 30, CompletionTokens: 70},
+			ok:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.metrics.Usage()
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("Usage() = %+v, %v, want %+v, %v", got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGenerateOnUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response":"ok","done":true,"prompt_eval_count":8,"eval_count":2,"eval_duration":500000000,"total_duration":900000000}`))
+	}))
+	defer server.Close()
+
+	var calls []Usage
+	config := &Config{APIURL: server.URL, Model: "codellama", Timeout: 5 * time.Second, OnUsage: func(u Usage) {
+		calls = append(calls, u)
+	}}
+
+	if _, err := Generate(context.Background(), "x", config); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("OnUsage chamada %d vezes, want 1", len(calls))
+	}
+	if u := calls[0]; u.TotalTokens() != 10 || u.TotalMs != 900 || u.TokensPerSecond != 4 {
+		t.Errorf("OnUsage recebeu %+v", u)
+	}
+}
@@ -0,0 +1,230 @@
+// Package policy restringe os servidores para onde o código pode ser enviado.
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBlocked é retornado (via BlockedError) quando a política recusa o destino
+var ErrBlocked = errors.New("destino bloqueado pela política")
+
+// Policy define os destinos permitidos. A política só é aplicada se estiver
+// ativa: AllowRemote explicitamente falso ou uma lista de hosts permitidos.
+type Policy struct {
+	AllowRemote  *bool
+	AllowedHosts []string // "host", "host:porta" ou "*.dominio"
+	Source       string   // Origem da política, exibida nos erros (ex.: .code-explainer.yaml)
+
+	also []Policy // Políticas combinadas por Merge, que também precisam permitir o destino
+}
+
+// Merge combina as políticas no sentido mais restritivo: um destino só é permitido
+// se todas as políticas ativas o permitirem. Políticas inativas são ignoradas.
+func Merge(policies ...Policy) Policy {
+	var merged Policy
+	for _, p := range policies {
+		switch {
+		case !p.Active():
+			continue
+		case !merged.Active():
+			merged = p
+		default:
+			merged.also = append(append([]Policy(nil), merged.also...), p)
+		}
+	}
+	return merged
+}
+
+// BlockedError descreve um envio recusado
+type BlockedError struct {
+	URL    string
+	Host   string
+	Source string
+}
+
+func (e *BlockedError) Error() string {
+	msg := fmt.Sprintf("envio para %s recusado: a política permite apenas servidores locais", e.Host)
+	if e.Source != "" {
+		msg += " ou listados em policy.allowed_hosts (" + e.Source + ")"
+	}
+	return msg
+}
+
+// Unwrap permite usar errors.Is(err, ErrBlocked)
+func (e *BlockedError) Unwrap() error {
+	return ErrBlocked
+}
+
+// Active informa se a política restringe algum destino
+func (p Policy) Active() bool {
+	return (p.AllowRemote != nil && !*p.AllowRemote) || len(p.AllowedHosts) > 0
+}
+
+// Check verifica se rawURL é um destino permitido
+func (p Policy) Check(rawURL string) error {
+	if !p.Active() {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return &BlockedError{URL: rawURL, Host: rawURL, Source: p.Source}
+	}
+
+	if !IsLoopback(u.Hostname()) && !p.allowed(u) {
+		return &BlockedError{URL: Sanitize(rawURL), Host: u.Host, Source: p.Source}
+	}
+	for _, other := range p.also {
+		if err := other.Check(rawURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allowed verifica a lista de hosts permitidos
+func (p Policy) allowed(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	hostPort := strings.ToLower(u.Host)
+
+	for _, entry := range p.AllowedHosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == host, entry == hostPort:
+			return true
+		case strings.HasPrefix(entry, "*.") && strings.HasSuffix(host, entry[1:]):
+			return true
+		}
+	}
+	return false
+}
+
+// IsLoopback informa se o host é a máquina local (localhost, 127.0.0.0/8 ou ::1).
+// Nomes não são resolvidos via DNS.
+func IsLoopback(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Sanitize remove credenciais e parâmetros da URL antes de registrá-la
+func Sanitize(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
+}
+
+// Event é uma linha do log de auditoria
+type Event struct {
+	Time     time.Time `json:"time"`
+	Decision string    `json:"decision"` // "blocked" ou "allowed"
+	URL      string    `json:"url"`
+	Host     string    `json:"host"`
+	Command  string    `json:"command,omitempty"`
+	User     string    `json:"user,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// AuditLog grava eventos em um arquivo JSONL
+type AuditLog struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// DefaultAuditPath retorna $XDG_STATE_HOME/code-explainer/audit.log (ou ~/.local/state)
+func DefaultAuditPath() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("não foi possível determinar o diretório de estado: %w", err)
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "code-explainer", "audit.log"), nil
+}
+
+// Log acrescenta o evento ao arquivo
+func (a *AuditLog) Log(e Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(a.Path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(a.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Guard retorna uma função que verifica cada URL contra a política e registra no log
+// de auditoria os envios recusados e os envios permitidos a hosts remotos.
+// command identifica o comando nos eventos; audit nil desativa o log.
+func Guard(p Policy, audit *AuditLog, command string) func(rawURL string) error {
+	return func(rawURL string) error {
+		err := p.Check(rawURL)
+		if audit == nil || !p.Active() {
+			return err
+		}
+
+		e := Event{URL: Sanitize(rawURL), Command: command, User: os.Getenv("USER")}
+		if u, parseErr := url.Parse(rawURL); parseErr == nil {
+			e.Host = u.Host
+		}
+
+		var blocked *BlockedError
+		switch {
+		case errors.As(err, &blocked):
+			e.Decision = "blocked"
+			e.Reason = blocked.Error()
+		case !IsLoopback(hostname(rawURL)):
+			e.Decision = "allowed"
+			e.Reason = "host permitido por policy.allowed_hosts"
+		default:
+			return err
+		}
+
+		if logErr := audit.Log(e); logErr != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Não foi possível gravar o log de auditoria: %v\n", logErr)
+		}
+		return err
+	}
+}
+
+// hostname extrai o host da URL, ou "" se ela for inválida
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
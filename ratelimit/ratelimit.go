@@ -0,0 +1,168 @@
+// Package ratelimit limita a taxa de requisições por cliente (token bucket) e o
+// número de requisições simultâneas, com uma fila de espera de tamanho fixo.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull indica que todas as vagas e a fila de espera estão ocupadas
+var ErrQueueFull = errors.New("servidor ocupado: fila de requisições cheia")
+
+// sweepInterval é o intervalo mínimo entre as limpezas de clientes inativos
+const sweepInterval = time.Minute
+
+// Limiter aplica um token bucket independente para cada chave (cliente)
+type Limiter struct {
+	rate  float64 // Fichas repostas por segundo
+	burst float64 // Capacidade do balde
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter cria um limitador de perMinute (positivo) requisições por minuto
+// por chave, permitindo rajadas de até burst requisições (mínimo 1)
+func NewLimiter(perMinute float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:    perMinute / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow consome uma ficha do balde de key. Se não houver ficha, retorna false
+// e o tempo até a próxima ficha ficar disponível.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// Peek informa se há ficha no balde de key, sem consumi-la. Se não houver,
+// retorna false e o tempo até a próxima ficha ficar disponível.
+func (l *Limiter) Peek(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.buckets[key]
+	if b == nil {
+		return true, 0
+	}
+	tokens := math.Min(l.burst, b.tokens+l.now().Sub(b.last).Seconds()*l.rate)
+	if tokens >= 1 {
+		return true, 0
+	}
+	return false, time.Duration((1 - tokens) / l.rate * float64(time.Second))
+}
+
+// sweep descarta os baldes que já estariam cheios, equivalentes a um cliente novo
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Gate limita as requisições simultâneas; as excedentes aguardam em uma fila
+// de até maxQueue posições
+type Gate struct {
+	slots    chan struct{}
+	maxQueue int
+
+	mu     sync.Mutex
+	queued int
+}
+
+// NewGate cria um Gate com maxInFlight vagas e fila de maxQueue posições
+func NewGate(maxInFlight, maxQueue int) *Gate {
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	return &Gate{slots: make(chan struct{}, maxInFlight), maxQueue: maxQueue}
+}
+
+// Acquire ocupa uma vaga, aguardando na fila se necessário. Retorna ErrQueueFull
+// se a fila estiver cheia, ou o erro de ctx se ele for cancelado durante a espera.
+// A função retornada libera a vaga.
+func (g *Gate) Acquire(ctx context.Context) (func(), error) {
+	release := func() { <-g.slots }
+
+	select {
+	case g.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	g.mu.Lock()
+	if g.queued >= g.maxQueue {
+		g.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	g.queued++
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		g.queued--
+		g.mu.Unlock()
+	}()
+
+	select {
+	case g.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// InFlight retorna o número de vagas ocupadas
+func (g *Gate) InFlight() int {
+	return len(g.slots)
+}
+
+// Queued retorna o número de requisições aguardando na fila
+func (g *Gate) Queued() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.queued
+}
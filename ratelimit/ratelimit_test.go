@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter(60, 2) // 1 por segundo, rajada de 2
+	l.now = func() time.Time { return now }
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		key     string
+		allowed bool
+		wait    time.Duration
+	}{
+		{name: "Primeira da rajada", key: "a", allowed: true},
+		{name: "Segunda da rajada", key: "a", allowed: true},
+		{name: "Balde vazio", key: "a", allowed: false, wait: time.Second},
+		{name: "Outro cliente", key: "b", allowed: true},
+		{name: "Meia ficha", advance: 500 * time.Millisecond, key: "a", allowed: false, wait: 500 * time.Millisecond},
+		{name: "Ficha reposta", advance: 500 * time.Millisecond, key: "a", allowed: true},
+		{name: "Reposição limitada à rajada", advance: time.Hour, key: "a", allowed: true},
+		{name: "Segunda após a pausa", key: "a", allowed: true},
+		{name: "Terceira após a pausa", key: "a", allowed: false, wait: time.Second},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			now = now.Add(step.advance)
+			allowed, wait := l.Allow(step.key)
+			if allowed != step.allowed || wait != step.wait {
+				t.Errorf("Allow(%q) = %v, %v, want %v, %v", step.key, allowed, wait, step.allowed, step.wait)
+			}
+		})
+	}
+}
+
+func TestLimiterPeek(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter(60, 1)
+	l.now = func() time.Time { return now }
+
+	if ok, _ := l.Peek("a"); !ok {
+		t.Fatal("Peek() em um balde novo = false")
+	}
+	if ok, _ := l.Peek("a"); !ok {
+		t.Fatal("Peek() consumiu a ficha")
+	}
+	l.Allow("a")
+	if ok, wait := l.Peek("a"); ok || wait != time.Second {
+		t.Errorf("Peek() com o balde vazio = %v, %v, want false, 1s", ok, wait)
+	}
+	now = now.Add(time.Second)
+	if ok, _ := l.Peek("a"); !ok {
+		t.Error("Peek() após a reposição = false")
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter(60, 5)
+	l.now = func() time.Time { return now }
+
+	l.Allow("a")
+	now = now.Add(2 * time.Minute)
+	l.Allow("b")
+
+	if _, ok := l.buckets["a"]; ok || len(l.buckets) != 1 {
+		t.Errorf("buckets = %v, want apenas b", l.buckets)
+	}
+}
+
+func TestGate(t *testing.T) {
+	g := NewGate(1, 1)
+	ctx := context.Background()
+
+	release, err := g.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("primeira vaga: %v", err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		r, err := g.Acquire(ctx)
+		if err != nil {
+			t.Errorf("requisição na fila: %v", err)
+		}
+		acquired <- r
+	}()
+
+	waitFor(t, func() bool { return g.Queued() == 1 })
+	if _, err := g.Acquire(ctx); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("fila cheia: erro = %v, want ErrQueueFull", err)
+	}
+
+	release()
+	second := <-acquired
+	if g.InFlight() != 1 || g.Queued() != 0 {
+		t.Errorf("InFlight() = %d, Queued() = %d, want 1, 0", g.InFlight(), g.Queued())
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := g.Acquire(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("contexto cancelado: erro = %v", err)
+	}
+	if g.Queued() != 0 {
+		t.Errorf("Queued() = %d após cancelamento, want 0", g.Queued())
+	}
+
+	second()
+	if g.InFlight() != 0 {
+		t.Errorf("InFlight() = %d, want 0", g.InFlight())
+	}
+}
+
+// waitFor aguarda cond ser verdadeira por até um segundo
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condição não atingida")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,153 @@
+// Package redact oculta segredos e dados pessoais do código antes de enviá-lo ao modelo.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rule identifica um tipo de dado sensível. Se Group for maior que zero, apenas
+// esse grupo da expressão é substituído (ex.: a senha de uma connection string).
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Group   int
+}
+
+// DefaultRules são as regras aplicadas por padrão, em ordem
+var DefaultRules = []Rule{
+	{Name: "private_key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{Name: "aws_access_key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "aws_secret_key", Pattern: regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`), Group: 1},
+	{Name: "github_token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{Name: "bearer_token", Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{16,})`), Group: 1},
+	{Name: "connection_string", Pattern: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@'"]+:([^\s@'"/]+)@`), Group: 1},
+	{Name: "secret", Pattern: regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret|token|password|passwd)\b["']?\s*[:=]\s*["']([^"'\s]{8,})["']`), Group: 1},
+	{Name: "email", Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+}
+
+// Redactor aplica um conjunto de regras
+type Redactor struct {
+	rules []Rule
+}
+
+// New cria um Redactor com as regras padrão seguidas das regras informadas
+func New(extra ...Rule) *Redactor {
+	rules := append([]Rule{}, DefaultRules...)
+	return &Redactor{rules: append(rules, extra...)}
+}
+
+// NewRule compila uma regra personalizada; o valor inteiro da correspondência é substituído
+func NewRule(name, expr string) (Rule, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return Rule{}, fmt.Errorf("expressão inválida para %s: %w", name, err)
+	}
+	return Rule{Name: name, Pattern: re}, nil
+}
+
+// Result é o texto com os dados ocultados e o mapeamento para restaurá-los
+type Result struct {
+	Text string
+
+	originals map[string]string // placeholder -> valor original
+	counts    map[string]int    // regra -> quantidade de valores distintos
+}
+
+// Redact substitui cada valor sensível por um placeholder como <REDACTED_EMAIL_1>.
+// Valores repetidos recebem o mesmo placeholder.
+func (r *Redactor) Redact(text string) *Result {
+	return r.Extend(nil, text)
+}
+
+// Extend oculta text continuando a partir de prev, como nas mensagens seguintes de
+// uma conversa: valores já vistos mantêm o placeholder e os novos continuam a
+// numeração. O resultado restaura tanto os valores de prev quanto os novos.
+func (r *Redactor) Extend(prev *Result, text string) *Result {
+	res := &Result{originals: map[string]string{}, counts: map[string]int{}}
+	placeholders := map[string]string{} // valor original -> placeholder
+	if prev != nil {
+		for placeholder, value := range prev.originals {
+			res.originals[placeholder] = value
+			placeholders[value] = placeholder
+		}
+		for name, n := range prev.counts {
+			res.counts[name] = n
+		}
+	}
+
+	for _, rule := range r.rules {
+		matches := rule.Pattern.FindAllStringSubmatchIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			start, end := m[0], m[1]
+			if rule.Group > 0 && len(m) > 2*rule.Group+1 && m[2*rule.Group] >= 0 {
+				start, end = m[2*rule.Group], m[2*rule.Group+1]
+			}
+
+			value := text[start:end]
+			placeholder, ok := placeholders[value]
+			if !ok {
+				res.counts[rule.Name]++
+				placeholder = fmt.Sprintf("<REDACTED_%s_%d>", strings.ToUpper(rule.Name), res.counts[rule.Name])
+				placeholders[value] = placeholder
+				res.originals[placeholder] = value
+			}
+
+			b.WriteString(text[last:start])
+			b.WriteString(placeholder)
+			last = end
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+
+	res.Text = text
+	return res
+}
+
+// Redacted informa se algum valor foi ocultado
+func (res *Result) Redacted() bool {
+	return len(res.originals) > 0
+}
+
+// Counts retorna a quantidade de valores distintos ocultados por regra
+func (res *Result) Counts() map[string]int {
+	return res.counts
+}
+
+// Summary descreve o que foi ocultado, ex.: "1 aws_access_key, 2 email"
+func (res *Result) Summary() string {
+	names := make([]string, 0, len(res.counts))
+	for name := range res.counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%d %s", res.counts[name], name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Restore devolve os valores originais no lugar dos placeholders presentes em text
+func (res *Result) Restore(text string) string {
+	if !res.Redacted() {
+		return text
+	}
+
+	pairs := make([]string, 0, 2*len(res.originals))
+	for placeholder, value := range res.originals {
+		pairs = append(pairs, placeholder, value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
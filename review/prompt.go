@@ -0,0 +1,58 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mvcbotelho/code-explainer/gitdiff"
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// responseFormat descreve o formato de resposta esperado do modelo
+const responseFormat = `Responda APENAS com um array JSON, sem texto adicional. Cada item deve ter os campos:
+  "file": caminho do arquivo
+  "line": número da linha (no arquivo novo, para diffs)
+  "severity": um de "info", "low", "medium", "high", "critical"
+  "category": por exemplo "bug", "security", "performance", "style", "maintainability"
+  "message": descrição objetiva do problema
+  "suggestion": como corrigir
+Se não houver problemas, responda com [].`
+
+// BuildPrompt monta o prompt de revisão de um arquivo completo.
+// As linhas são numeradas para que o modelo possa referenciá-las.
+func BuildPrompt(code, file, lang, level string) string {
+	var b strings.Builder
+
+	if instructions := openai.LevelInstructions(level); instructions != "" {
+		b.WriteString(instructions + "\n\n")
+	}
+
+	fmt.Fprintf(&b, "Revise o seguinte código em %s do arquivo %s. Aponte bugs, problemas de segurança, desempenho e manutenibilidade.\n\n", lang, file)
+	b.WriteString(responseFormat)
+	b.WriteString("\n\nCódigo (com números de linha):\n")
+
+	for i, line := range strings.Split(code, "\n") {
+		fmt.Fprintf(&b, "%4d | %s\n", i+1, line)
+	}
+
+	return b.String()
+}
+
+// BuildDiffPrompt monta o prompt de revisão das alterações de um arquivo
+func BuildDiffPrompt(f gitdiff.File, level string) string {
+	var b strings.Builder
+
+	if instructions := openai.LevelInstructions(level); instructions != "" {
+		b.WriteString(instructions + "\n\n")
+	}
+
+	fmt.Fprintf(&b, "Revise as alterações do arquivo %s (%s). Avalie apenas as linhas adicionadas ou modificadas, apontando bugs, problemas de segurança, desempenho e manutenibilidade.\n\n", f.Path(), f.Language())
+	b.WriteString(responseFormat)
+	b.WriteString("\n\nDiff:\n")
+
+	for _, h := range f.Hunks {
+		fmt.Fprintf(&b, "```diff\n%s```\n", h.Text())
+	}
+
+	return b.String()
+}
@@ -0,0 +1,214 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formatos de saída suportados
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// sarifSchema é o schema da versão de SARIF gerada
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// severityIcons são os ícones usados na saída em texto
+var severityIcons = map[string]string{
+	SeverityInfo:     "💡",
+	SeverityLow:      "🔵",
+	SeverityMedium:   "🟡",
+	SeverityHigh:     "🟠",
+	SeverityCritical: "🔴",
+}
+
+// ValidateFormat verifica se o formato de saída é suportado
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON, FormatSARIF, "":
+		return nil
+	default:
+		return fmt.Errorf("formato inválido: %s (use %s, %s ou %s)", format, FormatText, FormatJSON, FormatSARIF)
+	}
+}
+
+// Render escreve os achados no formato informado
+func Render(w io.Writer, findings []Finding, format, version string) error {
+	switch format {
+	case FormatJSON:
+		return RenderJSON(w, findings)
+	case FormatSARIF:
+		return RenderSARIF(w, findings, version)
+	case FormatText, "":
+		return RenderText(w, findings)
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+// RenderText escreve os achados em texto legível, agrupados por arquivo
+func RenderText(w io.Writer, findings []Finding) error {
+	var b strings.Builder
+
+	b.WriteString("🔎 Revisão de Código\n")
+	b.WriteString(strings.Repeat("=", 30) + "\n\n")
+
+	if len(findings) == 0 {
+		b.WriteString("✅ Nenhum problema encontrado.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	currentFile := ""
+	for _, f := range findings {
+		if f.File != currentFile {
+			currentFile = f.File
+			b.WriteString(fmt.Sprintf("📄 **%s**\n", f.File))
+		}
+
+		location := "-"
+		if f.Line > 0 {
+			location = fmt.Sprintf("linha %d", f.Line)
+		}
+		b.WriteString(fmt.Sprintf("  %s [%s] %s (%s): %s\n", severityIcons[f.Severity], f.Severity, location, f.Category, f.Message))
+		if f.Suggestion != "" {
+			b.WriteString(fmt.Sprintf("     💬 Sugestão: %s\n", f.Suggestion))
+		}
+	}
+
+	b.WriteString(fmt.Sprintf("\n📊 %d achado(s), maior severidade: %s\n", len(findings), MaxSeverity(findings)))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderJSON escreve os achados como um array JSON
+func RenderJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
+
+// Estruturas mínimas do formato SARIF 2.1.0
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifLevel converte a severidade para os níveis do SARIF
+func sarifLevel(severity string) string {
+	switch severity {
+	case SeverityCritical, SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// RenderSARIF escreve os achados no formato SARIF 2.1.0, aceito por painéis de code scanning
+func RenderSARIF(w io.Writer, findings []Finding, version string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "code-explainer",
+			Version:        version,
+			InformationURI: "https://github.com/mvcbotelho/code-explainer",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	seenRules := map[string]bool{}
+	for _, f := range findings {
+		ruleID := "code-explainer/" + f.Category
+		if !seenRules[ruleID] {
+			seenRules[ruleID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               ruleID,
+				ShortDescription: sarifMessage{Text: "Achados da categoria " + f.Category},
+			})
+		}
+
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: f.File}}
+		if f.Line > 0 {
+			location.Region = &sarifRegion{StartLine: f.Line}
+		}
+
+		message := f.Message
+		if f.Suggestion != "" {
+			message += "\nSugestão: " + f.Suggestion
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:     ruleID,
+			Level:      sarifLevel(f.Severity),
+			Message:    sarifMessage{Text: message},
+			Locations:  []sarifLocation{{PhysicalLocation: location}},
+			Properties: map[string]string{"severity": f.Severity},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}})
+}
@@ -0,0 +1,220 @@
+// Package review monta prompts de revisão de código e converte a resposta do
+// modelo em achados estruturados, renderizáveis como texto, JSON ou SARIF.
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severidades suportadas, da menor para a maior
+const (
+	SeverityInfo     = "info"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// SeverityNone desativa a falha por severidade em ExitCode
+const SeverityNone = "none"
+
+// ExitCodeBase é somado ao nível da maior severidade para formar o código de saída
+// (info=20, low=21, medium=22, high=23, critical=24)
+const ExitCodeBase = 20
+
+var severities = []string{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// severityAliases normaliza as variações que os modelos costumam usar
+var severityAliases = map[string]string{
+	"note":        SeverityInfo,
+	"informativo": SeverityInfo,
+	"informação":  SeverityInfo,
+	"minor":       SeverityLow,
+	"baixa":       SeverityLow,
+	"baixo":       SeverityLow,
+	"warning":     SeverityMedium,
+	"moderate":    SeverityMedium,
+	"média":       SeverityMedium,
+	"media":       SeverityMedium,
+	"médio":       SeverityMedium,
+	"major":       SeverityHigh,
+	"error":       SeverityHigh,
+	"alta":        SeverityHigh,
+	"alto":        SeverityHigh,
+	"blocker":     SeverityCritical,
+	"crítica":     SeverityCritical,
+	"critica":     SeverityCritical,
+	"crítico":     SeverityCritical,
+}
+
+// Finding representa um problema apontado na revisão
+type Finding struct {
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	Severity   string `json:"severity"`
+	Category   string `json:"category"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// GetSeverities retorna as severidades suportadas, da menor para a maior
+func GetSeverities() []string {
+	return append([]string(nil), severities...)
+}
+
+// SeverityRank retorna a posição da severidade (0 = info) ou -1 se for desconhecida
+func SeverityRank(severity string) int {
+	for i, s := range severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// NormalizeSeverity converte variações (ex.: "warning", "alta") para uma severidade
+// suportada. Valores desconhecidos viram "medium".
+func NormalizeSeverity(severity string) string {
+	s := strings.ToLower(strings.TrimSpace(severity))
+	if SeverityRank(s) >= 0 {
+		return s
+	}
+	if alias, ok := severityAliases[s]; ok {
+		return alias
+	}
+	return SeverityMedium
+}
+
+// ValidateFailOn verifica o valor de --fail-on
+func ValidateFailOn(failOn string) error {
+	if failOn == SeverityNone || SeverityRank(failOn) >= 0 {
+		return nil
+	}
+	return fmt.Errorf("severidade inválida: %s (use %s ou %s)", failOn, strings.Join(severities, ", "), SeverityNone)
+}
+
+// MaxSeverity retorna a maior severidade entre os achados, ou string vazia se não houver achados
+func MaxSeverity(findings []Finding) string {
+	max := -1
+	for _, f := range findings {
+		if rank := SeverityRank(f.Severity); rank > max {
+			max = rank
+		}
+	}
+	if max < 0 {
+		return ""
+	}
+	return severities[max]
+}
+
+// ExitCode retorna o código de saída para os achados: 0 se nenhum atinge failOn,
+// ou ExitCodeBase mais o nível da maior severidade encontrada
+func ExitCode(findings []Finding, failOn string) int {
+	threshold := SeverityRank(failOn)
+	if failOn == SeverityNone || threshold < 0 {
+		return 0
+	}
+
+	max := SeverityRank(MaxSeverity(findings))
+	if max < threshold {
+		return 0
+	}
+	return ExitCodeBase + max
+}
+
+// Sort ordena os achados por arquivo, linha e severidade (maior primeiro)
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return SeverityRank(a.Severity) > SeverityRank(b.Severity)
+	})
+}
+
+// fencedJSON encontra blocos ```json ... ``` na resposta do modelo
+var fencedJSON = regexp.MustCompile("(?s)```(?:json)?\\s*(.*?)```")
+
+// rawFinding aceita tanto números quanto strings no campo line
+type rawFinding struct {
+	File       string          `json:"file"`
+	Line       json.RawMessage `json:"line"`
+	Severity   string          `json:"severity"`
+	Category   string          `json:"category"`
+	Message    string          `json:"message"`
+	Suggestion string          `json:"suggestion"`
+}
+
+// ParseFindings extrai os achados da resposta do modelo. A resposta deve conter
+// um array JSON, opcionalmente dentro de um bloco de código. Achados sem arquivo
+// recebem defaultFile.
+func ParseFindings(answer, defaultFile string) ([]Finding, error) {
+	candidates := []string{}
+	for _, m := range fencedJSON.FindAllStringSubmatch(answer, -1) {
+		candidates = append(candidates, m[1])
+	}
+	if start, end := strings.Index(answer, "["), strings.LastIndex(answer, "]"); start != -1 && end > start {
+		candidates = append(candidates, answer[start:end+1])
+	}
+
+	for _, candidate := range candidates {
+		var raw []rawFinding
+		if err := json.Unmarshal([]byte(strings.TrimSpace(candidate)), &raw); err != nil {
+			continue
+		}
+		return normalize(raw, defaultFile), nil
+	}
+
+	return nil, fmt.Errorf("resposta do modelo não contém um array JSON de achados")
+}
+
+// normalize converte os achados brutos, descartando os que não têm mensagem
+func normalize(raw []rawFinding, defaultFile string) []Finding {
+	findings := make([]Finding, 0, len(raw))
+
+	for _, r := range raw {
+		if strings.TrimSpace(r.Message) == "" {
+			continue
+		}
+
+		f := Finding{
+			File:       r.File,
+			Line:       parseLine(r.Line),
+			Severity:   NormalizeSeverity(r.Severity),
+			Category:   strings.ToLower(strings.TrimSpace(r.Category)),
+			Message:    strings.TrimSpace(r.Message),
+			Suggestion: strings.TrimSpace(r.Suggestion),
+		}
+		if f.File == "" {
+			f.File = defaultFile
+		}
+		if f.Category == "" {
+			f.Category = "geral"
+		}
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+// parseLine aceita 12, "12" ou "12-15" (usa a primeira linha)
+func parseLine(raw json.RawMessage) int {
+	var n int
+	if json.Unmarshal(raw, &n) == nil {
+		return n
+	}
+
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		fmt.Sscanf(strings.TrimSpace(s), "%d", &n)
+	}
+	return n
+}
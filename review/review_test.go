@@ -0,0 +1,171 @@
+package review
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseFindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected int
+		wantErr  bool
+	}{
+		{
+			name:     "Array puro",
+			answer:   `[{"file": "a.go", "line": 3, "severity": "high", "category": "bug", "message": "nil pointer"}]`,
+			expected: 1,
+		},
+		{
+			name: "Bloco de código com texto ao redor",
+			answer: "Aqui está a revisão:\n```json\n[\n" +
+				`{"line": "10", "severity": "warning", "category": "Style", "message": "nome ruim", "suggestion": "renomeie"},` + "\n" +
+				`{"line": 12, "severity": "crítica", "category": "security", "message": "SQL injection"}` +
+				"\n]\n```\nEspero ter ajudado.",
+			expected: 2,
+		},
+		{
+			name:     "Sem problemas",
+			answer:   "[]",
+			expected: 0,
+		},
+		{
+			name:     "Achado sem mensagem é descartado",
+			answer:   `[{"severity": "low", "message": ""}]`,
+			expected: 0,
+		},
+		{
+			name:    "Resposta sem JSON",
+			answer:  "O código parece bom.",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := ParseFindings(tt.answer, "padrao.go")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFindings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(findings) != tt.expected {
+				t.Errorf("Expected %d findings, got %d", tt.expected, len(findings))
+			}
+		})
+	}
+
+	findings, _ := ParseFindings(tests[1].answer, "padrao.go")
+	first := findings[0]
+	if first.File != "padrao.go" || first.Line != 10 || first.Severity != SeverityMedium || first.Category != "style" {
+		t.Errorf("Unexpected normalized finding: %+v", first)
+	}
+	if findings[1].Severity != SeverityCritical {
+		t.Errorf("Expected 'crítica' to be normalized to critical, got %s", findings[1].Severity)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	findings := []Finding{
+		{Severity: SeverityLow},
+		{Severity: SeverityHigh},
+		{Severity: SeverityMedium},
+	}
+
+	tests := []struct {
+		name     string
+		findings []Finding
+		failOn   string
+		expected int
+	}{
+		{name: "Acima do limite", findings: findings, failOn: SeverityMedium, expected: ExitCodeBase + 3},
+		{name: "Igual ao limite", findings: findings, failOn: SeverityHigh, expected: ExitCodeBase + 3},
+		{name: "Abaixo do limite", findings: findings, failOn: SeverityCritical, expected: 0},
+		{name: "Desativado", findings: findings, failOn: SeverityNone, expected: 0},
+		{name: "Sem achados", findings: nil, failOn: SeverityInfo, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := ExitCode(tt.findings, tt.failOn); code != tt.expected {
+				t.Errorf("ExitCode() = %d, want %d", code, tt.expected)
+			}
+		})
+	}
+
+	if ValidateFailOn("sometimes") == nil {
+		t.Errorf("Expected error for invalid --fail-on value")
+	}
+}
+
+func TestSort(t *testing.T) {
+	findings := []Finding{
+		{File: "b.go", Line: 1, Severity: SeverityLow},
+		{File: "a.go", Line: 5, Severity: SeverityLow},
+		{File: "a.go", Line: 5, Severity: SeverityCritical},
+		{File: "a.go", Line: 2, Severity: SeverityInfo},
+	}
+
+	Sort(findings)
+
+	expected := []string{"a.go:2:info", "a.go:5:critical", "a.go:5:low", "b.go:1:low"}
+	for i, f := range findings {
+		got := fmt.Sprintf("%s:%d:%s", f.File, f.Line, f.Severity)
+		if got != expected[i] {
+			t.Errorf("Position %d: got %s, want %s", i, got, expected[i])
+		}
+	}
+}
+
+func TestRenderFormats(t *testing.T) {
+	findings := []Finding{
+		{File: "main.go", Line: 7, Severity: SeverityHigh, Category: "bug", Message: "erro ignorado", Suggestion: "trate o erro"},
+		{File: "main.go", Severity: SeverityInfo, Category: "style", Message: "comentário ausente"},
+	}
+
+	var text bytes.Buffer
+	if err := Render(&text, findings, FormatText, "1.0.0"); err != nil {
+		t.Fatalf("Render(text) error = %v", err)
+	}
+	for _, expected := range []string{"main.go", "linha 7", "erro ignorado", "trate o erro", "maior severidade: high"} {
+		if !strings.Contains(text.String(), expected) {
+			t.Errorf("Expected text output to contain %q", expected)
+		}
+	}
+
+	var jsonOut bytes.Buffer
+	Render(&jsonOut, findings, FormatJSON, "1.0.0")
+	var decoded []Finding
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil || len(decoded) != 2 {
+		t.Errorf("Expected JSON array with 2 findings, got %s (%v)", jsonOut.String(), err)
+	}
+
+	var sarif bytes.Buffer
+	Render(&sarif, findings, FormatSARIF, "1.0.0")
+	var log sarifLog
+	if err := json.Unmarshal(sarif.Bytes(), &log); err != nil {
+		t.Fatalf("Invalid SARIF JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF envelope: %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Results) != 2 || len(run.Tool.Driver.Rules) != 2 {
+		t.Errorf("Expected 2 results and 2 rules, got %d and %d", len(run.Results), len(run.Tool.Driver.Rules))
+	}
+	if run.Results[0].Level != "error" || run.Results[1].Level != "note" {
+		t.Errorf("Unexpected SARIF levels: %s, %s", run.Results[0].Level, run.Results[1].Level)
+	}
+	if run.Results[0].Locations[0].PhysicalLocation.Region.StartLine != 7 {
+		t.Errorf("Expected SARIF region start line 7")
+	}
+	if run.Results[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("Expected no region for finding without line")
+	}
+
+	if err := Render(&text, findings, "xml", ""); err == nil {
+		t.Errorf("Expected error for unknown format")
+	}
+}
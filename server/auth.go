@@ -0,0 +1,114 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/auth"
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// UsageResponse é a resposta de GET /v1/admin/usage
+type UsageResponse struct {
+	Keys []auth.Usage `json:"keys"`
+}
+
+// authenticate exige uma API key válida ("Authorization: Bearer" ou X-API-Key)
+// e guarda a chave no contexto da requisição. Com limite por cliente, cada falha
+// consome uma ficha do balde do IP; esgotado o balde, novas tentativas desse IP
+// são recusadas com 429 antes mesmo de a chave ser verificada.
+func (s *Server) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	if s.opts.Auth == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := s.clientID(r)
+		if s.limiter != nil {
+			if ok, wait := s.limiter.Peek(client); !ok {
+				s.rateLimited(w, wait)
+				return
+			}
+		}
+
+		token := apiKey(r)
+		k := s.opts.Auth.Authenticate(token)
+		if k == nil {
+			if s.limiter != nil {
+				s.limiter.Allow(client)
+			}
+			message := "API key ausente: use o cabeçalho Authorization: Bearer <chave>"
+			if token != "" {
+				message = "API key inválida"
+				s.opts.Logger.WarnContext(r.Context(), "API key inválida", "client", client)
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="code-explainer"`)
+			writeError(w, http.StatusUnauthorized, ErrorUnauthorized, message)
+			return
+		}
+		next(w, r.WithContext(auth.WithKey(r.Context(), k)))
+	}
+}
+
+// quota recusa a requisição se a cota diária da chave já acabou. A requisição só é
+// contada por reserve, depois de validada e de obter uma vaga na fila.
+func (s *Server) quota(next http.HandlerFunc) http.HandlerFunc {
+	if s.opts.Auth == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.quotaExceeded(w, s.opts.Auth.Check(auth.KeyFrom(r.Context()))) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// reserve conta a requisição na cota diária da chave autenticada. Retorna false,
+// já tendo respondido 429, se a cota acabou enquanto a requisição aguardava.
+func (s *Server) reserve(w http.ResponseWriter, r *http.Request) bool {
+	if s.opts.Auth == nil {
+		return true
+	}
+	k := auth.KeyFrom(r.Context())
+	if k == nil {
+		return true
+	}
+	return !s.quotaExceeded(w, s.opts.Auth.Begin(k))
+}
+
+// quotaExceeded responde 429 se err for um *auth.QuotaError
+func (s *Server) quotaExceeded(w http.ResponseWriter, err error) bool {
+	var quotaErr *auth.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+	s.metrics.rejected.Inc("quota")
+	writeRetryAfter(w, time.Until(quotaErr.ResetAt), ErrorQuotaExceeded, err.Error())
+	return true
+}
+
+// admin restringe a rota às chaves com admin: true
+func (s *Server) admin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if k := auth.KeyFrom(r.Context()); k == nil || !k.Admin {
+			writeError(w, http.StatusForbidden, ErrorForbidden, "rota restrita a chaves de administrador")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// chargeTokens soma os tokens da explicação à cota da chave autenticada
+func (s *Server) chargeTokens(r *http.Request, usage *openai.Usage) {
+	if s.opts.Auth == nil || usage == nil {
+		return
+	}
+	if k := auth.KeyFrom(r.Context()); k != nil {
+		s.opts.Auth.AddTokens(k, usage.TotalTokens())
+	}
+}
+
+func (s *Server) handleAdminUsage(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, UsageResponse{Keys: s.opts.Auth.Usage()})
+}
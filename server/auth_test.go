@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/mvcbotelho/code-explainer/auth"
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+func newAuthServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	a, err := auth.NewAuthenticator([]auth.Key{
+		{Name: "ana", Hash: auth.HashKey("ce_ana"), DailyRequests: 2},
+		{Name: "bia", Hash: auth.HashKey("ce_bia"), DailyTokens: 100},
+		{Name: "admin", Hash: auth.HashKey("ce_admin"), Admin: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(Options{
+		Explain: func(ctx context.Context, code string, config *openai.Config) (string, error) {
+			if config.OnUsage != nil {
+				config.OnUsage(openai.Usage{PromptTokens: 40, CompletionTokens: 60})
+			}
+			return "ok", nil
+		},
+		Auth:   a,
+		Logger: discardLogger,
+	})
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestAuthentication(t *testing.T) {
+	ts := newAuthServer(t)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		headers map[string]string
+		status  int
+		body    string
+		errType string
+	}{
+		{name: "Corpo inválido não consome cota", method: http.MethodPost, path: "/v1/explain", headers: map[string]string{"X-API-Key": "ce_ana"}, body: `{"code": " "}`, status: http.StatusBadRequest, errType: ErrorInvalidRequest},
+		{name: "Sem chave", method: http.MethodPost, path: "/v1/explain", status: http.StatusUnauthorized, errType: ErrorUnauthorized},
+		{name: "Chave inválida", method: http.MethodPost, path: "/v1/explain", headers: map[string]string{"Authorization": "Bearer ce_errada"}, status: http.StatusUnauthorized, errType: ErrorUnauthorized},
+		{name: "Esquema errado", method: http.MethodPost, path: "/v1/explain", headers: map[string]string{"Authorization": "Basic ce_ana"}, status: http.StatusUnauthorized, errType: ErrorUnauthorized},
+		{name: "Bearer aceito", method: http.MethodPost, path: "/v1/explain", headers: map[string]string{"Authorization": "Bearer ce_ana"}, status: http.StatusOK},
+		{name: "X-API-Key aceito", method: http.MethodPost, path: "/v1/explain", headers: map[string]string{"X-API-Key": "ce_ana"}, status: http.StatusOK},
+		{name: "Cota de requisições", method: http.MethodPost, path: "/v1/explain", headers: map[string]string{"X-API-Key": "ce_ana"}, status: http.StatusTooManyRequests, errType: ErrorQuotaExceeded},
+		{name: "Detect não consome cota", method: http.MethodPost, path: "/v1/detect", headers: map[string]string{"X-API-Key": "ce_ana"}, status: http.StatusOK},
+		{name: "Detect exige chave", method: http.MethodPost, path: "/v1/detect", status: http.StatusUnauthorized, errType: ErrorUnauthorized},
+		{name: "Healthz aberto", method: http.MethodGet, path: "/healthz", status: http.StatusOK},
+		{name: "Tokens dentro da cota", method: http.MethodPost, path: "/v1/explain", headers: map[string]string{"X-API-Key": "ce_bia"}, status: http.StatusOK},
+		{name: "Cota de tokens", method: http.MethodPost, path: "/v1/explain", headers: map[string]string{"X-API-Key": "ce_bia"}, status: http.StatusTooManyRequests, errType: ErrorQuotaExceeded},
+		{name: "Admin exige chave de administrador", method: http.MethodGet, path: "/v1/admin/usage", headers: map[string]string{"X-API-Key": "ce_ana"}, status: http.StatusForbidden, errType: ErrorForbidden},
+		{name: "Admin sem chave", method: http.MethodGet, path: "/v1/admin/usage", status: http.StatusUnauthorized, errType: ErrorUnauthorized},
+		{name: "Métricas sem chave", method: http.MethodGet, path: "/metrics", status: http.StatusUnauthorized, errType: ErrorUnauthorized},
+		{name: "Métricas exigem chave de administrador", method: http.MethodGet, path: "/metrics", headers: map[string]string{"X-API-Key": "ce_bia"}, status: http.StatusForbidden, errType: ErrorForbidden},
+		{name: "Métricas com chave de administrador", method: http.MethodGet, path: "/metrics", headers: map[string]string{"X-API-Key": "ce_admin"}, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == "" && tt.method == http.MethodPost {
+				body = `{"code": "x"}`
+			}
+			resp, errResp := request(t, tt.method, ts.URL+tt.path, body, tt.headers)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d (erro: %+v)", resp.StatusCode, tt.status, errResp.Error)
+			}
+			if errResp.Error.Type != tt.errType {
+				t.Errorf("tipo de erro = %q, want %q", errResp.Error.Type, tt.errType)
+			}
+			switch tt.status {
+			case http.StatusUnauthorized:
+				if resp.Header.Get("WWW-Authenticate") == "" {
+					t.Error("resposta 401 sem WWW-Authenticate")
+				}
+			case http.StatusTooManyRequests:
+				if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err != nil || seconds < 1 || seconds > 86400 {
+					t.Errorf("Retry-After = %q", resp.Header.Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func TestAdminUsage(t *testing.T) {
+	ts := newAuthServer(t)
+
+	request(t, http.MethodPost, ts.URL+"/v1/explain", `{"code": "x"}`, map[string]string{"X-API-Key": "ce_ana"})
+	request(t, http.MethodPost, ts.URL+"/v1/explain", `{"code": "x"}`, map[string]string{"X-API-Key": "ce_bia"})
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/admin/usage", nil)
+	req.Header.Set("Authorization", "Bearer ce_admin")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	var got UsageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	usage := map[string]auth.Usage{}
+	for _, u := range got.Keys {
+		usage[u.Name] = u
+	}
+	if len(usage) != 3 {
+		t.Fatalf("chaves = %+v, want 3", got.Keys)
+	}
+	if u := usage["ana"]; u.Requests != 1 || u.Tokens != 100 || u.DailyRequests != 2 {
+		t.Errorf("uso de ana = %+v", u)
+	}
+	if u := usage["bia"]; u.Requests != 1 || u.Tokens != 100 || u.DailyTokens != 100 {
+		t.Errorf("uso de bia = %+v", u)
+	}
+	if u := usage["admin"]; u.Requests != 0 || !u.Admin {
+		t.Errorf("uso de admin = %+v", u)
+	}
+}
+
+func TestAdminRouteWithoutAuth(t *testing.T) {
+	ts := newTestServer(t, nil)
+	resp, err := http.Get(ts.URL + "/v1/admin/usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 sem autenticação configurada", resp.StatusCode)
+	}
+}
+
+func TestAuthFailuresThrottled(t *testing.T) {
+	a, err := auth.NewAuthenticator([]auth.Key{{Name: "ana", Hash: auth.HashKey("ce_ana")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(Options{
+		Explain: func(ctx context.Context, code string, config *openai.Config) (string, error) {
+			return "ok", nil
+		},
+		Auth:       a,
+		RateLimit:  1,
+		Burst:      2,
+		TrustProxy: true,
+		Logger:     discardLogger,
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{name: "Primeira chave errada", headers: map[string]string{"X-API-Key": "ce_1", "X-Forwarded-For": "10.0.0.1"}, status: http.StatusUnauthorized},
+		{name: "Segunda chave errada", headers: map[string]string{"X-API-Key": "ce_2", "X-Forwarded-For": "10.0.0.1"}, status: http.StatusUnauthorized},
+		{name: "IP bloqueado mesmo com a chave certa", headers: map[string]string{"X-API-Key": "ce_ana", "X-Forwarded-For": "10.0.0.1"}, status: http.StatusTooManyRequests},
+		{name: "Outro IP com a chave certa", headers: map[string]string{"X-API-Key": "ce_ana", "X-Forwarded-For": "10.0.0.2"}, status: http.StatusOK},
+		{name: "Chave não herda as falhas do IP", headers: map[string]string{"X-API-Key": "ce_ana", "X-Forwarded-For": "10.0.0.2"}, status: http.StatusOK},
+		{name: "Limite pelo nome da chave", headers: map[string]string{"X-API-Key": "ce_ana", "X-Forwarded-For": "10.0.0.3"}, status: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, errResp := request(t, http.MethodPost, ts.URL+"/v1/explain", `{"code": "x"}`, tt.headers)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d (erro: %+v)", resp.StatusCode, tt.status, errResp.Error)
+			}
+			if tt.status == http.StatusTooManyRequests && errResp.Error.Type != ErrorRateLimited {
+				t.Errorf("tipo de erro = %q, want %q", errResp.Error.Type, ErrorRateLimited)
+			}
+		})
+	}
+}
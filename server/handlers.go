@@ -0,0 +1,272 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/policy"
+)
+
+// Tipos de erro retornados pela API
+const (
+	ErrorInvalidRequest = "invalid_request"
+	ErrorTooLarge       = "request_too_large"
+	ErrorTimeout        = "timeout"
+	ErrorModelNotFound  = "model_not_found"
+	ErrorContextTooLong = "context_too_long"
+	ErrorUnavailable    = "backend_unavailable"
+	ErrorPolicy         = "policy_blocked"
+	ErrorRateLimited    = "rate_limited"
+	ErrorServerBusy     = "server_busy"
+	ErrorUnauthorized   = "unauthorized"
+	ErrorForbidden      = "forbidden"
+	ErrorQuotaExceeded  = "quota_exceeded"
+	ErrorAPI            = "api_error"
+	ErrorExplain        = "explain_error"
+	ErrorCanceled       = "canceled"
+)
+
+// statusClientClosedRequest é o status (convenção do nginx) das explicações canceladas
+// porque o cliente desconectou ou o servidor está encerrando
+const statusClientClosedRequest = 499
+
+// ExplainRequest é o corpo de POST /v1/explain. Os campos opcionais espelham
+// openai.Config; a URL da API não pode ser alterada pelo cliente.
+type ExplainRequest struct {
+	Code     string `json:"code"`
+	Language string `json:"language,omitempty"`
+	Model    string `json:"model,omitempty"` // Recusado se diferente de MODEL_NAME, quando o servidor a define
+	Level    string `json:"level,omitempty"`
+	Timeout  int    `json:"timeout,omitempty"` // Em segundos; limitado por Options.MaxTimeout
+}
+
+// ExplainResponse é a resposta de POST /v1/explain
+type ExplainResponse struct {
+	Explanation string `json:"explanation"`
+	Language    string `json:"language"`
+	Model       string `json:"model"`
+	Level       string `json:"level,omitempty"`
+	DurationMs  int64  `json:"duration_ms"`
+
+	Usage *openai.Usage `json:"usage,omitempty"` // Tokens e tempos informados pelo modelo
+}
+
+// DetectRequest é o corpo de POST /v1/detect
+type DetectRequest struct {
+	Code     string `json:"code"`
+	Filename string `json:"filename,omitempty"` // Se informado, a extensão tem prioridade
+}
+
+// DetectResponse é a resposta de POST /v1/detect
+type DetectResponse struct {
+	Language string `json:"language"`
+}
+
+// LanguagesResponse é a resposta de GET /v1/languages
+type LanguagesResponse struct {
+	Languages []string `json:"languages"`
+	Levels    []string `json:"levels"`
+}
+
+// HealthResponse é a resposta de GET /healthz
+type HealthResponse struct {
+	Status  string `json:"status"`
+	Version string `json:"version,omitempty"`
+}
+
+// ErrorResponse é o corpo das respostas de erro
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// Error descreve um erro da API
+type Error struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func (s *Server) handleExplain(w http.ResponseWriter, r *http.Request) {
+	var req ExplainRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	config, err := s.configFor(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrorInvalidRequest, err.Error())
+		return
+	}
+	if !s.reserve(w, r) {
+		return
+	}
+
+	var usage *openai.Usage
+	config.OnUsage = openai.CaptureUsage(config.OnUsage, &usage)
+
+	start := time.Now()
+	explanation, err := s.opts.Explain(r.Context(), req.Code, config)
+	s.chargeTokens(r, usage)
+	if err != nil {
+		status, errType := classifyError(err)
+		s.explainFailed(r, config, errType, err)
+		writeError(w, status, errType, err.Error())
+		return
+	}
+	model, language := s.metricLabels(config)
+	s.metrics.observeExplain(model, language, time.Since(start), "")
+
+	writeJSON(w, http.StatusOK, ExplainResponse{
+		Explanation: explanation,
+		Language:    config.Language,
+		Model:       config.Model,
+		Level:       config.Level,
+		DurationMs:  time.Since(start).Milliseconds(),
+		Usage:       usage,
+	})
+}
+
+// explainFailed registra a falha de uma explicação nas métricas e no log
+func (s *Server) explainFailed(r *http.Request, config *openai.Config, errType string, err error) {
+	model, language := s.metricLabels(config)
+	s.metrics.observeExplain(model, language, 0, errType)
+	s.opts.Logger.WarnContext(r.Context(), "falha ao explicar código",
+		"model", config.Model,
+		"language", config.Language,
+		"type", errType,
+		"error", err,
+	)
+}
+
+// configFor valida a requisição e monta a configuração a partir dos padrões do servidor
+func (s *Server) configFor(req ExplainRequest) (*openai.Config, error) {
+	if strings.TrimSpace(req.Code) == "" {
+		return nil, fmt.Errorf("o campo code é obrigatório")
+	}
+	if err := openai.ValidateLevel(req.Level); err != nil {
+		return nil, err
+	}
+	if req.Timeout < 0 {
+		return nil, fmt.Errorf("timeout deve ser maior que zero")
+	}
+
+	if fixed := openai.ModelOverride(); fixed != "" && req.Model != "" && req.Model != fixed {
+		return nil, fmt.Errorf("o modelo deste servidor é fixado por MODEL_NAME (%s); omita o campo model", fixed)
+	}
+
+	config := *s.opts.Config
+	if req.Model != "" {
+		config.Model = req.Model
+	}
+	// A resposta, os logs e as métricas informam o modelo que de fato responde
+	config.Model = openai.EffectiveModel(&config)
+	if req.Level != "" {
+		config.Level = req.Level
+	}
+	if req.Language != "" {
+		config.Language = req.Language
+	}
+	if config.Language == "" {
+		config.Language = openai.DetectLanguage(req.Code)
+	}
+	if req.Timeout > 0 {
+		config.Timeout = time.Duration(req.Timeout) * time.Second
+	}
+	if config.Timeout <= 0 || config.Timeout > s.opts.MaxTimeout {
+		config.Timeout = s.opts.MaxTimeout
+	}
+
+	return &config, nil
+}
+
+func (s *Server) handleDetect(w http.ResponseWriter, r *http.Request) {
+	var req DetectRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Code) == "" && req.Filename == "" {
+		writeError(w, http.StatusBadRequest, ErrorInvalidRequest, "informe code ou filename")
+		return
+	}
+
+	lang := ""
+	if req.Filename != "" {
+		lang = openai.LanguageFromFilename(req.Filename)
+	}
+	if lang == "" {
+		lang = openai.DetectLanguage(req.Code)
+	}
+
+	writeJSON(w, http.StatusOK, DetectResponse{Language: lang})
+}
+
+func (s *Server) handleLanguages(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, LanguagesResponse{
+		Languages: openai.GetSupportedLanguages(),
+		Levels:    openai.GetPromptLevels(),
+	})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, HealthResponse{Status: "ok", Version: s.opts.Version})
+}
+
+// decodeJSON lê o corpo da requisição; em caso de erro já escreve a resposta e retorna false
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, ErrorTooLarge,
+				fmt.Sprintf("o corpo da requisição excede %d bytes", tooLarge.Limit))
+			return false
+		}
+		writeError(w, http.StatusBadRequest, ErrorInvalidRequest, "JSON inválido: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// classifyError traduz o erro da explicação em status HTTP e tipo de erro
+func classifyError(err error) (int, string) {
+	var apiErr *openai.APIError
+	switch {
+	case errors.Is(err, policy.ErrBlocked):
+		return http.StatusForbidden, ErrorPolicy
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, openai.ErrTimeout):
+		return http.StatusGatewayTimeout, ErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest, ErrorCanceled
+	case errors.Is(err, openai.ErrModelNotFound):
+		return http.StatusNotFound, ErrorModelNotFound
+	case errors.Is(err, openai.ErrContextTooLong):
+		return http.StatusRequestEntityTooLarge, ErrorContextTooLong
+	case errors.Is(err, openai.ErrServerUnavailable):
+		return http.StatusServiceUnavailable, ErrorUnavailable
+	case errors.As(err, &apiErr):
+		return http.StatusBadGateway, ErrorAPI
+	default:
+		return http.StatusInternalServerError, ErrorExplain
+	}
+}
+
+// writeJSON escreve v como JSON com o status informado
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+// writeError escreve uma resposta de erro padronizada
+func writeError(w http.ResponseWriter, status int, errType, message string) {
+	writeJSON(w, status, ErrorResponse{Error: Error{Type: errType, Message: message}})
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/auth"
+	"github.com/mvcbotelho/code-explainer/ratelimit"
+)
+
+// queueRetryAfter é o tempo sugerido ao cliente quando a fila está cheia
+const queueRetryAfter = 5 * time.Second
+
+// limit aplica o limite de requisições por cliente (API key autenticada ou IP)
+func (s *Server) limit(next http.HandlerFunc) http.HandlerFunc {
+	if s.limiter == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := s.limiter.Allow(s.clientID(r)); !ok {
+			s.rateLimited(w, wait)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// rateLimited responde 429 ao cliente que excedeu o limite de requisições
+func (s *Server) rateLimited(w http.ResponseWriter, wait time.Duration) {
+	s.metrics.rejected.Inc("rate_limit")
+	writeRetryAfter(w, wait, ErrorRateLimited,
+		fmt.Sprintf("limite de %d requisições por minuto excedido", s.opts.RateLimit))
+}
+
+// queue limita as explicações simultâneas; as excedentes aguardam em fila
+func (s *Server) queue(next http.HandlerFunc) http.HandlerFunc {
+	if s.gate == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		release, err := s.gate.Acquire(r.Context())
+		if errors.Is(err, ratelimit.ErrQueueFull) {
+			s.metrics.rejected.Inc("queue_full")
+			writeRetryAfter(w, queueRetryAfter, ErrorServerBusy, err.Error())
+			return
+		}
+		if err != nil {
+			// O cliente desistiu enquanto aguardava; não há a quem responder
+			return
+		}
+		defer release()
+
+		s.metrics.queueWait.Observe(time.Since(start).Seconds())
+		next(w, r)
+	}
+}
+
+// clientID identifica o cliente pelo nome da API key já autenticada ou, sem ela, pelo
+// endereço IP. Chaves ainda não verificadas não contam: com uma chave inventada por
+// requisição, cada uma teria um balde novo e o limite seria contornado.
+func (s *Server) clientID(r *http.Request) string {
+	if k := auth.KeyFrom(r.Context()); k != nil {
+		return "key:" + k.Name
+	}
+	return "ip:" + s.clientIP(r)
+}
+
+// clientIP retorna o endereço do cliente. Com TrustProxy, é a última entrada do
+// X-Forwarded-For, a que o proxy confiável acrescentou; as anteriores vêm do próprio
+// cliente e podem ser forjadas.
+func (s *Server) clientIP(r *http.Request) string {
+	if s.opts.TrustProxy {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			hops := strings.Split(forwarded[len(forwarded)-1], ",")
+			if last := strings.TrimSpace(hops[len(hops)-1]); last != "" {
+				return last
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return host
+}
+
+// apiKey retorna a chave enviada em "Authorization: Bearer" ou X-API-Key
+func apiKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// writeRetryAfter responde 429 com o cabeçalho Retry-After em segundos (arredondado para cima)
+func writeRetryAfter(w http.ResponseWriter, wait time.Duration, errType, message string) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeError(w, http.StatusTooManyRequests, errType, message)
+}
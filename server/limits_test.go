@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/auth"
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// request envia uma requisição com os cabeçalhos informados e retorna a resposta já lida
+func request(t *testing.T, method, url, body string, headers map[string]string) (*http.Response, ErrorResponse) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	var errResp ErrorResponse
+	json.NewDecoder(resp.Body).Decode(&errResp)
+	return resp, errResp
+}
+
+func TestRateLimit(t *testing.T) {
+	s := New(Options{
+		Explain: func(ctx context.Context, code string, config *openai.Config) (string, error) {
+			return "ok", nil
+		},
+		RateLimit:  1,
+		Burst:      2,
+		TrustProxy: true,
+		Logger:     discardLogger,
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		status  int
+	}{
+		{name: "Primeira do IP", path: "/v1/explain", status: http.StatusOK},
+		{name: "Segunda do IP", path: "/v1/detect", status: http.StatusOK},
+		{name: "Terceira do IP", path: "/v1/explain", status: http.StatusTooManyRequests},
+		{name: "Healthz sem limite", path: "/healthz", status: http.StatusOK},
+		{name: "Chave não verificada não cria balde", path: "/v1/explain", headers: map[string]string{"Authorization": "Bearer chave-inventada"}, status: http.StatusTooManyRequests},
+		{name: "IP adicionado pelo proxy", path: "/v1/explain", headers: map[string]string{"X-Forwarded-For": "127.0.0.1, 10.0.0.7"}, status: http.StatusOK},
+		{name: "Mesmo IP do proxy", path: "/v1/detect", headers: map[string]string{"X-Forwarded-For": "10.0.0.7"}, status: http.StatusOK},
+		{name: "Primeira entrada forjada não escapa", path: "/v1/explain", headers: map[string]string{"X-Forwarded-For": "203.0.113.9, 10.0.0.7"}, status: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, body := http.MethodPost, `{"code": "x"}`
+			if tt.path == "/healthz" {
+				method, body = http.MethodGet, ""
+			}
+			resp, errResp := request(t, method, ts.URL+tt.path, body, tt.headers)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status == http.StatusTooManyRequests {
+				if errResp.Error.Type != ErrorRateLimited || resp.Header.Get("Retry-After") == "" {
+					t.Errorf("erro = %+v, Retry-After = %q", errResp.Error, resp.Header.Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	started := make(chan struct{}, 2)
+	unblock := make(chan struct{})
+	m := NewMetrics()
+	a, err := auth.NewAuthenticator([]auth.Key{{Name: "ops", Hash: auth.HashKey("ce_ops"), Admin: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := map[string]string{"X-API-Key": "ce_ops"}
+	s := New(Options{
+		Explain: func(ctx context.Context, code string, config *openai.Config) (string, error) {
+			started <- struct{}{}
+			<-unblock
+			return "ok", nil
+		},
+		MaxInFlight: 1,
+		MaxQueue:    1,
+		Metrics:     m,
+		Auth:        a,
+		Logger:      discardLogger,
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	done := make(chan int, 2)
+	send := func() {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/v1/explain", strings.NewReader(`{"code": "x"}`))
+		req.Header.Set("X-API-Key", "ce_ops")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			done <- 0
+			return
+		}
+		resp.Body.Close()
+		done <- resp.StatusCode
+	}
+
+	go send()
+	<-started
+	go send()
+	deadline := time.Now().Add(time.Second)
+	for s.gate.Queued() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("segunda requisição não entrou na fila")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	resp, errResp := request(t, http.MethodPost, ts.URL+"/v1/explain", `{"code": "x"}`, headers)
+	if resp.StatusCode != http.StatusTooManyRequests || errResp.Error.Type != ErrorServerBusy || resp.Header.Get("Retry-After") != "5" {
+		t.Errorf("fila cheia: status = %d, erro = %+v, Retry-After = %q", resp.StatusCode, errResp.Error, resp.Header.Get("Retry-After"))
+	}
+
+	// Só a requisição em execução conta na cota; a da fila e a recusada ainda não
+	if usage := a.Usage(); usage[0].Requests != 1 {
+		t.Errorf("requisições na cota = %d, want 1", usage[0].Requests)
+	}
+
+	metricsReq, _ := http.NewRequest(http.MethodGet, ts.URL+"/metrics", nil)
+	metricsReq.Header.Set("X-API-Key", "ce_ops")
+	metricsResp, err := http.DefaultClient.Do(metricsReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(metricsResp.Body)
+	metricsResp.Body.Close()
+	for _, want := range []string{
+		"code_explainer_in_flight_requests 1",
+		"code_explainer_queue_depth 1",
+		`code_explainer_rejected_requests_total{reason="queue_full"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics não contém %q", want)
+		}
+	}
+
+	close(unblock)
+	for i := 0; i < 2; i++ {
+		if status := <-done; status != http.StatusOK {
+			t.Errorf("requisição %d: status = %d, want 200", i, status)
+		}
+	}
+	if usage := a.Usage(); usage[0].Requests != 2 {
+		t.Errorf("requisições na cota = %d, want 2", usage[0].Requests)
+	}
+}
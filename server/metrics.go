@@ -0,0 +1,209 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/logging"
+	"github.com/mvcbotelho/code-explainer/metrics"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/ratelimit"
+	"github.com/mvcbotelho/code-explainer/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Metrics reúne as métricas do servidor expostas em /metrics
+type Metrics struct {
+	Registry *metrics.Registry
+
+	requests        *metrics.Counter
+	requestDuration *metrics.Histogram
+	explainDuration *metrics.Histogram
+	upstreamErrors  *metrics.Counter
+	cacheLookups    *metrics.Counter
+	rejected        *metrics.Counter
+	queueWait       *metrics.Histogram
+
+	gate atomic.Pointer[ratelimit.Gate] // Fonte dos medidores da fila
+}
+
+// NewMetrics registra as métricas do servidor em um novo Registry
+func NewMetrics() *Metrics {
+	r := metrics.NewRegistry()
+	m := &Metrics{
+		Registry: r,
+		requests: r.Counter("code_explainer_http_requests_total",
+			"Requisições HTTP atendidas, por rota, método e status.", "route", "method", "status"),
+		requestDuration: r.Histogram("code_explainer_http_request_duration_seconds",
+			"Duração das requisições HTTP, por rota.", nil, "route"),
+		explainDuration: r.Histogram("code_explainer_explain_duration_seconds",
+			"Duração das explicações concluídas, por modelo e linguagem.", nil, "model", "language"),
+		upstreamErrors: r.Counter("code_explainer_upstream_errors_total",
+			"Falhas nas chamadas ao modelo, por modelo e tipo de erro.", "model", "type"),
+		cacheLookups: r.Counter("code_explainer_cache_lookups_total",
+			"Consultas ao cache de explicações, por resultado (hit ou miss).", "result"),
+	}
+	m.rejected = r.Counter("code_explainer_rejected_requests_total",
+		"Requisições recusadas com 429, por motivo (rate_limit, queue_full ou quota).", "reason")
+	m.queueWait = r.Histogram("code_explainer_queue_wait_seconds",
+		"Tempo de espera na fila antes de a explicação começar.", nil)
+	r.GaugeFunc("code_explainer_cache_hit_ratio",
+		"Proporção de consultas ao cache que encontraram a explicação.", m.cacheHitRatio)
+	r.GaugeFunc("code_explainer_in_flight_requests",
+		"Explicações em andamento.", m.gaugeFrom((*ratelimit.Gate).InFlight))
+	r.GaugeFunc("code_explainer_queue_depth",
+		"Explicações aguardando uma vaga.", m.gaugeFrom((*ratelimit.Gate).Queued))
+	return m
+}
+
+// watch passa a expor a ocupação de g nos medidores da fila
+func (m *Metrics) watch(g *ratelimit.Gate) {
+	m.gate.Store(g)
+}
+
+// gaugeFrom lê um valor do Gate observado; sem Gate, o medidor fica em zero
+func (m *Metrics) gaugeFrom(read func(*ratelimit.Gate) int) func() float64 {
+	return func() float64 {
+		if g := m.gate.Load(); g != nil {
+			return float64(read(g))
+		}
+		return 0
+	}
+}
+
+// CacheLookup registra uma consulta ao cache de explicações
+func (m *Metrics) CacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.Inc(result)
+}
+
+func (m *Metrics) cacheHitRatio() float64 {
+	hits, misses := m.cacheLookups.Value("hit"), m.cacheLookups.Value("miss")
+	if hits+misses == 0 {
+		return 0
+	}
+	return hits / (hits + misses)
+}
+
+// observeExplain registra o resultado de uma chamada ao modelo
+func (m *Metrics) observeExplain(model, language string, duration time.Duration, errType string) {
+	if errType != "" {
+		m.upstreamErrors.Inc(model, errType)
+		return
+	}
+	m.explainDuration.Observe(duration.Seconds(), model, language)
+}
+
+// otherLabel substitui, nos rótulos das métricas, valores fora do conjunto conhecido
+const otherLabel = "other"
+
+// metricLabels retorna os rótulos model e language da configuração. Os valores vêm do
+// corpo da requisição; limitá-los aos modelos e linguagens conhecidos evita que cada
+// valor novo crie uma série nova no Prometheus.
+func (s *Server) metricLabels(config *openai.Config) (model, language string) {
+	model, language = config.Model, config.Language
+	// "llama3" é o mesmo modelo que "llama3:latest" na lista de instalados
+	if !s.models[model] && !s.models[model+":latest"] {
+		model = otherLabel
+	}
+	if !s.languages[language] {
+		language = otherLabel
+	}
+	return model, language
+}
+
+// instrument atribui um ID a cada requisição, abre o span do servidor (continuando o
+// rastreamento do cliente, se houver traceparent) e registra as métricas HTTP e o log
+// de acesso. O ID vem do cabeçalho X-Request-ID, se válido, e é devolvido na resposta.
+func (s *Server) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+
+		// Rotas desconhecidas são agrupadas para limitar a cardinalidade das métricas
+		route := otherLabel
+		if _, pattern := s.mux.Handler(r); pattern != "" {
+			_, path, _ := strings.Cut(pattern, " ")
+			route = path
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := otel.Tracer(tracing.Name).Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String(logging.RequestIDKey, id),
+			),
+		)
+		r = r.WithContext(logging.WithRequestID(ctx, id))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		span.SetAttributes(attribute.Int("http.response.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+		span.End()
+
+		duration := time.Since(start)
+		s.metrics.requests.Inc(route, r.Method, strconv.Itoa(rec.status))
+		s.metrics.requestDuration.Observe(duration.Seconds(), route)
+		s.opts.Logger.InfoContext(r.Context(), "requisição atendida",
+			"method", r.Method,
+			"route", route,
+			"status", rec.status,
+			"duration_ms", duration.Milliseconds(),
+		)
+	})
+}
+
+// validRequestID aceita IDs curtos com letras, dígitos, "-", "_" e "."
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorder guarda o status da resposta; mantém o Flush para o streaming
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
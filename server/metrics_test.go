@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/logging"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	m := NewMetrics()
+	s := New(Options{
+		Config: &openai.Config{APIURL: "http://ollama", Model: "codellama", Timeout: 30 * time.Second},
+		Explain: func(ctx context.Context, code string, config *openai.Config) (string, error) {
+			m.CacheLookup(code == "repetido")
+			if code == "falha" {
+				return "", openai.ErrServerUnavailable
+			}
+			return "ok", nil
+		},
+		Metrics: m,
+		Logger:  discardLogger,
+		Models:  []string{"llama3:latest"},
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	for _, code := range []string{"func main() {}", "repetido", "falha"} {
+		post(t, ts.URL+"/v1/explain", `{"code": "`+code+`", "language": "Go"}`, nil)
+	}
+	http.Get(ts.URL + "/nao-existe")
+
+	// Modelos e linguagens fora do conjunto conhecido não criam séries novas
+	post(t, ts.URL+"/v1/explain", `{"code": "x", "language": "Klingon", "model": "modelo-aleatorio-123"}`, nil)
+	post(t, ts.URL+"/v1/explain", `{"code": "x", "language": "Python", "model": "llama3"}`, nil)
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, want := range []string{
+		`code_explainer_http_requests_total{route="/v1/explain",method="POST",status="200"} 4`,
+		`code_explainer_http_requests_total{route="/v1/explain",method="POST",status="503"} 1`,
+		`code_explainer_http_requests_total{route="other",method="GET",status="404"} 1`,
+		`code_explainer_explain_duration_seconds_count{model="codellama",language="Go"} 2`,
+		`code_explainer_upstream_errors_total{model="codellama",type="backend_unavailable"} 1`,
+		`code_explainer_cache_lookups_total{result="hit"} 1`,
+		`code_explainer_cache_hit_ratio 0.2`,
+		`code_explainer_explain_duration_seconds_count{model="other",language="other"} 1`,
+		`code_explainer_explain_duration_seconds_count{model="llama3",language="Python"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics não contém %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	logger, _ := logging.New(&logs, logging.FormatJSON, "info")
+
+	var seen string
+	s := New(Options{
+		Explain: func(ctx context.Context, code string, config *openai.Config) (string, error) {
+			seen = logging.RequestID(ctx)
+			return "ok", nil
+		},
+		Logger: logger,
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		header string
+		reuse  bool
+	}{
+		{name: "Gerado pelo servidor", header: ""},
+		{name: "Enviado pelo cliente", header: "pedido-42", reuse: true},
+		{name: "Inválido", header: "com espaço"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			req, _ := http.NewRequest(http.MethodPost, ts.URL+"/v1/explain", strings.NewReader(`{"code": "x"}`))
+			if tt.header != "" {
+				req.Header.Set("X-Request-ID", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			id := resp.Header.Get("X-Request-ID")
+			if id == "" || id != seen || (tt.reuse && id != tt.header) || (!tt.reuse && id == tt.header) {
+				t.Errorf("X-Request-ID = %q, contexto = %q, cabeçalho enviado = %q", id, seen, tt.header)
+			}
+
+			var record map[string]interface{}
+			if err := json.Unmarshal(bytes.TrimSpace(logs.Bytes()), &record); err != nil {
+				t.Fatalf("log inválido %q: %v", logs.String(), err)
+			}
+			if record[logging.RequestIDKey] != id || record["route"] != "/v1/explain" || record["level"] != slog.LevelInfo.String() {
+				t.Errorf("registro de acesso inesperado: %v", record)
+			}
+		})
+	}
+}
+
+func TestTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	var traceID string
+	ts := newTestServer(t, func(ctx context.Context, code string, config *openai.Config) (string, error) {
+		traceID = trace.SpanContextFromContext(ctx).TraceID().String()
+		return "ok", nil
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/v1/explain", strings.NewReader(`{"code": "x"}`))
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if want := "4bf92f3577b34da6a3ce929d0e0e4736"; traceID != want {
+		t.Errorf("trace ID no contexto = %q, want %q", traceID, want)
+	}
+}
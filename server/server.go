@@ -0,0 +1,161 @@
+// Package server expõe a explicação e a detecção de linguagem como uma API REST.
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/auth"
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/ratelimit"
+)
+
+// Valores padrão do servidor
+const (
+	DefaultMaxBodyBytes = 1 << 20 // 1 MB
+	DefaultMaxTimeout   = 5 * time.Minute
+	DefaultBurst        = 5
+	DefaultMaxQueue     = 10
+	shutdownTimeout     = 30 * time.Second
+)
+
+// Options configura o servidor
+type Options struct {
+	Config       *openai.Config     // Configuração padrão das explicações (URL, modelo, timeout...)
+	Explain      openai.ExplainFunc // Função de explicação; nil usa openai.ExplainCodeContext
+	Stream       openai.StreamFunc  // Função de explicação em streaming; nil usa openai.ExplainCodeStream
+	MaxBodyBytes int64              // Tamanho máximo do corpo das requisições
+	MaxTimeout   time.Duration      // Limite para o timeout pedido pelo cliente
+	Version      string             // Versão informada em /healthz
+	Metrics      *Metrics           // Métricas expostas em /metrics; nil cria um conjunto novo
+	Logger       *slog.Logger       // Log de acesso e de erros; nil usa slog.Default()
+	Models       []string           // Modelos conhecidos além do padrão (ex.: os instalados), usados como rótulos das métricas
+
+	RateLimit   int  // Requisições por minuto por cliente (API key ou IP) nas rotas /v1; 0 desliga
+	Burst       int  // Rajada permitida acima da taxa; 0 usa DefaultBurst
+	MaxInFlight int  // Explicações simultâneas; 0 desliga o limite
+	MaxQueue    int  // Explicações aguardando uma vaga antes de responder 429
+	TrustProxy  bool // Identifica o cliente pelo X-Forwarded-For (servidor atrás de proxy)
+
+	Auth *auth.Authenticator // Exige API key nas rotas /v1 e aplica as cotas diárias; nil desliga
+}
+
+// Server atende a API REST do code-explainer
+type Server struct {
+	opts    Options
+	mux     *http.ServeMux
+	metrics *Metrics
+	limiter *ratelimit.Limiter // nil sem limite por cliente
+	gate    *ratelimit.Gate    // nil sem limite de simultaneidade
+
+	// Valores aceitos nos rótulos model e language das métricas; os demais viram "other"
+	models    map[string]bool
+	languages map[string]bool
+}
+
+// New cria o servidor preenchendo os valores padrão de opts
+func New(opts Options) *Server {
+	if opts.Config == nil {
+		opts.Config = openai.DefaultConfig()
+	}
+	if opts.Explain == nil {
+		opts.Explain = openai.ExplainCodeContext
+	}
+	if opts.Stream == nil {
+		opts.Stream = openai.ExplainCodeStream
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if opts.MaxTimeout <= 0 {
+		opts.MaxTimeout = DefaultMaxTimeout
+	}
+
+	if opts.Metrics == nil {
+		opts.Metrics = NewMetrics()
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	s := &Server{opts: opts, mux: http.NewServeMux(), metrics: opts.Metrics}
+	s.models = map[string]bool{opts.Config.Model: true}
+	for _, m := range opts.Models {
+		s.models[m] = true
+	}
+	s.languages = map[string]bool{}
+	for _, l := range openai.GetSupportedLanguages() {
+		s.languages[l] = true
+	}
+	if opts.RateLimit > 0 {
+		if opts.Burst <= 0 {
+			s.opts.Burst = DefaultBurst
+		}
+		s.limiter = ratelimit.NewLimiter(float64(opts.RateLimit), s.opts.Burst)
+	}
+	if opts.MaxInFlight > 0 {
+		s.gate = ratelimit.NewGate(opts.MaxInFlight, opts.MaxQueue)
+		s.metrics.watch(s.gate)
+	}
+	s.routes()
+	return s
+}
+
+// routes registra os endpoints da API. A autenticação vem antes do limite por
+// cliente, para que ele use o nome da chave já verificada.
+func (s *Server) routes() {
+	s.mux.HandleFunc("POST /v1/explain", s.authenticate(s.limit(s.quota(s.queue(s.handleExplain)))))
+	s.mux.HandleFunc("GET /v1/explain/stream", s.authenticate(s.limit(s.quota(s.queue(s.handleExplainStream)))))
+	s.mux.HandleFunc("POST /v1/explain/stream", s.authenticate(s.limit(s.quota(s.queue(s.handleExplainStream)))))
+	s.mux.HandleFunc("POST /v1/detect", s.authenticate(s.limit(s.handleDetect)))
+	s.mux.HandleFunc("GET /v1/languages", s.authenticate(s.limit(s.handleLanguages)))
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+
+	// Com API keys, as métricas e o uso ficam restritos às chaves de administrador
+	if s.opts.Auth == nil {
+		s.mux.Handle("GET /metrics", s.metrics.Registry.Handler())
+		return
+	}
+	s.mux.HandleFunc("GET /v1/admin/usage", s.authenticate(s.limit(s.admin(s.handleAdminUsage))))
+	s.mux.HandleFunc("GET /metrics", s.authenticate(s.limit(s.admin(s.metrics.Registry.Handler().ServeHTTP))))
+}
+
+// Handler retorna o http.Handler do servidor, útil com httptest
+func (s *Server) Handler() http.Handler {
+	return s.instrument(http.MaxBytesHandler(s.mux, s.opts.MaxBodyBytes))
+}
+
+// ListenAndServe atende em addr até ctx ser cancelado. Ao cancelar, aguarda as
+// requisições em andamento terminarem (graceful shutdown).
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
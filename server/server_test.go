@@ -0,0 +1,259 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+	"github.com/mvcbotelho/code-explainer/policy"
+)
+
+// discardLogger descarta o log de acesso durante os testes
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newTestServer cria um servidor com uma função de explicação falsa
+func newTestServer(t *testing.T, explain openai.ExplainFunc) *httptest.Server {
+	t.Helper()
+	s := New(Options{
+		Config:       &openai.Config{APIURL: "http://ollama", Model: "codellama", Timeout: 30 * time.Second},
+		Explain:      explain,
+		MaxBodyBytes: 1024,
+		MaxTimeout:   time.Minute,
+		Version:      "1.0.0",
+		Logger:       discardLogger,
+	})
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func post(t *testing.T, url, body string, v interface{}) int {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		json.NewDecoder(resp.Body).Decode(v)
+	}
+	return resp.StatusCode
+}
+
+func TestExplainEndpoint(t *testing.T) {
+	var got *openai.Config
+	ts := newTestServer(t, func(ctx context.Context, code string, config *openai.Config) (string, error) {
+		got = config
+		switch code {
+		case "lento":
+			return "", context.DeadlineExceeded
+		case "cancelado":
+			return "", fmt.Errorf("erro ao explicar código: %w", context.Canceled)
+		case "api":
+			return "", &openai.APIError{StatusCode: 500, Message: "500 Internal Server Error"}
+		case "modelo":
+			return "", &openai.APIError{StatusCode: 404, Message: "404 Not Found", Kind: openai.ErrModelNotFound}
+		case "contexto":
+			return "", &openai.APIError{StatusCode: 400, Message: "400 Bad Request", Kind: openai.ErrContextTooLong}
+		case "remoto":
+			return "", &policy.BlockedError{URL: "https://api.example.com", Host: "api.example.com"}
+		}
+		config.OnUsage(openai.Usage{PromptTokens: 20, CompletionTokens: 5})
+		return "Explicação de " + code, nil
+	})
+
+	tests := []struct {
+		name      string
+		body      string
+		status    int
+		errorType string
+	}{
+		{name: "Sucesso", body: `{"code": "func main() {}", "model": "llama3", "level": "basico", "timeout": 600}`, status: http.StatusOK},
+		{name: "Código vazio", body: `{"code": "  "}`, status: http.StatusBadRequest, errorType: ErrorInvalidRequest},
+		{name: "Nível inválido", body: `{"code": "x", "level": "expert"}`, status: http.StatusBadRequest, errorType: ErrorInvalidRequest},
+		{name: "Campo desconhecido", body: `{"code": "x", "api_url": "http://outro"}`, status: http.StatusBadRequest, errorType: ErrorInvalidRequest},
+		{name: "JSON inválido", body: `{`, status: http.StatusBadRequest, errorType: ErrorInvalidRequest},
+		{name: "Corpo grande demais", body: `{"code": "` + strings.Repeat("x", 2048) + `"}`, status: http.StatusRequestEntityTooLarge, errorType: ErrorTooLarge},
+		{name: "Timeout", body: `{"code": "lento"}`, status: http.StatusGatewayTimeout, errorType: ErrorTimeout},
+		{name: "Cancelado", body: `{"code": "cancelado"}`, status: statusClientClosedRequest, errorType: ErrorCanceled},
+		{name: "Modelo inexistente", body: `{"code": "modelo"}`, status: http.StatusNotFound, errorType: ErrorModelNotFound},
+		{name: "Contexto excedido", body: `{"code": "contexto"}`, status: http.StatusRequestEntityTooLarge, errorType: ErrorContextTooLong},
+		{name: "Destino bloqueado", body: `{"code": "remoto"}`, status: http.StatusForbidden, errorType: ErrorPolicy},
+		{name: "Erro da API", body: `{"code": "api"}`, status: http.StatusBadGateway, errorType: ErrorAPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp struct {
+				ExplainResponse
+				Error *Error `json:"error"`
+			}
+			status := post(t, ts.URL+"/v1/explain", tt.body, &resp)
+			if status != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, status)
+			}
+			if tt.errorType != "" {
+				if resp.Error == nil || resp.Error.Type != tt.errorType {
+					t.Errorf("Expected error type %s, got %+v", tt.errorType, resp.Error)
+				}
+				return
+			}
+			if resp.Explanation != "Explicação de func main() {}" || resp.Language != "Go" || resp.Model != "llama3" || resp.Level != "basico" {
+				t.Errorf("Unexpected response: %+v", resp.ExplainResponse)
+			}
+			if resp.Usage == nil || resp.Usage.TotalTokens() != 25 {
+				t.Errorf("Expected usage with 25 tokens, got %+v", resp.Usage)
+			}
+		})
+	}
+
+	post(t, ts.URL+"/v1/explain", `{"code": "x", "timeout": 600}`, nil)
+	if got.Timeout != time.Minute || got.APIURL != "http://ollama" {
+		t.Errorf("Expected timeout capped at 1m and server API URL, got %v / %s", got.Timeout, got.APIURL)
+	}
+}
+
+func TestExplainModelOverride(t *testing.T) {
+	t.Setenv("MODEL_NAME", "llama3")
+	ts := newTestServer(t, func(ctx context.Context, code string, config *openai.Config) (string, error) {
+		return "ok", nil
+	})
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		model  string
+	}{
+		{name: "Sem modelo", body: `{"code": "x"}`, status: http.StatusOK, model: "llama3"},
+		{name: "Mesmo modelo", body: `{"code": "x", "model": "llama3"}`, status: http.StatusOK, model: "llama3"},
+		{name: "Modelo sobreposto por MODEL_NAME", body: `{"code": "x", "model": "codellama:13b"}`, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp ExplainResponse
+			if status := post(t, ts.URL+"/v1/explain", tt.body, &resp); status != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, status)
+			}
+			if resp.Model != tt.model {
+				t.Errorf("Expected model %q, got %q", tt.model, resp.Model)
+			}
+		})
+	}
+}
+
+func TestDetectEndpoint(t *testing.T) {
+	ts := newTestServer(t, nil)
+
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		expected string
+	}{
+		{name: "Pelo código", body: `{"code": "def hello():\n    print('oi')"}`, status: http.StatusOK, expected: "Python"},
+		{name: "Pelo nome do arquivo", body: `{"code": "x", "filename": "lib.rs"}`, status: http.StatusOK, expected: "Rust"},
+		{name: "Sem dados", body: `{}`, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp DetectResponse
+			if status := post(t, ts.URL+"/v1/detect", tt.body, &resp); status != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, status)
+			}
+			if resp.Language != tt.expected {
+				t.Errorf("Expected language %q, got %q", tt.expected, resp.Language)
+			}
+		})
+	}
+}
+
+func TestReadOnlyEndpoints(t *testing.T) {
+	ts := newTestServer(t, nil)
+
+	resp, err := http.Get(ts.URL + "/v1/languages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var langs LanguagesResponse
+	json.NewDecoder(resp.Body).Decode(&langs)
+	resp.Body.Close()
+	if len(langs.Languages) == 0 || len(langs.Levels) != 3 {
+		t.Errorf("Unexpected languages response: %+v", langs)
+	}
+
+	resp, _ = http.Get(ts.URL + "/healthz")
+	var health HealthResponse
+	json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if health.Status != "ok" || health.Version != "1.0.0" {
+		t.Errorf("Unexpected health response: %+v", health)
+	}
+
+	resp, _ = http.Get(ts.URL + "/v1/explain")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET /v1/explain, got %d", resp.StatusCode)
+	}
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	started := make(chan struct{})
+	s := New(Options{Explain: func(ctx context.Context, code string, config *openai.Config) (string, error) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return "terminou", nil
+	}, Logger: discardLogger})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.ListenAndServe(ctx, addr) }()
+
+	// Aguarda o servidor aceitar conexões
+	for i := 0; i < 50; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	result := make(chan ExplainResponse, 1)
+	go func() {
+		var resp ExplainResponse
+		if r, err := http.Post("http://"+addr+"/v1/explain", "application/json", strings.NewReader(`{"code": "x"}`)); err == nil {
+			json.NewDecoder(r.Body).Decode(&resp)
+			r.Body.Close()
+		}
+		result <- resp
+	}()
+
+	<-started
+	cancel()
+
+	if err := <-done; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("ListenAndServe() error = %v", err)
+	}
+	if resp := <-result; resp.Explanation != "terminou" {
+		t.Errorf("Expected in-flight request to finish, got %+v", resp)
+	}
+}
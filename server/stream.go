@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// Eventos enviados por /v1/explain/stream
+const (
+	EventLanguage = "language"
+	EventToken    = "token"
+	EventDone     = "done"
+	EventError    = "error"
+)
+
+// LanguageEvent é o primeiro evento do streaming
+type LanguageEvent struct {
+	Language string `json:"language"`
+}
+
+// TokenEvent carrega um trecho da resposta do modelo
+type TokenEvent struct {
+	Token string `json:"token"`
+}
+
+// DoneEvent encerra o streaming com sucesso
+type DoneEvent struct {
+	Model      string `json:"model"`
+	Level      string `json:"level,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+
+	Usage *openai.Usage `json:"usage,omitempty"`
+}
+
+// handleExplainStream explica o código enviando a resposta como Server-Sent Events.
+// Aceita o mesmo corpo de /v1/explain (POST) ou os mesmos campos como parâmetros de consulta (GET).
+func (s *Server) handleExplainStream(w http.ResponseWriter, r *http.Request) {
+	var req ExplainRequest
+	if r.Method == http.MethodGet {
+		if err := requestFromQuery(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrorInvalidRequest, err.Error())
+			return
+		}
+	} else if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	config, err := s.configFor(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrorInvalidRequest, err.Error())
+		return
+	}
+	if !s.reserve(w, r) {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, ErrorExplain, "streaming não suportado pela conexão")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, data interface{}) error {
+		if err := writeEvent(w, event, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	if err := send(EventLanguage, LanguageEvent{Language: config.Language}); err != nil {
+		return
+	}
+
+	// r.Context() é cancelado quando o cliente desconecta, o que cancela a requisição ao modelo
+	var usage *openai.Usage
+	config.OnUsage = openai.CaptureUsage(config.OnUsage, &usage)
+
+	start := time.Now()
+	_, err = s.opts.Stream(r.Context(), req.Code, config, func(token string) error {
+		return send(EventToken, TokenEvent{Token: token})
+	})
+	s.chargeTokens(r, usage)
+	if r.Context().Err() != nil {
+		return
+	}
+	if err != nil {
+		_, errType := classifyError(err)
+		s.explainFailed(r, config, errType, err)
+		send(EventError, Error{Type: errType, Message: err.Error()})
+		return
+	}
+
+	model, language := s.metricLabels(config)
+	s.metrics.observeExplain(model, language, time.Since(start), "")
+	send(EventDone, DoneEvent{
+		Model:      config.Model,
+		Level:      config.Level,
+		DurationMs: time.Since(start).Milliseconds(),
+		Usage:      usage,
+	})
+}
+
+// requestFromQuery preenche a requisição com os parâmetros de consulta
+func requestFromQuery(r *http.Request, req *ExplainRequest) error {
+	q := r.URL.Query()
+	req.Code = q.Get("code")
+	req.Language = q.Get("language")
+	req.Model = q.Get("model")
+	req.Level = q.Get("level")
+
+	if t := q.Get("timeout"); t != "" {
+		timeout, err := strconv.Atoi(t)
+		if err != nil {
+			return fmt.Errorf("timeout inválido: %s", t)
+		}
+		req.Timeout = timeout
+	}
+	return nil
+}
+
+// writeEvent escreve um evento SSE com os dados em JSON (sempre em uma única linha)
+func writeEvent(w http.ResponseWriter, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
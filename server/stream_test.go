@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mvcbotelho/code-explainer/openai"
+)
+
+// sseEvent é um evento lido da resposta
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readEvents lê todos os eventos SSE do corpo
+func readEvents(t *testing.T, resp *http.Response) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var current sseEvent
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+	return events
+}
+
+// newStreamServer cria um servidor cuja função de streaming envia os tokens informados
+func newStreamServer(t *testing.T, stream openai.StreamFunc) *httptest.Server {
+	t.Helper()
+	s := New(Options{
+		Config: &openai.Config{APIURL: "http://ollama", Model: "codellama", Timeout: 30 * time.Second},
+		Stream: stream,
+		Logger: discardLogger,
+	})
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestExplainStream(t *testing.T) {
+	ts := newStreamServer(t, func(ctx context.Context, code string, config *openai.Config, onToken openai.TokenFunc) (string, error) {
+		if code == "falha" {
+			onToken("parcial")
+			return "", &openai.APIError{StatusCode: 500, Message: "500 Internal Server Error"}
+		}
+		for _, token := range []string{"Imprime ", "\"oi\"\n", "na tela."} {
+			if err := onToken(token); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	})
+
+	tests := []struct {
+		name     string
+		request  func() (*http.Response, error)
+		expected []string
+		contains string
+	}{
+		{
+			name: "POST",
+			request: func() (*http.Response, error) {
+				return http.Post(ts.URL+"/v1/explain/stream", "application/json", strings.NewReader(`{"code": "print('oi')"}`))
+			},
+			expected: []string{EventLanguage, EventToken, EventToken, EventToken, EventDone},
+			contains: `{"token":"\"oi\"\n"}`,
+		},
+		{
+			name: "GET",
+			request: func() (*http.Response, error) {
+				return http.Get(ts.URL + "/v1/explain/stream?" + url.Values{"code": {"fn main() {}"}, "language": {"Rust"}}.Encode())
+			},
+			expected: []string{EventLanguage, EventToken, EventToken, EventToken, EventDone},
+			contains: `{"language":"Rust"}`,
+		},
+		{
+			name: "Erro do modelo",
+			request: func() (*http.Response, error) {
+				return http.Post(ts.URL+"/v1/explain/stream", "application/json", strings.NewReader(`{"code": "falha"}`))
+			},
+			expected: []string{EventLanguage, EventToken, EventError},
+			contains: `"type":"api_error"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.request()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+				t.Errorf("Expected text/event-stream, got %s", ct)
+			}
+
+			events := readEvents(t, resp)
+			var names, all []string
+			for _, e := range events {
+				names = append(names, e.name)
+				all = append(all, e.data)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Events = %v, want %v", names, tt.expected)
+			}
+			if !strings.Contains(strings.Join(all, "\n"), tt.contains) {
+				t.Errorf("Expected events to contain %s, got %v", tt.contains, all)
+			}
+		})
+	}
+
+	resp, _ := http.Get(ts.URL + "/v1/explain/stream")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 without code, got %d", resp.StatusCode)
+	}
+}
+
+func TestExplainStreamClientDisconnect(t *testing.T) {
+	cancelled := make(chan error, 1)
+	ts := newStreamServer(t, func(ctx context.Context, code string, config *openai.Config, onToken openai.TokenFunc) (string, error) {
+		onToken("primeiro")
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return "", ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/v1/explain/stream?code=x", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lê o evento de linguagem e o primeiro token antes de desconectar
+	reader := bufio.NewReader(resp.Body)
+	for i := 0; i < 6; i++ {
+		reader.ReadString('\n')
+	}
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected upstream context to be cancelled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("Upstream request was not cancelled after client disconnect")
+	}
+}
@@ -0,0 +1,509 @@
+// Package testgen gera esqueletos de testes table-driven para funções Go
+// e completa a tabela de casos com a resposta do modelo.
+package testgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// casesPlaceholder marca, no esqueleto, onde os casos de teste são inseridos
+const casesPlaceholder = "// TODO: adicione os casos de teste"
+
+// Field é um parâmetro, resultado ou receptor da função
+type Field struct {
+	Name string // Nome do campo na tabela de testes
+	Type string // Tipo como aparece no código-fonte
+}
+
+// Function descreve a função para a qual os testes serão gerados
+type Function struct {
+	Package  string   // Nome do pacote
+	Name     string   // Nome da função ou método
+	Receiver *Field   // Receptor, para métodos
+	Params   []Field  // Parâmetros, na ordem da assinatura
+	Results  []Field  // Resultados, sem o error final
+	Variadic bool     // Último parâmetro é variádico (...T)
+	HasError bool     // Último resultado é error
+	Source   string   // Código-fonte da função, enviado ao modelo
+	Imports  []string // Imports do arquivo usados na assinatura
+}
+
+// TestName retorna o nome da função de teste (TestFunc ou TestTipo_Metodo)
+func (f *Function) TestName() string {
+	if f.Receiver == nil {
+		return "Test" + exportName(f.Name)
+	}
+	recv := strings.TrimPrefix(f.Receiver.Type, "*")
+	if i := strings.Index(recv, "["); i >= 0 {
+		recv = recv[:i]
+	}
+	return "Test" + exportName(recv) + "_" + f.Name
+}
+
+// TestPath retorna o caminho do arquivo de teste correspondente (x.go -> x_test.go)
+func TestPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "_test.go"
+}
+
+// FindFunction procura a função no arquivo. Métodos podem ser indicados como
+// "Tipo.Metodo" ou apenas "Metodo" quando não há ambiguidade.
+func FindFunction(src, filename, name string) (*Function, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	recvName, funcName := "", name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		recvName, funcName = name[:i], name[i+1:]
+	}
+
+	var found []*ast.FuncDecl
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Name.Name != funcName {
+			continue
+		}
+		if recvName != "" && (fd.Recv == nil || receiverType(fd.Recv.List[0].Type) != recvName) {
+			continue
+		}
+		found = append(found, fd)
+	}
+
+	// Sem o tipo, uma função tem prioridade sobre métodos de mesmo nome
+	if recvName == "" && len(found) > 1 {
+		var funcs []*ast.FuncDecl
+		for _, fd := range found {
+			if fd.Recv == nil {
+				funcs = append(funcs, fd)
+			}
+		}
+		if len(funcs) == 1 {
+			found = funcs
+		}
+	}
+
+	switch {
+	case len(found) == 0:
+		return nil, fmt.Errorf("função %s não encontrada em %s", name, filename)
+	case len(found) > 1:
+		return nil, fmt.Errorf("%s é ambíguo; use Tipo.%s", name, funcName)
+	}
+
+	fd := found[0]
+	if fd.Type.TypeParams != nil && len(fd.Type.TypeParams.List) > 0 {
+		return nil, fmt.Errorf("funções genéricas não são suportadas: %s", name)
+	}
+
+	fn := &Function{
+		Package: file.Name.Name,
+		Name:    fd.Name.Name,
+		Source:  src[fset.Position(fd.Pos()).Offset:fset.Position(fd.End()).Offset],
+	}
+
+	typeOf := func(expr ast.Expr) string {
+		return src[fset.Position(expr.Pos()).Offset:fset.Position(expr.End()).Offset]
+	}
+	used := map[string]bool{"name": true, "tt": true, "t": true, "tests": true, "err": true, "wantErr": true, "result": true, "receiver": true}
+
+	if fd.Recv != nil && len(fd.Recv.List) > 0 {
+		fn.Receiver = &Field{Name: "receiver", Type: typeOf(fd.Recv.List[0].Type)}
+	}
+
+	for i, p := range expandFields(fd.Type.Params) {
+		field := Field{Name: uniqueName(p.name, fmt.Sprintf("arg%d", i), used), Type: typeOf(p.expr)}
+		if ellipsis, ok := p.expr.(*ast.Ellipsis); ok {
+			field.Type = "[]" + typeOf(ellipsis.Elt)
+			fn.Variadic = true
+		}
+		fn.Params = append(fn.Params, field)
+	}
+
+	results := expandFields(fd.Type.Results)
+	if n := len(results); n > 0 {
+		if ident, ok := results[n-1].expr.(*ast.Ident); ok && ident.Name == "error" {
+			fn.HasError = true
+			results = results[:n-1]
+		}
+	}
+	for i, r := range results {
+		fallback := "expected"
+		if len(results) > 1 {
+			fallback = fmt.Sprintf("expected%d", i+1)
+		}
+		fn.Results = append(fn.Results, Field{Name: uniqueName("", fallback, used), Type: typeOf(r.expr)})
+	}
+
+	fn.Imports = usedImports(file, fn)
+	return fn, nil
+}
+
+// Skeleton gera o arquivo de teste table-driven com a tabela de casos vazia
+func Skeleton(fn *Function) (string, error) {
+	var b strings.Builder
+
+	b.WriteString("package " + fn.Package + "\n\n")
+	b.WriteString("import (\n")
+	if needsDeepEqual(fn) {
+		b.WriteString("\t\"reflect\"\n")
+	}
+	b.WriteString("\t\"testing\"\n")
+	for _, imp := range fn.Imports {
+		b.WriteString("\t" + imp + "\n")
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n", fn.TestName())
+	b.WriteString("\ttests := []struct {\n")
+	b.WriteString("\t\tname string\n")
+	if fn.Receiver != nil {
+		fmt.Fprintf(&b, "\t\t%s %s\n", fn.Receiver.Name, fn.Receiver.Type)
+	}
+	for _, p := range fn.Params {
+		fmt.Fprintf(&b, "\t\t%s %s\n", p.Name, p.Type)
+	}
+	for _, r := range fn.Results {
+		fmt.Fprintf(&b, "\t\t%s %s\n", r.Name, r.Type)
+	}
+	if fn.HasError {
+		b.WriteString("\t\twantErr bool\n")
+	}
+	b.WriteString("\t}{\n")
+	b.WriteString("\t\t" + casesPlaceholder + "\n")
+	b.WriteString("\t}\n\n")
+
+	b.WriteString("\tfor _, tt := range tests {\n")
+	b.WriteString("\t\tt.Run(tt.name, func(t *testing.T) {\n")
+	writeCall(&b, fn)
+	writeChecks(&b, fn)
+	b.WriteString("\t\t})\n")
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+
+	return Validate(b.String())
+}
+
+// Complete insere no esqueleto os casos de teste retornados pelo modelo.
+// O resultado é validado com go/parser e formatado com go/printer.
+func Complete(skeleton, answer string) (string, error) {
+	cases := cleanAnswer(answer)
+	if cases == "" {
+		return "", fmt.Errorf("o modelo não retornou casos de teste")
+	}
+	if !strings.Contains(skeleton, casesPlaceholder) {
+		return "", fmt.Errorf("esqueleto sem marcador de casos de teste")
+	}
+
+	indented := "\t\t" + strings.ReplaceAll(cases, "\n", "\n\t\t")
+	result, err := Validate(strings.Replace(skeleton, casesPlaceholder, indented, 1))
+	if err != nil {
+		return "", fmt.Errorf("os casos gerados pelo modelo não são código Go válido: %w", err)
+	}
+	return result, nil
+}
+
+// Validate verifica se o código é um arquivo Go válido e o retorna formatado
+func Validate(src string) (string, error) {
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "gerado_test.go", src, parser.ParseComments); err != nil {
+		return "", err
+	}
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+// Merge acrescenta a função de teste de generated ao arquivo de teste existing,
+// incluindo os imports que faltarem. Os testes já existentes são preservados; se
+// existing já declarar uma função com o mesmo nome, Merge retorna um erro.
+func Merge(existing, generated string) (string, error) {
+	fset := token.NewFileSet()
+	dst, err := parser.ParseFile(fset, "existente_test.go", existing, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("o arquivo de teste existente não é código Go válido: %w", err)
+	}
+	src, err := parser.ParseFile(fset, "gerado_test.go", generated, 0)
+	if err != nil {
+		return "", err
+	}
+	if dst.Name.Name != src.Name.Name {
+		return "", fmt.Errorf("o arquivo de teste existente é do pacote %s, e o teste gerado é do pacote %s", dst.Name.Name, src.Name.Name)
+	}
+
+	declared := map[string]bool{}
+	for _, decl := range dst.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv == nil {
+			declared[fd.Name.Name] = true
+		}
+	}
+	var funcs []string
+	for _, decl := range src.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if declared[fd.Name.Name] {
+			return "", fmt.Errorf("o arquivo de teste já contém %s", fd.Name.Name)
+		}
+		funcs = append(funcs, generated[fset.Position(fd.Pos()).Offset:fset.Position(fd.End()).Offset])
+	}
+
+	// Imports do teste gerado que ainda não estão no arquivo existente
+	present := map[string]bool{}
+	for _, imp := range dst.Imports {
+		present[importSpec(imp)] = true
+	}
+	var missing []string
+	for _, imp := range src.Imports {
+		if spec := importSpec(imp); !present[spec] {
+			missing = append(missing, spec)
+		}
+	}
+
+	var b strings.Builder
+	rest := existing
+	if len(missing) > 0 {
+		// Os imports entram no último bloco de imports ou, sem um bloco, logo após o package
+		at := fset.Position(dst.Name.End()).Offset
+		block := "\n\nimport (\n\t" + strings.Join(missing, "\n\t") + "\n)"
+		for _, decl := range dst.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.IMPORT {
+				continue
+			}
+			at = fset.Position(gd.End()).Offset
+			if gd.Rparen.IsValid() {
+				at = fset.Position(gd.Rparen).Offset
+				block = "\t" + strings.Join(missing, "\n\t") + "\n"
+			} else {
+				block = "\n\nimport (\n\t" + strings.Join(missing, "\n\t") + "\n)"
+			}
+		}
+		b.WriteString(existing[:at] + block)
+		rest = existing[at:]
+	}
+	b.WriteString(strings.TrimRight(rest, "\n"))
+	for _, f := range funcs {
+		b.WriteString("\n\n" + f)
+	}
+	b.WriteString("\n")
+
+	return Validate(b.String())
+}
+
+// BuildPrompt monta o prompt que pede ao modelo apenas as entradas da tabela
+func BuildPrompt(fn *Function, skeleton string) string {
+	var b strings.Builder
+
+	b.WriteString("Escreva casos de teste para a seguinte função Go.\n\n")
+	b.WriteString("Função:\n```go\n" + fn.Source + "\n```\n\n")
+	b.WriteString("Esqueleto do teste table-driven:\n```go\n" + skeleton + "```\n\n")
+	fmt.Fprintf(&b, "Substitua a linha %q por entradas da tabela `tests`.\n", casesPlaceholder)
+	b.WriteString("Cubra o caso comum, valores limite e casos de erro quando fizer sentido.\n")
+	b.WriteString("Responda APENAS com as entradas, uma por caso, no formato:\n")
+	b.WriteString("{\n\tname: \"descrição do caso\",\n\t...\n},\n")
+	b.WriteString("Use somente os campos declarados na struct, sem explicações e sem repetir o restante do arquivo.")
+
+	return b.String()
+}
+
+// writeCall escreve a chamada da função testada
+func writeCall(b *strings.Builder, fn *Function) {
+	var args []string
+	for i, p := range fn.Params {
+		arg := "tt." + p.Name
+		if fn.Variadic && i == len(fn.Params)-1 {
+			arg += "..."
+		}
+		args = append(args, arg)
+	}
+
+	callee := fn.Name
+	if fn.Receiver != nil {
+		callee = "tt." + fn.Receiver.Name + "." + fn.Name
+	}
+	call := callee + "(" + strings.Join(args, ", ") + ")"
+
+	var lhs []string
+	for i := range fn.Results {
+		lhs = append(lhs, resultVar(fn, i))
+	}
+	if fn.HasError {
+		lhs = append(lhs, "err")
+	}
+
+	if len(lhs) == 0 {
+		b.WriteString("\t\t\t" + call + "\n")
+		return
+	}
+	b.WriteString("\t\t\t" + strings.Join(lhs, ", ") + " := " + call + "\n")
+}
+
+// writeChecks escreve as verificações dos resultados
+func writeChecks(b *strings.Builder, fn *Function) {
+	if fn.HasError {
+		b.WriteString("\t\t\tif (err != nil) != tt.wantErr {\n")
+		fmt.Fprintf(b, "\t\t\t\tt.Fatalf(\"%s() error = %%v, wantErr %%v\", err, tt.wantErr)\n", fn.Name)
+		b.WriteString("\t\t\t}\n")
+	}
+
+	for i, r := range fn.Results {
+		got := resultVar(fn, i)
+		cond := fmt.Sprintf("%s != tt.%s", got, r.Name)
+		if !isComparable(r.Type) {
+			cond = fmt.Sprintf("!reflect.DeepEqual(%s, tt.%s)", got, r.Name)
+		}
+		fmt.Fprintf(b, "\t\t\tif %s {\n", cond)
+		fmt.Fprintf(b, "\t\t\t\tt.Errorf(\"%s() = %%v, want %%v\", %s, tt.%s)\n", fn.Name, got, r.Name)
+		b.WriteString("\t\t\t}\n")
+	}
+}
+
+// resultVar retorna o nome da variável local do i-ésimo resultado
+func resultVar(fn *Function, i int) string {
+	if len(fn.Results) == 1 {
+		return "result"
+	}
+	return "result" + strconv.Itoa(i+1)
+}
+
+// comparableTypes são os tipos predeclarados que sempre podem ser comparados com !=
+var comparableTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true, "uintptr": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// isComparable informa se o tipo pode ser comparado com != no teste gerado.
+// Só a assinatura é conhecida, então tipos nomeados (que podem ser structs com
+// slices ou maps), ponteiros e compostos são comparados com reflect.DeepEqual.
+func isComparable(typ string) bool {
+	return comparableTypes[typ]
+}
+
+// needsDeepEqual informa se o teste precisa importar reflect
+func needsDeepEqual(fn *Function) bool {
+	for _, r := range fn.Results {
+		if !isComparable(r.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanAnswer remove marcadores de bloco de código e a declaração da tabela, se repetida
+func cleanAnswer(answer string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(answer), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || trimmed == casesPlaceholder {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// namedExpr é um parâmetro ou resultado com seu nome (vazio se anônimo)
+type namedExpr struct {
+	name string
+	expr ast.Expr
+}
+
+// expandFields transforma "a, b int" em duas entradas
+func expandFields(list *ast.FieldList) []namedExpr {
+	if list == nil {
+		return nil
+	}
+	var out []namedExpr
+	for _, f := range list.List {
+		if len(f.Names) == 0 {
+			out = append(out, namedExpr{expr: f.Type})
+			continue
+		}
+		for _, n := range f.Names {
+			out = append(out, namedExpr{name: n.Name, expr: f.Type})
+		}
+	}
+	return out
+}
+
+// uniqueName escolhe um nome de campo que não colida com os já usados
+func uniqueName(name, fallback string, used map[string]bool) string {
+	if name == "" || name == "_" {
+		name = fallback
+	}
+	for used[name] {
+		name += "Arg"
+	}
+	used[name] = true
+	return name
+}
+
+// receiverType extrai o nome do tipo do receptor (T, *T, T[K])
+func receiverType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(e.X)
+	case *ast.IndexExpr:
+		return receiverType(e.X)
+	case *ast.IndexListExpr:
+		return receiverType(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// usedImports retorna as declarações de import do arquivo usadas na assinatura
+func usedImports(file *ast.File, fn *Function) []string {
+	var types []string
+	if fn.Receiver != nil {
+		types = append(types, fn.Receiver.Type)
+	}
+	for _, f := range append(append([]Field(nil), fn.Params...), fn.Results...) {
+		types = append(types, f.Type)
+	}
+	signature := strings.Join(types, " ")
+
+	var imports []string
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == "_" || name == "." || !strings.Contains(signature, name+".") {
+			continue
+		}
+		imports = append(imports, importSpec(imp))
+	}
+	return imports
+}
+
+// importSpec retorna a declaração de um import como aparece no bloco (nome opcional e caminho)
+func importSpec(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name + " " + imp.Path.Value
+	}
+	return imp.Path.Value
+}
+
+// exportName deixa a primeira letra maiúscula (parse -> Parse)
+func exportName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,225 @@
+package testgen
+
+import (
+	"strings"
+	"testing"
+)
+
+const goSource = `package sample
+
+import (
+	"context"
+	str "strings"
+)
+
+func Double(n int) int {
+	return n * 2
+}
+
+func Split(ctx context.Context, s, sep string) ([]string, error) {
+	return str.Split(s, sep), nil
+}
+
+func Join(sep string, parts ...string) string {
+	return str.Join(parts, sep)
+}
+
+type Counter struct{ n int }
+
+func (c *Counter) Add(delta int) (int, bool) {
+	c.n += delta
+	return c.n, c.n > 0
+}
+
+func Reset() {}
+
+func (c Counter) Reset() {}
+
+func Map[T any](xs []T) []T { return xs }
+
+type Point struct {
+	X, Y int
+	Tags []string
+}
+
+func Origin() Point { return Point{} }
+`
+
+func TestFindFunction(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		testName string
+		params   string
+		results  string
+		hasError bool
+		variadic bool
+		imports  string
+		wantErr  bool
+	}{
+		{name: "Função simples", function: "Double", testName: "TestDouble", params: "n int", results: "expected int"},
+		{name: "Com error e imports", function: "Split", testName: "TestSplit", params: "ctx context.Context,s string,sep string", results: "expected []string", hasError: true, imports: `"context"`},
+		{name: "Variádica", function: "Join", testName: "TestJoin", params: "sep string,parts []string", results: "expected string", variadic: true},
+		{name: "Método", function: "Counter.Add", testName: "TestCounter_Add", params: "delta int", results: "expected1 int,expected2 bool"},
+		{name: "Função homônima de método", function: "Reset", testName: "TestReset"},
+		{name: "Inexistente", function: "Nada", wantErr: true},
+		{name: "Genérica", function: "Map", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, err := FindFunction(goSource, "sample.go", tt.function)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindFunction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if fn.TestName() != tt.testName {
+				t.Errorf("TestName() = %v, want %v", fn.TestName(), tt.testName)
+			}
+			if got := joinFields(fn.Params); got != tt.params {
+				t.Errorf("Params = %q, want %q", got, tt.params)
+			}
+			if got := joinFields(fn.Results); got != tt.results {
+				t.Errorf("Results = %q, want %q", got, tt.results)
+			}
+			if fn.HasError != tt.hasError || fn.Variadic != tt.variadic {
+				t.Errorf("HasError = %v, Variadic = %v", fn.HasError, fn.Variadic)
+			}
+			if got := strings.Join(fn.Imports, ","); got != tt.imports {
+				t.Errorf("Imports = %q, want %q", got, tt.imports)
+			}
+		})
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		function string
+		expected []string
+	}{
+		{
+			function: "Split",
+			expected: []string{`"reflect"`, `"context"`, "wantErr  bool", "result, err := Split(tt.ctx, tt.s, tt.sep)", "(err != nil) != tt.wantErr", "!reflect.DeepEqual(result, tt.expected)"},
+		},
+		{
+			function: "Join",
+			expected: []string{"result := Join(tt.sep, tt.parts...)", "if result != tt.expected {"},
+		},
+		{
+			function: "Counter.Add",
+			expected: []string{"receiver  *Counter", "result1, result2 := tt.receiver.Add(tt.delta)", "if result2 != tt.expected2 {"},
+		},
+		{
+			function: "Origin",
+			expected: []string{`"reflect"`, "result := Origin()", "!reflect.DeepEqual(result, tt.expected)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			fn, _ := FindFunction(goSource, "sample.go", tt.function)
+			skeleton, err := Skeleton(fn)
+			if err != nil {
+				t.Fatalf("Skeleton() error = %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(skeleton, expected) {
+					t.Errorf("Expected skeleton to contain %q, got:\n%s", expected, skeleton)
+				}
+			}
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	fn, _ := FindFunction(goSource, "sample.go", "Double")
+	skeleton, _ := Skeleton(fn)
+
+	tests := []struct {
+		name    string
+		answer  string
+		wantErr bool
+	}{
+		{name: "Entradas puras", answer: "{name: \"zero\", n: 0, expected: 0},\n{name: \"positivo\", n: 2, expected: 4},"},
+		{name: "Bloco de código", answer: "```go\n{\n\tname: \"negativo\",\n\tn: -1,\n\texpected: -2,\n},\n```"},
+		{name: "Texto explicativo", answer: "Aqui estão os casos de teste que cobrem a função.", wantErr: true},
+		{name: "Vazio", answer: "```\n```", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Complete(skeleton, tt.answer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Complete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.Contains(result, casesPlaceholder) {
+				t.Errorf("Expected placeholder to be replaced, got:\n%s", result)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	fn, _ := FindFunction(goSource, "sample.go", "Split")
+	generated, _ := Skeleton(fn)
+
+	tests := []struct {
+		name     string
+		existing string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "Bloco de imports",
+			existing: "package sample\n\nimport (\n\t\"testing\"\n)\n\nfunc TestDouble(t *testing.T) {}\n",
+			expected: []string{"func TestDouble(t *testing.T) {}", "func TestSplit(t *testing.T) {", "\t\"context\"\n\t\"reflect\"\n\t\"testing\"\n"},
+		},
+		{
+			name:     "Import simples",
+			existing: "package sample\n\nimport \"testing\"\n\nfunc TestDouble(t *testing.T) {}\n",
+			expected: []string{"import \"testing\"", "func TestDouble(t *testing.T) {}", "func TestSplit(t *testing.T) {", "\"context\""},
+		},
+		{
+			name:     "Teste já existente",
+			existing: "package sample\n\nimport \"testing\"\n\nfunc TestSplit(t *testing.T) {}\n",
+			wantErr:  true,
+		},
+		{
+			name:     "Pacote externo",
+			existing: "package sample_test\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Merge(tt.existing, generated)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Merge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Expected result to contain %q, got:\n%s", expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestTestPath(t *testing.T) {
+	if got := TestPath("pkg/util.go"); got != "pkg/util_test.go" {
+		t.Errorf("TestPath() = %v, want pkg/util_test.go", got)
+	}
+}
+
+// joinFields formata os campos como "nome tipo" separados por vírgula
+func joinFields(fields []Field) string {
+	var parts []string
+	for _, f := range fields {
+		parts = append(parts, f.Name+" "+f.Type)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,101 @@
+// Package tracing configura o OpenTelemetry: exportação dos spans para um
+// endpoint OTLP/HTTP ou para um arquivo JSON local e propagação do contexto W3C.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Name identifica o instrumentador dos spans do code-explainer
+const Name = "github.com/mvcbotelho/code-explainer"
+
+// Options configura o destino dos spans. Sem Endpoint nem File, o rastreamento fica desligado.
+type Options struct {
+	Endpoint    string // URL OTLP/HTTP (ex.: http://localhost:4318)
+	File        string // Arquivo JSON, um span por linha
+	ServiceName string
+	Version     string
+}
+
+// ShutdownFunc envia os spans pendentes e libera o exportador
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup registra o TracerProvider e o propagador globais conforme opts
+func Setup(ctx context.Context, opts Options) (ShutdownFunc, error) {
+	// O contexto de rastreamento é propagado mesmo sem exportador local
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporters []sdktrace.SpanExporter
+	var closer io.Closer
+	if opts.Endpoint != "" {
+		exp, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao configurar exportador OTLP: %w", err)
+		}
+		exporters = append(exporters, exp)
+	}
+	if opts.File != "" {
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao abrir arquivo de rastreamento: %w", err)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		exporters = append(exporters, exp)
+		closer = f
+	}
+	if len(exporters) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res := resource.NewSchemaless(
+		semconv.ServiceName(opts.ServiceName),
+		semconv.ServiceVersion(opts.Version),
+	)
+	providerOpts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	for _, exp := range exporters {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exp))
+	}
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// Start inicia um span filho do span em ctx usando o TracerProvider global
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(Name).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End registra err no span, se houver, e o encerra
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSetupFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), Options{File: path, ServiceName: "code-explainer", Version: "test"})
+	if err != nil {
+		t.Fatalf("Setup() erro = %v", err)
+	}
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	ctx, parent := Start(context.Background(), "explain")
+	_, child := Start(ctx, "build_prompt")
+	End(child, errors.New("falhou"))
+	End(parent, nil)
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() erro = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{`"Name":"explain"`, `"Name":"build_prompt"`, `"Description":"falhou"`, "code-explainer"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("arquivo de rastreamento não contém %s:\n%s", want, out)
+		}
+	}
+	if got := strings.Count(strings.TrimSpace(out), "\n") + 1; got != 2 {
+		t.Errorf("arquivo tem %d span(s), want 2", got)
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("Setup() erro = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() erro = %v", err)
+	}
+
+	// Mesmo sem exportador, o contexto recebido é propagado
+	fields := otel.GetTextMapPropagator().Fields()
+	if len(fields) == 0 || fields[0] != "traceparent" {
+		t.Errorf("propagador = %v, want traceparent", fields)
+	}
+}